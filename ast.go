@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"go/ast"
+	"go/types"
 )
 
 // Annotated ast.Expr nodes
@@ -29,6 +31,12 @@ type Expr interface {
 	String() string
 }
 
+// Annotated ast.Stmt nodes. Statements are checked in place, the
+// statements of a checked *ast.BlockStmt are themselves annotated.
+type Stmt interface {
+	ast.Stmt
+}
+
 type knownType []reflect.Type
 type constValue reflect.Value
 
@@ -55,6 +63,8 @@ type BasicLit struct {
 
 type FuncLit struct {
 	*ast.FuncLit
+	knownType
+	fn *function
 }
 
 type CompositeLit struct {
@@ -83,6 +93,20 @@ type IndexExpr struct {
 	constValue
 }
 
+// IndexListExpr is the instantiation of a generic function, f[T, U]. A
+// generic function called without type arguments is also represented
+// by an IndexListExpr, with no Indices. fn is the instantiation, or is
+// invalid if the type arguments are inferred from the values of the
+// arguments when the call is evaluated.
+type IndexListExpr struct {
+	*ast.IndexListExpr
+	knownType
+
+	generic *Generic
+	targs   []reflect.Type
+	fn      reflect.Value
+}
+
 type SliceExpr struct {
 	*ast.SliceExpr
 	knownType
@@ -162,6 +186,117 @@ type ChanType struct {
 	knownType
 }
 
+type BadStmt struct {
+	*ast.BadStmt
+}
+
+type DeclStmt struct {
+	*ast.DeclStmt
+	decl Decl
+}
+
+type EmptyStmt struct {
+	*ast.EmptyStmt
+}
+
+type LabeledStmt struct {
+	*ast.LabeledStmt
+}
+
+type ExprStmt struct {
+	*ast.ExprStmt
+}
+
+type IncDecStmt struct {
+	*ast.IncDecStmt
+}
+
+type AssignStmt struct {
+	*ast.AssignStmt
+
+	// For :=, which of the names on the left are newly declared
+	newVars []bool
+}
+
+type ReturnStmt struct {
+	*ast.ReturnStmt
+}
+
+type BranchStmt struct {
+	*ast.BranchStmt
+}
+
+type DeferStmt struct {
+	*ast.DeferStmt
+	call *CallExpr
+}
+
+type GoStmt struct {
+	*ast.GoStmt
+	call *CallExpr
+}
+
+type SendStmt struct {
+	*ast.SendStmt
+}
+
+type CommClause struct {
+	*ast.CommClause
+
+	// The channel of a send or receive case, nil for the default case
+	ch Expr
+
+	// The value sent by a send case
+	value Expr
+
+	// The variables assigned by a receive case, if any
+	lhs    []ast.Expr
+	define bool
+}
+
+type SelectStmt struct {
+	*ast.SelectStmt
+}
+
+type BlockStmt struct {
+	*ast.BlockStmt
+}
+
+type IfStmt struct {
+	*ast.IfStmt
+}
+
+type CaseClause struct {
+	*ast.CaseClause
+}
+
+type SwitchStmt struct {
+	*ast.SwitchStmt
+}
+
+// TypeSwitchStmt is a switch on the dynamic type of the interface value
+// x. name is the variable the guard declares in each clause, if any.
+type TypeSwitchStmt struct {
+	*ast.TypeSwitchStmt
+	x    Expr
+	name string
+}
+
+// TypeCaseClause is a clause of a type switch. types holds the types of
+// List, nil for the nil case.
+type TypeCaseClause struct {
+	*ast.CaseClause
+	types []reflect.Type
+}
+
+type ForStmt struct {
+	*ast.ForStmt
+}
+
+type RangeStmt struct {
+	*ast.RangeStmt
+}
+
 func (t knownType) KnownType() []reflect.Type {
 	return []reflect.Type(t)
 }
@@ -175,7 +310,6 @@ func (c constValue) Const() reflect.Value {
 }
 
 func (*BadExpr) KnownType() []reflect.Type      { return nil }
-func (*FuncLit) KnownType() []reflect.Type      { return nil }
 func (*KeyValueExpr) KnownType() []reflect.Type { return nil }
 
 func (*BadExpr) IsConst() bool        { return false }
@@ -184,6 +318,7 @@ func (*FuncLit) IsConst() bool        { return false }
 func (*CompositeLit) IsConst() bool   { return false }
 func (*SelectorExpr) IsConst() bool   { return false }
 func (*IndexExpr) IsConst() bool      { return false }
+func (*IndexListExpr) IsConst() bool  { return false }
 func (*SliceExpr) IsConst() bool      { return false }
 func (*TypeAssertExpr) IsConst() bool { return false }
 func (*StarExpr) IsConst() bool       { return false }
@@ -201,6 +336,7 @@ func (*FuncLit) Const() reflect.Value        { return reflect.Value{} }
 func (*CompositeLit) Const() reflect.Value   { return reflect.Value{} }
 func (*SelectorExpr) Const() reflect.Value   { return reflect.Value{} }
 func (*IndexExpr) Const() reflect.Value      { return reflect.Value{} }
+func (*IndexListExpr) Const() reflect.Value  { return reflect.Value{} }
 func (*SliceExpr) Const() reflect.Value      { return reflect.Value{} }
 func (*TypeAssertExpr) Const() reflect.Value { return reflect.Value{} }
 func (*StarExpr) Const() reflect.Value       { return reflect.Value{} }
@@ -229,7 +365,9 @@ func (ident *Ident) String() string {
 	return ident.Ident.String()
 }
 
-func (ellipsis *Ellipsis) String() string { return "TODO  ellipsis.Ellipsis" }
+func (ellipsis *Ellipsis) String() string {
+	return "..." + exprString(ellipsis.Elt)
+}
 
 func (basicLit *BasicLit) String() string {
 	if basicLit.IsConst() {
@@ -238,26 +376,88 @@ func (basicLit *BasicLit) String() string {
 	return basicLit.Value
 }
 
-func (funcLit *FuncLit) String() string { return "TODO  funcLit.FuncLit" }
-func (compositeLit *CompositeLit) String() string { return "TODO  compositeLit.CompositeLit" }
+// Function and composite literals are shortened, as gc prints them
+func (funcLit *FuncLit) String() string {
+	return funcTypeString(funcLit.Type) + " {…}"
+}
+
+func (compositeLit *CompositeLit) String() string {
+	if len(compositeLit.Elts) == 0 {
+		return exprString(compositeLit.Type) + "{}"
+	}
+	return exprString(compositeLit.Type) + "{…}"
+}
 
 func (parenExpr *ParenExpr) String() string {
 	if parenExpr.IsConst() {
 		return sprintConstValue(parenExpr.Const())
 	}
-	return skipSuperfluousParens(parenExpr).String()
+	if x := skipSuperfluousParens(parenExpr); x != parenExpr {
+		return x.String()
+	}
+	return "(" + exprString(parenExpr.X) + ")"
+}
+
+func (selectorExpr *SelectorExpr) String() string {
+	return exprString(selectorExpr.X) + "." + selectorExpr.Sel.Name
+}
+
+func (indexExpr *IndexExpr) String() string {
+	if indexExpr.IsConst() {
+		return sprintConstValue(indexExpr.Const())
+	}
+	return exprString(indexExpr.X) + "[" + exprString(indexExpr.Index) + "]"
 }
 
-func (selectorExpr *SelectorExpr) String() string { return "TODO  selectorExpr.SelectorExpr" }
-func (indexExpr *IndexExpr) String() string { return "TODO  indexExpr.IndexExpr" }
-func (sliceExpr *SliceExpr) String() string { return "TODO  sliceExpr.SliceExpr" }
-func (typeAssertExpr *TypeAssertExpr) String() string { return "TODO  typeAssertExpr.TypeAssertExpr" }
-func (callExpr *CallExpr) String() string { return "TODO  callExpr.CallExpr" }
-func (starExpr *StarExpr) String() string { return "TODO  starExpr.StarExpr" }
+func (indexList *IndexListExpr) String() string {
+	if indexList.fn.IsValid() && indexList.Indices == nil {
+		return indexList.generic.instanceName(indexList.targs)
+	}
+	return types.ExprString(indexList.IndexListExpr)
+}
+
+func (sliceExpr *SliceExpr) String() string {
+	s := exprString(sliceExpr.X) + "[" + exprString(sliceExpr.Low) + ":" + exprString(sliceExpr.High)
+	if sliceExpr.Slice3 {
+		s += ":" + exprString(sliceExpr.Max)
+	}
+	return s + "]"
+}
+
+func (typeAssertExpr *TypeAssertExpr) String() string {
+	if typeAssertExpr.Type == nil {
+		return exprString(typeAssertExpr.X) + ".(type)"
+	}
+	return exprString(typeAssertExpr.X) + ".(" + exprString(typeAssertExpr.Type) + ")"
+}
+
+func (callExpr *CallExpr) String() string {
+	// The parens of a conversion to a type such as (*T) are kept
+	fun := exprString(callExpr.Fun)
+	if paren, ok := callExpr.Fun.(*ParenExpr); ok && skipSuperfluousParens(paren) != Expr(paren) {
+		fun = "(" + fun + ")"
+	}
+	args := make([]string, len(callExpr.Args))
+	for i, arg := range callExpr.Args {
+		args[i] = exprString(arg)
+	}
+	if callExpr.Ellipsis.IsValid() {
+		return fun + "(" + strings.Join(args, ", ") + "...)"
+	}
+	return fun + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (starExpr *StarExpr) String() string {
+	return "*" + exprString(starExpr.X)
+}
 
 func (unary *UnaryExpr) String() string {
 	operand := skipSuperfluousParens(unary.X.(Expr))
-	return fmt.Sprintf("%v %v", unary.Op, operand)
+	if inner, ok := operand.(*UnaryExpr); ok && inner.Op == unary.Op {
+		// - -x, not --x
+		return fmt.Sprintf("%v %v", unary.Op, operand)
+	}
+	return fmt.Sprintf("%v%v", unary.Op, operand)
 }
 
 func (binary *BinaryExpr) String() string {
@@ -268,13 +468,80 @@ func (binary *BinaryExpr) String() string {
 	return fmt.Sprintf("%v %v %v", left, binary.Op, right)
 }
 
-func (keyValueExpr *KeyValueExpr) String() string { return "TODO  keyValueExpr.KeyValueExpr" }
-func (arrayType *ArrayType) String() string { return "TODO  arrayType.ArrayType" }
-func (structType *StructType) String() string { return "TODO  structType.StructType" }
-func (funcType *FuncType) String() string { return "TODO  funcType.FuncType" }
-func (interfaceType *InterfaceType) String() string { return "TODO  interfaceType.InterfaceType" }
-func (mapType *MapType) String() string { return "TODO  mapType.MapType" }
-func (chanType *ChanType) String() string { return "TODO  chanType.ChanType" }
+func (keyValueExpr *KeyValueExpr) String() string {
+	return exprString(keyValueExpr.Key) + ": " + exprString(keyValueExpr.Value)
+}
+
+func (arrayType *ArrayType) String() string {
+	return "[" + exprString(arrayType.Len) + "]" + exprString(arrayType.Elt)
+}
+
+func (structType *StructType) String() string {
+	return "struct{" + fieldListString(structType.Fields, "; ") + "}"
+}
+
+func (funcType *FuncType) String() string {
+	return funcTypeString(funcType.FuncType)
+}
+
+func (interfaceType *InterfaceType) String() string {
+	return types.ExprString(interfaceType.InterfaceType)
+}
+
+func (mapType *MapType) String() string {
+	return "map[" + exprString(mapType.Key) + "]" + exprString(mapType.Value)
+}
+
+func (chanType *ChanType) String() string {
+	switch chanType.Dir {
+	case ast.SEND:
+		return "chan<- " + exprString(chanType.Value)
+	case ast.RECV:
+		return "<-chan " + exprString(chanType.Value)
+	}
+	return "chan " + exprString(chanType.Value)
+}
+
+// exprString prints a child of a node, which may or may not be annotated
+// depending on whether it was checked
+func exprString(expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	} else if e, ok := expr.(Expr); ok {
+		return e.String()
+	}
+	return types.ExprString(expr)
+}
+
+func funcTypeString(ftype *ast.FuncType) string {
+	s := "func(" + fieldListString(ftype.Params, ", ") + ")"
+	if results := ftype.Results; results != nil && len(results.List) > 0 {
+		if len(results.List) == 1 && results.List[0].Names == nil {
+			return s + " " + exprString(results.List[0].Type)
+		}
+		return s + " (" + fieldListString(results, ", ") + ")"
+	}
+	return s
+}
+
+func fieldListString(list *ast.FieldList, sep string) string {
+	if list == nil {
+		return ""
+	}
+	fields := make([]string, len(list.List))
+	for i, field := range list.List {
+		names := make([]string, len(field.Names))
+		for j, name := range field.Names {
+			names[j] = name.Name
+		}
+		if len(names) == 0 {
+			fields[i] = exprString(field.Type)
+		} else {
+			fields[i] = strings.Join(names, ", ") + " " + exprString(field.Type)
+		}
+	}
+	return strings.Join(fields, sep)
+}
 
 // Returns a printable interface{} which replaces constant expressions with their constants
 func simplifyBinaryChildExpr(parent *BinaryExpr, expr Expr) interface{} {
@@ -296,8 +563,7 @@ func simplifyBinaryChildExpr(parent *BinaryExpr, expr Expr) interface{} {
 func skipSuperfluousParens(expr Expr) Expr {
 	if p, ok := expr.(*ParenExpr); ok {
 		// Remove useless parens from (((x))) expressions
-		var tmp *ParenExpr
-		for ; ok; tmp, ok = p.X.(*ParenExpr) {
+		for tmp, ok := p.X.(*ParenExpr); ok; tmp, ok = p.X.(*ParenExpr) {
 			p = tmp
 		}
 
@@ -74,8 +74,13 @@ func (z *BigComplex) Int(bits int) (_ int64, truncation, overflow bool) {
 	integer, truncation = z.Integer()
 	res := new(big.Int).Set(integer.Re.Num())
 
-	// Numerator must fit in bits - 1, with 1 bit left for sign
-	if overflow = res.BitLen() > bits - 1; overflow {
+	// Numerator must fit in bits - 1, with 1 bit left for sign. A
+	// negative numerator n fits if ^n = -n - 1 does
+	mag := res
+	if res.Sign() < 0 {
+		mag = new(big.Int).Not(res)
+	}
+	if overflow = mag.BitLen() > bits - 1; overflow {
 		var mask uint64 = ^uint64(0) >> uint(64 - bits)
 		res.And(res, new(big.Int).SetUint64(mask))
 	}
@@ -26,6 +26,15 @@ func TestIntOverflows(t *testing.T) {
 	expectIntOverflow(t, 64, newBigInt("-0xfffffffffffffffffe"), 0x0000000000000002)
 }
 
+func TestIntBounds(t *testing.T) {
+	expectIntBound(t, 8, newBigInt("-0x80"), -0x80)
+	expectIntBound(t, 16, newBigInt("-0x8000"), -0x8000)
+	expectIntBound(t, 32, newBigInt("-0x80000000"), -0x80000000)
+	expectIntBound(t, 64, newBigInt("-0x8000000000000000"), -0x8000000000000000)
+
+	expectIntOverflow(t, 8, newBigInt("-0x81"), 0x7f)
+}
+
 func TestUintOverflows(t *testing.T) {
 	expectUintOverflow(t, 8, newBigInt("0x000001fe"), 0xfe)
 	expectUintOverflow(t, 8, newBigInt("0xfffffffe"), 0xfe)
@@ -62,6 +71,16 @@ func expectIntOverflow(t *testing.T, bits int, c *BigComplex, expected int64) {
 	}
 }
 
+func expectIntBound(t *testing.T, bits int, c *BigComplex, expected int64) {
+	if result, truncation, overflow := c.Int(bits); truncation {
+		t.Fatalf("Unexpected truncation")
+	} else if overflow {
+		t.Fatalf("Unexpected overflow")
+	} else if result != expected {
+		t.Fatalf("Expected %v, got %v\n", expected, result)
+	}
+}
+
 func expectUintOverflow(t *testing.T, bits int, c *BigComplex, expected uint64) {
 	if result, truncation, overflow := c.Uint(bits); truncation {
 		t.Fatalf("Unexpected truncation")
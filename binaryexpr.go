@@ -1,10 +1,13 @@
 package eval
 
 import (
+	"errors"
 	"reflect"
 	"go/token"
 )
 
+var errDivideByZero = errors.New("runtime error: integer divide by zero")
+
 func evalBinaryExpr(ctx *Ctx, b *BinaryExpr, env *Env) (r reflect.Value, rtyped bool, err error) {
 	var xx, yy *[]reflect.Value
 	var xtyped, ytyped bool
@@ -12,57 +15,137 @@ func evalBinaryExpr(ctx *Ctx, b *BinaryExpr, env *Env) (r reflect.Value, rtyped
 		return reflect.Value{}, false, err
 	}
 
+	// && and || only evaluate their right operand if needed
+	if xx == nil {
+		// x is nil
+	} else if x := (*xx)[0]; x.Kind() == reflect.Bool {
+		if b.Op == token.LAND && !x.Bool() || b.Op == token.LOR && x.Bool() {
+			return x, xtyped, nil
+		}
+	}
+
 	if yy, ytyped, err = EvalExpr(ctx, b.Y.(Expr), env); err != nil {
 		return reflect.Value{}, false, err
 	}
+	if xx == nil {
+		return evalNilComparison(ctx, b, (*yy)[0])
+	} else if yy == nil {
+		return evalNilComparison(ctx, b, (*xx)[0])
+	}
+	return evalBinaryValues(ctx, (*xx)[0], xtyped, b.Op, (*yy)[0], ytyped)
+}
+
+// evalNilComparison evaluates x == nil or x != nil, for either order of
+// the operands
+func evalNilComparison(ctx *Ctx, b *BinaryExpr, x reflect.Value) (reflect.Value, bool, error) {
+	switch x.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		switch b.Op {
+		case token.EQL:
+			return reflect.ValueOf(x.IsNil()), false, nil
+		case token.NEQ:
+			return reflect.ValueOf(!x.IsNil()), false, nil
+		}
+	}
+	return reflect.Value{}, false, ErrInvalidNilOperands{at(ctx, b), x.Type(), b.Op}
+}
+
+// evalBinaryValues evaluates x op y for evaluated operands. It is shared
+// by binary expressions and the op= and ++/-- statements.
+func evalBinaryValues(ctx *Ctx, x reflect.Value, xtyped bool, op token.Token, y reflect.Value, ytyped bool) (r reflect.Value, rtyped bool, err error) {
 	rtyped = xtyped || ytyped
-	x, y := (*xx)[0], (*yy)[0]
 
 	if userConversion != nil {
 		x, xtyped, err = userConversion(x, xtyped)
 		y, ytyped, err = userConversion(y, xtyped)
 	}
 
+	// The type of a shift is that of x alone
+	if op == token.SHL || op == token.SHR {
+		return evalShiftValues(ctx, x, xtyped, op, y)
+	}
+
 	// Rearrange x and y such that y is assignable to x, if possible
 	if xtyped && ytyped {
 		if x.Type().AssignableTo(y.Type()) {
 			x = x.Convert(y.Type())
 		} else if !y.Type().AssignableTo(x.Type()) {
-			return r, rtyped, ErrMismatchedTypes{x, b.Op, y}
+			return r, rtyped, ErrMismatchedTypes{x, op, y}
 		}
 	} else if xtyped {
-		if !y.Type().ConvertibleTo(x.Type()) {
-			return r, rtyped, ErrInvalidOperands{x, b.Op, y}
+		// Untyped numbers are promoted to complex types, which reflect
+		// cannot convert them to
+		if y.Type().ConvertibleTo(x.Type()) {
+			y = y.Convert(x.Type())
+		} else if promoted, err := promoteUntypedNumeral(y, x.Type()); err == nil {
+			y = promoted
+		} else {
+			return r, rtyped, ErrInvalidOperands{x, op, y}
 		}
-		y = y.Convert(x.Type())
 	} else if ytyped {
-		if !x.Type().ConvertibleTo(y.Type()) {
-			return r, rtyped, ErrInvalidOperands{x, b.Op, y}
+		if x.Type().ConvertibleTo(y.Type()) {
+			x = x.Convert(y.Type())
+		} else if promoted, err := promoteUntypedNumeral(x, y.Type()); err == nil {
+			x = promoted
+		} else {
+			return r, rtyped, ErrInvalidOperands{x, op, y}
 		}
-		x = x.Convert(y.Type())
 	} else if isUntypedNumeral(x) && isUntypedNumeral(y) {
 		x, y = promoteUntypedNumerals(x, y)
 	} else {
-		return r, rtyped, ErrInvalidOperands{x, b.Op, y}
+		return r, rtyped, ErrInvalidOperands{x, op, y}
 	}
 
 	switch x.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		r, err = evalBinaryIntExpr(ctx, x, b.Op, y)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		r, err = evalBinaryUintExpr(ctx, x, b.Op, y)
+		r, err = evalBinaryIntExpr(ctx, x, op, y)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		r, err = evalBinaryUintExpr(ctx, x, op, y)
 	case reflect.Float32, reflect.Float64:
-		r, err = evalBinaryFloatExpr(ctx, x, b.Op, y)
+		r, err = evalBinaryFloatExpr(ctx, x, op, y)
 	case reflect.Complex64, reflect.Complex128:
-		r, err = evalBinaryComplexExpr(ctx, x, b.Op, y)
+		r, err = evalBinaryComplexExpr(ctx, x, op, y)
 	case reflect.String:
-		r, err = evalBinaryStringExpr(ctx, x, b.Op, y)
+		r, err = evalBinaryStringExpr(ctx, x, op, y)
+	case reflect.Bool:
+		r, err = evalBinaryBoolExpr(ctx, x, op, y)
+	case reflect.Interface, reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		r, err = evalBinaryEqualityExpr(ctx, x, op, y)
 	default:
-		err = ErrInvalidOperands{x, b.Op, y}
+		err = ErrInvalidOperands{x, op, y}
 	}
 	return
 }
 
+func evalShiftValues(ctx *Ctx, x reflect.Value, xtyped bool, op token.Token, y reflect.Value) (reflect.Value, bool, error) {
+	var count uint64
+	switch y.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if y.Int() < 0 {
+			return reflect.Value{}, false, errors.New("runtime error: negative shift amount")
+		}
+		count = uint64(y.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		count = y.Uint()
+	default:
+		return reflect.Value{}, false, ErrInvalidOperands{x, op, y}
+	}
+	switch x.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if op == token.SHL {
+			return reflect.ValueOf(x.Int() << count).Convert(x.Type()), xtyped, nil
+		}
+		return reflect.ValueOf(x.Int() >> count).Convert(x.Type()), xtyped, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if op == token.SHL {
+			return reflect.ValueOf(x.Uint() << count).Convert(x.Type()), xtyped, nil
+		}
+		return reflect.ValueOf(x.Uint() >> count).Convert(x.Type()), xtyped, nil
+	default:
+		return reflect.Value{}, false, ErrInvalidOperands{x, op, y}
+	}
+}
+
 // Assumes y is assignable to x, panics otherwise
 func evalBinaryIntExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.Value) (reflect.Value, error) {
 	var r int64
@@ -75,8 +158,14 @@ func evalBinaryIntExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.Valu
 	case token.ADD: r = xx + yy
 	case token.SUB: r = xx - yy
 	case token.MUL: r = xx * yy
-	case token.QUO: r = xx / yy
-	case token.REM: r = xx % yy
+	case token.QUO, token.REM:
+		if yy == 0 {
+			return reflect.Value{}, errDivideByZero
+		} else if op == token.QUO {
+			r = xx / yy
+		} else {
+			r = xx % yy
+		}
 	case token.AND: r = xx & yy
 	case token.OR:  r = xx | yy
 	case token.XOR: r = xx ^ yy
@@ -108,8 +197,14 @@ func evalBinaryUintExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.Val
 	case token.ADD: r = xx + yy
 	case token.SUB: r = xx - yy
 	case token.MUL: r = xx * yy
-	case token.QUO: r = xx / yy
-	case token.REM: r = xx % yy
+	case token.QUO, token.REM:
+		if yy == 0 {
+			return reflect.Value{}, errDivideByZero
+		} else if op == token.QUO {
+			r = xx / yy
+		} else {
+			r = xx % yy
+		}
 	case token.AND: r = xx & yy
 	case token.OR:  r = xx | yy
 	case token.XOR: r = xx ^ yy
@@ -140,9 +235,12 @@ func evalBinaryFloatExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.Va
 	case token.SUB: r = xx - yy
 	case token.MUL: r = xx * yy
 	case token.QUO: r = xx / yy
-	// case token.EQL: b = xx == yy
-	// case token.LSS: b = xx < yy
-	// case token.GTR: b = xx > yy
+	case token.EQL: return reflect.ValueOf(xx == yy), nil
+	case token.NEQ: return reflect.ValueOf(xx != yy), nil
+	case token.LEQ: return reflect.ValueOf(xx <= yy), nil
+	case token.GEQ: return reflect.ValueOf(xx >= yy), nil
+	case token.LSS: return reflect.ValueOf(xx < yy), nil
+	case token.GTR: return reflect.ValueOf(xx > yy), nil
 	default: err = ErrInvalidOperands{x, op, y}
 	}
 	return reflect.ValueOf(r).Convert(x.Type()), err
@@ -159,6 +257,8 @@ func evalBinaryComplexExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.
 	case token.SUB: r = xx - yy
 	case token.MUL: r = xx * yy
 	case token.QUO: r = xx / yy
+	case token.EQL: return reflect.ValueOf(xx == yy), nil
+	case token.NEQ: return reflect.ValueOf(xx != yy), nil
 	default: err = ErrInvalidOperands{x, op, y}
 	}
 	return reflect.ValueOf(r).Convert(x.Type()), err
@@ -173,7 +273,11 @@ func evalBinaryStringExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.V
 
 	xx, yy := x.String(), y.String()
 	switch op {
-	case token.ADD:	r = xx + yy
+	case token.ADD:
+		n := len(xx) + len(yy)
+		if err = ctx.Limits.allocate(n, int64(n)); err == nil {
+			r = xx + yy
+		}
 	case token.EQL: b = xx == yy; is_bool = true
 	case token.NEQ: b = xx != yy; is_bool = true
 	case token.LEQ: b = xx <= yy; is_bool = true
@@ -188,3 +292,35 @@ func evalBinaryStringExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.V
 		return reflect.ValueOf(r).Convert(x.Type()), err
 	}
 }
+
+// Assumes y is assignable to x, panics otherwise
+func evalBinaryBoolExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.Value) (reflect.Value, error) {
+	var r bool
+	var err error
+
+	xx, yy := x.Bool(), y.Bool()
+	switch op {
+	case token.LAND: r = xx && yy
+	case token.LOR: r = xx || yy
+	case token.EQL: r = xx == yy
+	case token.NEQ: r = xx != yy
+	default: err = ErrInvalidOperands{x, op, y}
+	}
+	return reflect.ValueOf(r).Convert(x.Type()), err
+}
+
+// evalBinaryEqualityExpr compares interfaces, pointers and channels. Like
+// Go it panics if two interfaces hold the same uncomparable type.
+func evalBinaryEqualityExpr(ctx *Ctx, x reflect.Value, op token.Token, y reflect.Value) (r reflect.Value, err error) {
+	if op != token.EQL && op != token.NEQ {
+		return r, ErrInvalidOperands{x, op, y}
+	}
+	defer func() {
+		if recover() != nil {
+			r, err = reflect.Value{}, ErrPanic{errors.New(
+				"runtime error: comparing uncomparable type " + x.Elem().Type().String())}
+		}
+	}()
+	equal := x.Interface() == y.Interface()
+	return reflect.ValueOf(equal == (op == token.EQL)), nil
+}
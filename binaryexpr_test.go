@@ -57,6 +57,18 @@ func TestIntBinaryOps(t *testing.T) {
 // 	expectResult(t, "uint64(2)!=3", env, bool(uint64(2)!=3))
 // }
 
+func TestUintptrOps(t *testing.T) {
+	env := makeEnv()
+	env.Vars["p"] = reflect.ValueOf(new(uintptr))
+	env.Vars["a"] = reflect.ValueOf(&[]int{1, 2, 3})
+	*env.Vars["p"].Interface().(*uintptr) = 2
+
+	expectResult(t, "uintptr(9)", env, uintptr(9))
+	expectResult(t, "p + 1", env, uintptr(3))
+	expectResult(t, "^p", env, ^uintptr(2))
+	expectResult(t, "a[p]", env, 3)
+}
+
 func TestFloatBinaryOps(t *testing.T) {
 	env := makeEnv()
 
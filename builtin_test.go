@@ -70,6 +70,8 @@ func TestBuiltinLen(t *testing.T) {
 
 	expectResult(t, "len(\"abc\")", env, len("abc"))
 	expectResult(t, "len(slice)", env, len(slice))
+	expectError(t, "len()", env, "not enough arguments in call to len")
+	expectError(t, "len(\"a\", \"b\")", env, "too many arguments in call to len")
 	// FIXME: add tests for map, array and channel
 }
 
@@ -82,4 +84,6 @@ func TestBuiltinNew(t *testing.T) {
 		t.Fatalf("Error Expecting `%s' return Kind to be `ptr' is `%s`", expr, returnKind)
 	}
 	expectError(t, "new(5)", env, "new parameter is not a type")
+	expectError(t, "new()", env, "missing argument to new")
+	expectError(t, "new(int, int)", env, "too many arguments to new(int)")
 }
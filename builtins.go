@@ -3,6 +3,7 @@ package eval
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 )
 
@@ -14,15 +15,16 @@ var (
 	c128 reflect.Type = reflect.TypeOf(complex128(0))
 )
 
-// For each parameter in a builtin function, a bool parameter is passed
-// to indicate if the corresponding value is typed. The boolean(s) appear
+// Builtin functions are passed the Ctx of the call, followed by the
+// parameters. For each parameter, a bool parameter is passed to
+// indicate if the corresponding value is typed. The boolean(s) appear
 // after entire builtin parameter list.
 //
 // Builtin functions must return the builtin function reflect.Value, a
 // bool indicating if the return value is typed, and an error if there was one.
 // The returned Value must be valid
 var builtinFuncs = map[string] reflect.Value {
-	"complex": reflect.ValueOf(func(r, i reflect.Value, rt, it bool) (reflect.Value, bool, error) {
+	"complex": reflect.ValueOf(func(ctx *Ctx, r, i reflect.Value, rt, it bool) (reflect.Value, bool, error) {
 		rr, rerr := assignableValue(r, f64, rt)
 		ii, ierr := assignableValue(i, f64, it)
 		if rerr == nil && ierr == nil {
@@ -36,7 +38,7 @@ var builtinFuncs = map[string] reflect.Value {
 		}
 		return reflect.Zero(c128), false, ErrBadComplexArguments{r, i}
 	}),
-	"real": reflect.ValueOf(func(z reflect.Value, zt bool) (reflect.Value, bool, error) {
+	"real": reflect.ValueOf(func(ctx *Ctx, z reflect.Value, zt bool) (reflect.Value, bool, error) {
 		if zz, err := assignableValue(z, c128, zt); err == nil {
 			return reflect.ValueOf(real(zz.Complex())), zt, nil
 		} else if zz, err := assignableValue(z, c64, zt); err == nil {
@@ -45,7 +47,7 @@ var builtinFuncs = map[string] reflect.Value {
 			return reflect.Zero(f64), false, ErrBadBuiltinArgument{"real", z}
 		}
 	}),
-	"imag": reflect.ValueOf(func(z reflect.Value, zt bool) (reflect.Value, bool, error) {
+	"imag": reflect.ValueOf(func(ctx *Ctx, z reflect.Value, zt bool) (reflect.Value, bool, error) {
 		if zz, err := assignableValue(z, c128, zt); err == nil {
 			return reflect.ValueOf(imag(zz.Complex())), zt, nil
 		} else if zz, err := assignableValue(z, c64, zt); err == nil {
@@ -56,8 +58,8 @@ var builtinFuncs = map[string] reflect.Value {
 	}),
 	"append": reflect.ValueOf(builtinAppend),
 	"cap"   : reflect.ValueOf(builtinCap),
+	"close" : reflect.ValueOf(builtinClose),
 	"len"   : reflect.ValueOf(builtinLen),
-	"new"   : reflect.ValueOf(builtinNew),
 	"panic" : reflect.ValueOf(builtinPanic),
 }
 
@@ -73,6 +75,7 @@ var builtinTypes = map[string] reflect.Type{
 	"uint16": reflect.TypeOf(uint16(0)),
 	"uint32": reflect.TypeOf(uint32(0)),
 	"uint64": reflect.TypeOf(uint64(0)),
+	"uintptr": reflect.TypeOf(uintptr(0)),
 
 	"float32": reflect.TypeOf(float32(0)),
 	"float64": reflect.TypeOf(float64(0)),
@@ -85,12 +88,12 @@ var builtinTypes = map[string] reflect.Type{
 	"rune": RuneType,
 	"string": reflect.TypeOf(""),
 
-	"error": reflect.TypeOf(errors.New("")),
+	"error": reflect.TypeOf((*error)(nil)).Elem(),
 }
 
 // FIXME: the real append is variadic. We can only handle one arg.
 
-func builtinAppend(s, t reflect.Value, st, tt bool) (reflect.Value, bool, error) {
+func builtinAppend(ctx *Ctx, s, t reflect.Value, st, tt bool) (reflect.Value, bool, error) {
 	if s.Kind() != reflect.Slice {
 		return reflect.ValueOf(nil), true,
 		errors.New(fmt.Sprintf("first argument to append must be a slice; " +
@@ -102,10 +105,23 @@ func builtinAppend(s, t reflect.Value, st, tt bool) (reflect.Value, bool, error)
 		errors.New(fmt.Sprintf("cannot use type %v as type %v in append",
 			ttype, stype))
 	}
-	return reflect.Append(s, t), true, nil
+	if err := ctx.Limits.allocate(s.Len()+1, 0); err != nil {
+		return reflect.ValueOf(nil), false, err
+	} else if ctx.Policy.readOnly() && s.Len() < s.Cap() {
+		// The append would write to memory which s may share
+		return reflect.ValueOf(nil), false, ErrDenied{What: "append into existing capacity"}
+	}
+	r := reflect.Append(s, t)
+	if r.Cap() != s.Cap() {
+		// The slice was reallocated
+		if err := ctx.Limits.allocate(0, int64(r.Cap())*int64(stype.Size())); err != nil {
+			return reflect.ValueOf(nil), false, err
+		}
+	}
+	return r, true, nil
 }
 
-func builtinCap(v reflect.Value, vt bool) (reflect.Value, bool, error) {
+func builtinCap(ctx *Ctx, v reflect.Value, vt bool) (reflect.Value, bool, error) {
 	switch v.Kind() {
 	case reflect.Array, reflect.Chan, reflect.Slice:
 		return reflect.ValueOf(v.Cap()), true, nil
@@ -116,7 +132,27 @@ func builtinCap(v reflect.Value, vt bool) (reflect.Value, bool, error) {
 	}
 }
 
-func builtinLen(z reflect.Value, zt bool) (reflect.Value, bool, error) {
+// builtinClose closes a channel. Closing a closed channel, or a nil one,
+// panics as it would in compiled code.
+func builtinClose(ctx *Ctx, ch reflect.Value, cht bool) (v reflect.Value, t bool, err error) {
+	if ctx.Policy.readOnly() {
+		return reflect.ValueOf(nil), false, ErrDenied{What: "call of close"}
+	} else if ch.Kind() != reflect.Chan {
+		return reflect.ValueOf(nil), false, ErrBadBuiltinArgument{"close", ch}
+	} else if ch.Type().ChanDir() == reflect.RecvDir {
+		return reflect.ValueOf(nil), false,
+		errors.New(fmt.Sprintf("invalid operation: close(%v) (cannot close receive-only channel)", ch.Type()))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrPanic{r}
+		}
+	}()
+	ch.Close()
+	return reflect.ValueOf(nil), false, nil
+}
+
+func builtinLen(ctx *Ctx, z reflect.Value, zt bool) (reflect.Value, bool, error) {
 	switch z.Kind() {
 	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
 		return reflect.ValueOf(z.Len()), true, nil
@@ -125,18 +161,117 @@ func builtinLen(z reflect.Value, zt bool) (reflect.Value, bool, error) {
 	}
 }
 
-func builtinNew(rtyp reflect.Value, bt bool) (reflect.Value, bool, error) {
-	if typ, ok := rtyp.Interface().(reflect.Type); ok {
-		return reflect.New(typ), true, nil
-	} else {
-		return reflect.ValueOf(nil), false, errors.New("new parameter is not a type")
+// evalNew implements the builtin new. Like make, it is not in
+// builtinFuncs, as its argument is a type.
+func evalNew(ctx *Ctx, call *CallExpr, env *Env) (*[]reflect.Value, bool, error) {
+	if len(call.Args) == 0 {
+		return nil, false, errors.New("missing argument to new")
+	} else if len(call.Args) > 1 {
+		return nil, false, errors.New(fmt.Sprintf("too many arguments to new(%v)", call.Args[0]))
+	}
+	t, err := evalType(ctx, call.Args[0].(Expr), env)
+	if _, ok := err.(ErrLimitExceeded); ok {
+		return nil, false, err
+	} else if err != nil {
+		return nil, false, errors.New("new parameter is not a type")
+	}
+	t = unhackType(t)
+	if err := ctx.Limits.allocate(0, int64(t.Size())); err != nil {
+		return nil, false, err
+	}
+	return &[]reflect.Value{reflect.New(t)}, true, nil
+}
+
+var errMakeLen = ErrPanic{errors.New("runtime error: makeslice: len out of range")}
+
+// evalMake implements the builtin make. It is not in builtinFuncs, as
+// its first argument is a type and the number of arguments varies.
+// Limits are checked before sizes are checked against those of Go, and
+// reflect panicking on a size Go cannot allocate is returned as an error.
+func evalMake(ctx *Ctx, call *CallExpr, env *Env) (_ *[]reflect.Value, _ bool, err error) {
+	defer recoverFuncError(&err)
+
+	if len(call.Args) == 0 {
+		return nil, false, errors.New("missing argument to make")
+	}
+	t, err := evalType(ctx, call.Args[0].(Expr), env)
+	if err != nil {
+		return nil, false, err
+	}
+	t = unhackType(t)
+
+	sizes := make([]int, len(call.Args)-1)
+	for i, arg := range call.Args[1:] {
+		v, typed, err := evalSingle(ctx, arg.(Expr), env)
+		if err != nil {
+			return nil, false, err
+		}
+		v = defaultValue(v, typed)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.Int() < 0 {
+				return nil, false, errMakeLen
+			}
+			sizes[i] = int(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.Uint() > math.MaxInt64 {
+				sizes[i] = math.MaxInt64
+			} else {
+				sizes[i] = int(v.Uint())
+			}
+		default:
+			return nil, false, errors.New(fmt.Sprintf("non-integer size argument in make(%v)", t))
+		}
+	}
+
+	var r reflect.Value
+	switch t.Kind() {
+	case reflect.Slice:
+		if len(sizes) == 0 || len(sizes) > 2 {
+			return nil, false, errors.New(fmt.Sprintf(
+				"invalid operation: make(%v) expects 2 or 3 arguments; found %d", t, len(call.Args)))
+		}
+		n := sizes[len(sizes)-1]
+		if n < sizes[0] {
+			return nil, false, errors.New(fmt.Sprintf("len larger than cap in make(%v)", t))
+		} else if err := ctx.Limits.allocate(n, allocSize(n, t.Elem().Size())); err != nil {
+			return nil, false, err
+		} else if n > math.MaxInt32 {
+			return nil, false, errMakeLen
+		}
+		r = reflect.MakeSlice(t, sizes[0], n)
+	case reflect.Map, reflect.Chan:
+		if len(sizes) > 1 {
+			return nil, false, errors.New(fmt.Sprintf(
+				"invalid operation: make(%v) expects 1 or 2 arguments; found %d", t, len(call.Args)))
+		}
+		n := 0
+		if len(sizes) == 1 {
+			n = sizes[0]
+		}
+		size := t.Elem().Size()
+		if t.Kind() == reflect.Map {
+			size += t.Key().Size()
+		}
+		if err := ctx.Limits.allocate(n, allocSize(n, size)); err != nil {
+			return nil, false, err
+		} else if n > math.MaxInt32 {
+			return nil, false, errMakeLen
+		}
+		if t.Kind() == reflect.Map {
+			r = reflect.MakeMapWithSize(t, n)
+		} else {
+			r = reflect.MakeChan(t, n)
+		}
+	default:
+		return nil, false, errors.New(fmt.Sprintf(
+			"invalid argument: cannot make %v; type must be slice, map, or channel", t))
 	}
+	return &[]reflect.Value{r}, true, nil
 }
 
-func builtinPanic(z reflect.Value, zt bool) (reflect.Value, bool, error) {
-	// FIXME: we want results relative to the evaluated environment rather
-	// than a panic inside the evaluator. We might use error, but panic's
-	// parameter isn't the same as error's?
-	panic(z.Interface())
-	return reflect.ValueOf(nil), false, errors.New("Panic")
+// builtinPanic returns the panic as an error, rather than panicking
+// inside the evaluator. Deferred functions may recover it.
+func builtinPanic(ctx *Ctx, z reflect.Value, zt bool) (reflect.Value, bool, error) {
+	return reflect.ValueOf(nil), false, ErrPanic{z.Interface()}
 }
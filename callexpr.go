@@ -9,6 +9,23 @@ import (
 )
 
 func evalCallExpr(ctx *Ctx, call *CallExpr, env *Env) (*[]reflect.Value, bool, error) {
+	if isBuiltinCall(call, "recover", env) {
+		return evalRecover(env), true, nil
+	} else if isBuiltinCall(call, "make", env) {
+		return evalMake(ctx, call, env)
+	} else if isBuiltinCall(call, "new", env) {
+		return evalNew(ctx, call, env)
+	} else if fun, ok := call.Fun.(*IndexListExpr); ok && !fun.fn.IsValid() {
+		args, atyped, err := evalArgs(ctx, call, env)
+		if err != nil {
+			return nil, false, err
+		}
+		f, err := instantiateCall(ctx, fun, call, args, atyped)
+		if err != nil {
+			return nil, false, err
+		}
+		return callFunValues(ctx, env, f, true, call, args, atyped)
+	}
 	if t, err := evalType(ctx, call.Fun.(Expr), env); err == nil {
 		if v, typed, err := evalCallTypeExpr(ctx, t, call, env); err != nil {
 			return nil, false, err
@@ -16,13 +33,28 @@ func evalCallExpr(ctx *Ctx, call *CallExpr, env *Env) (*[]reflect.Value, bool, e
 			ret := []reflect.Value{v}
 			return &ret, typed, nil
 		}
-	} else if fun, _, err := EvalExpr(ctx, call.Fun.(Expr), env); err == nil {
-		return evalCallFunExpr(ctx, (*fun)[0], call, env)
-	} else {
+	} else if fun, typed, err := EvalExpr(ctx, call.Fun.(Expr), env); err != nil {
 		return nil, false, err
+	} else if fun == nil {
+		return nil, false, nil
+	} else {
+		return evalCallFunExpr(ctx, (*fun)[0], typed, call, env)
 	}
 }
 
+// isBuiltinCall reports whether call calls the builtin function name,
+// which may be shadowed in env
+func isBuiltinCall(call *CallExpr, name string, env *Env) bool {
+	ident, ok := call.Fun.(*Ident)
+	if !ok || ident.Name != name {
+		return false
+	}
+	_, isVar := env.Vars[name]
+	_, isConst := env.Consts[name]
+	_, isFunc := env.Funcs[name]
+	return !isVar && !isConst && !isFunc
+}
+
 func evalCallTypeExpr(ctx *Ctx, t reflect.Type, call *CallExpr, env *Env) (reflect.Value, bool, error) {
 	var r reflect.Value
 	if call.Args == nil {
@@ -38,55 +70,85 @@ func evalCallTypeExpr(ctx *Ctx, t reflect.Type, call *CallExpr, env *Env) (refle
 	}
 }
 
-func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]reflect.Value, bool, error) {
-	var err error
-	var v *[]reflect.Value
-	var typed bool
-	if v, typed, err = EvalExpr(ctx, call.Fun.(Expr), env); v == nil {
-		return nil, false, nil
-	}
-	if err != nil {
-		return nil, false, err
-	}
-	obj := (*v)[0]
-	if obj.Kind() != reflect.Func {
+func evalCallFunExpr(ctx *Ctx, fun reflect.Value, typed bool, call *CallExpr, env *Env) (*[]reflect.Value, bool, error) {
+	if fun.Kind() != reflect.Func {
 		// Perhaps we have a type cast?
-		if typ, ok := obj.Interface().(reflect.Type); ok {
+		if typ, ok := fun.Interface().(reflect.Type); ok {
 			val, typed, err := evalCallTypeExpr(ctx, typ, call, env)
 			retval := []reflect.Value {val}
 			return &retval, typed, err
 		} else {
-			return nil, false, errors.New(fmt.Sprintf("Cannot call %v", obj))
+			return nil, false, ErrUncallable{at(ctx, call.Fun), fun.Type()}
 		}
 	}
-	builtin := !typed
 
-	// Special case handling doesn't play well with nil Args
-	ftype := (*v)[0].Type()
-	if call.Args == nil {
-		if ftype.NumIn() == 0 {
-			out := (*v)[0].Call([]reflect.Value{})
-			return &out, true, nil
-		} else {
-			return nil, false, ErrWrongNumberOfArgsOld{(*v)[0], 0}
-		}
+	args, atyped, err := evalArgs(ctx, call, env)
+	if err != nil {
+		return nil, false, err
 	}
+	return callFunValues(ctx, env, fun, typed, call, args, atyped)
+}
 
+// evalArgs evaluates the arguments of a call
+func evalArgs(ctx *Ctx, call *CallExpr, env *Env) ([]*[]reflect.Value, []bool, error) {
 	args := make([]*[]reflect.Value, len(call.Args))
 	atyped := make([]bool, len(call.Args))
-
-	// Evaluate each arg
 	for i := range call.Args {
 		var err error
 		args[i], atyped[i], err = EvalExpr(ctx, call.Args[i].(Expr), env)
 		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return args, atyped, nil
+}
+
+// callFunValues calls fun with the evaluated arguments of call, made in
+// env. Untyped functions are builtins. Panics raised by fun are returned
+// as errors.
+func callFunValues(ctx *Ctx, env *Env, fun reflect.Value, typed bool, call *CallExpr, args []*[]reflect.Value, atyped []bool) (_ *[]reflect.Value, _ bool, err error) {
+	defer recoverFuncError(&err)
+
+	v := &[]reflect.Value{fun}
+	builtin := !typed
+	if !builtin {
+		if err := ctx.Policy.checkFunc(ctx, "call of", Callee{}, fun, call); err != nil {
+			return nil, false, err
+		} else if err := checkPureCall(ctx, calleeEnv(call, env), fun, call); err != nil {
 			return nil, false, err
 		}
 	}
 
+	// Special case handling doesn't play well with nil Args
+	ftype := (*v)[0].Type()
+	actualNumIn := ftype.NumIn()
+	if builtin {
+		// See builtinFuncs comment
+		actualNumIn = (actualNumIn - 1) / 2
+	}
+	if !builtin && call.Ellipsis.IsValid() && !ftype.IsVariadic() {
+		return nil, false, ErrWrongNumberOfArgsOld{at(ctx, call), (*v)[0], actualNumIn, len(call.Args)}
+	} else if call.Args == nil {
+		if ftype.NumIn() == 0 || !builtin && ftype.IsVariadic() && ftype.NumIn() == 1 {
+			out, err := callValue(ctx, calleeEnv(call, env), (*v)[0], []reflect.Value{}, false)
+			return &out, true, err
+		} else {
+			return nil, false, ErrWrongNumberOfArgsOld{at(ctx, call), (*v)[0], actualNumIn, 0}
+		}
+	}
+
+	// badArg reports that argument i cannot be passed as type to. The
+	// values of a splat all come from its only argument
+	wasSplat := false
+	badArg := func(i int, arg reflect.Value, to reflect.Type) error {
+		if wasSplat {
+			i = 0
+		}
+		return ErrBadFunArgument{at(ctx, call.Args[i]), call.Fun, to, defaultValue(arg, atyped[i])}
+	}
+
 	_, firstArgIsFun := call.Args[0].(*CallExpr)
 	// Special case for f(g()), where g may return multiple values
-	wasSplat := false
 	if len(call.Args) == 1 && firstArgIsFun {
 		arg := *(args[0])
 
@@ -106,12 +168,6 @@ func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]
 	}
 
 	// Parse args into a slice suitable for calling the function
-	actualNumIn := ftype.NumIn()
-	if builtin {
-		// See builtinFuncs comment
-		actualNumIn /= 2
-	}
-
 	in := make([]reflect.Value, actualNumIn)
 	intyped := make([]bool, actualNumIn)
 
@@ -124,6 +180,8 @@ func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]
 			// In the case of a splat, we cannot possibly be dealing with multi values here
 			if wasSplat {
 				arg = (*args[i])[0]
+			} else if args[i] == nil {
+				// Untyped nil, checked against the parameter type below
 			} else if arg, err = expectSingleValue(ctx, *(args[i]), call.Args[i]); err != nil {
 				return nil, false, err
 			}
@@ -138,6 +196,8 @@ func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]
 			var err error
 			if wasSplat {
 				arg = (*args[i])[0]
+			} else if args[i] == nil {
+				// Untyped nil, checked against the parameter type below
 			} else if arg, err = expectSingleValue(ctx, *(args[i]), call.Args[i]); err != nil {
 				return nil, false, err
 			}
@@ -145,15 +205,20 @@ func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]
 			intyped[i] = atyped[i]
 		}
 		if i == len(args)-1 && call.Ellipsis != token.NoPos {
-			// Call is of form f(first, second, ...others)
-			arg := *(args[i])
-			// Assert this indeed is the ellipsis
-			_ = call.Args[i].(*Ellipsis)
-			in[i], err = makeSliceWithValues(arg, ftype.In(i))
-			intyped[i] = true
-			if err != nil {
-				return nil, false, ErrBadFunArgument{(*v)[0], i, in[i]}
+			// Call is of form f(first, second, others...), others is
+			// passed as the variadic slice itself. Untyped nil
+			// evaluates to no values.
+			var arg reflect.Value
+			var err error
+			if args[i] != nil {
+				if arg, err = expectSingleValue(ctx, *(args[i]), call.Args[i]); err != nil {
+					return nil, false, err
+				}
+			}
+			if in[i], err = assignValue(ctx, arg, atyped[i], ftype.In(i), call.Args[i]); err != nil {
+				return nil, false, err
 			}
+			intyped[i] = true
 		} else if i <= len(args) && call.Ellipsis == token.NoPos {
 			// Call is of form f(first, second, third, fourth and so on)
 			remainingArgs := len(args) - actualNumIn + 1
@@ -162,63 +227,65 @@ func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]
 			intyped[i] = true
 			etype := in[i].Type().Elem()
 			for j := i; j < len(args); j += 1 {
-				if arg, err := expectSingleValue(ctx, *(args[j]), call.Args[j]); err != nil {
+				var arg reflect.Value
+				if args[j] == nil {
+					// Untyped nil, checked against the element type below
+				} else if arg, err = expectSingleValue(ctx, *(args[j]), call.Args[j]); err != nil {
 					return nil, false, err
 				} else {
 					if userConversion != nil {
 						var err error
 						arg, atyped[j], err = userConversion(arg, atyped[j])
 						if err != nil {
-							return nil, false, ErrBadFunArgument{(*v)[0], j, arg}
+							return nil, false, badArg(j, arg, etype)
 						}
 					}
-					if arg, err := assignableValue(arg, etype, atyped[j]); err != nil {
-						return nil, false, ErrBadFunArgument{(*v)[0], j, arg}
-					} else {
-						in[i].Index(j-i).Set(arg)
-					}
+				}
+				if arg, err := assignValue(ctx, arg, atyped[j], etype, call); err != nil {
+					return nil, false, badArg(j, arg, etype)
+				} else {
+					in[i].Index(j-i).Set(arg)
 				}
 			}
 		} else {
-			return nil, false, ErrWrongNumberOfArgsOld{(*v)[0], len(call.Args)}
+			return nil, false, ErrWrongNumberOfArgsOld{at(ctx, call), (*v)[0], actualNumIn, len(call.Args)}
 		}
 	} else {
-		return nil, false, ErrWrongNumberOfArgsOld{(*v)[0], len(call.Args)}
+		return nil, false, ErrWrongNumberOfArgsOld{at(ctx, call), (*v)[0], actualNumIn, len(call.Args)}
 	}
 
 	if builtin {
 		// Builtin functions take and return raw values as well as typing information
-		bin := make([]reflect.Value, len(in) * 2)
+		bin := make([]reflect.Value, len(in) * 2 + 1)
+		bin[0] = reflect.ValueOf(ctx)
 		for i := range in {
-			bin[i] = reflect.ValueOf(in[i])
-			bin[i+len(in)] = reflect.ValueOf(intyped[i])
+			bin[i+1] = reflect.ValueOf(in[i])
+			bin[i+len(in)+1] = reflect.ValueOf(intyped[i])
 		}
 		in = bin
 	} else {
 		// Check argument types
 		for i := range in {
-			if userConversion != nil {
+			if userConversion != nil && in[i].IsValid() {
 				var err error
 				in[i], intyped[i], err = userConversion(in[i], intyped[i])
 				if err != nil {
-					return nil, false, ErrBadFunArgument{(*v)[0], i, in[i]}
+					return nil, false, badArg(i, in[i], ftype.In(i))
 				}
 			}
 
 			var checked reflect.Value
-			if checked, err = assignableValue(in[i], ftype.In(i), intyped[i]); err != nil {
-				return nil, false, ErrBadFunArgument{(*v)[0], i, in[i]}
+			if checked, err = assignValue(ctx, in[i], intyped[i], ftype.In(i), call); err != nil {
+				return nil, false, badArg(i, in[i], ftype.In(i))
 			} else {
 				in[i] = checked
 			}
 		}
 	}
 
-	var ret []reflect.Value
-	if ftype.IsVariadic() {
-		ret = (*v)[0].CallSlice(in)
-	} else {
-		ret = (*v)[0].Call(in)
+	ret, err := callValue(ctx, calleeEnv(call, env), (*v)[0], in, true)
+	if err != nil {
+		return nil, false, err
 	}
 	out := &ret
 
@@ -228,6 +295,11 @@ func evalCallFunExpr(ctx *Ctx, fun reflect.Value, call *CallExpr, env *Env) (*[]
 		if !ret[2].IsNil() {
 			err = ret[2].Interface().(error)
 		}
+		if denied, ok := err.(ErrDenied); ok && denied.Node == nil {
+			// Builtins do not know the call they are made by
+			denied.ErrorContext = at(ctx, call)
+			err = denied
+		}
 		// Unwrap the Value of a Value
 		out = &[]reflect.Value{ret[0].Interface().(reflect.Value)}
 		return out, otyped, err
@@ -22,6 +22,17 @@ func TestFuncCallWithWrongArgs(t *testing.T) {
 	expectFail(t, "Foo(1.5)", env)
 }
 
+func TestFuncCallWithUnpromotableArgs(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["s"] = reflect.ValueOf(func (string) int { return 1; })
+	env.Funcs["i"] = reflect.ValueOf(func (int) int { return 1; })
+
+	expectFail(t, "s(65)", env)
+	expectFail(t, "i(1.5)", env)
+	expectResult(t, "i(2.0)", env, 1)
+	expectResult(t, "s(\"A\")", env, 1)
+}
+
 func TestFuncCallLogNewWithWrongArgs(t *testing.T) {
 	env := makeEnv()
 	logpkg := makeEnv()
@@ -66,6 +77,23 @@ func TestFuncCallWithSplatTwo(t *testing.T) {
 	expectResult(t, expr, env, expected)
 }
 
+func TestFuncCallWithEllipsis(t *testing.T) {
+	env := makeEnv()
+
+	sum := func(init int, xs ...int) int {
+		for _, x := range xs {
+			init += x
+		}
+		return init
+	}
+	env.Funcs["sum"] = reflect.ValueOf(sum)
+	env.Vars["xs"] = reflect.ValueOf(&[]int{1, 2, 3})
+
+	expectResult(t, "sum(10, xs...)", env, sum(10, []int{1, 2, 3}...))
+	expectResult(t, "sum(10, []int{}...)", env, 10)
+	expectResult(t, "sum(10, nil...)", env, 10)
+}
+
 // This test hits a specific case in the implementation where
 // f(g()) is evaluated as args := g(); f(args)
 func TestFuncCallWithMissingValueSplat(t *testing.T) {
@@ -86,6 +114,18 @@ func TestFuncCallWithMissingValue(t *testing.T) {
 	expectError(t, "g(1, f())", env, "f() used as value")
 }
 
+func TestFuncCallWithNil(t *testing.T) {
+	env := makeEnv()
+
+	env.Funcs["f"] = reflect.ValueOf(func(p *int) bool { return p == nil })
+	env.Funcs["g"] = reflect.ValueOf(func(ps ...[]int) int { return len(ps) })
+	env.Funcs["h"] = reflect.ValueOf(func(int) {})
+
+	expectResult(t, "f(nil)", env, true)
+	expectResult(t, "g(nil, nil)", env, 2)
+	expectError(t, "h(nil)", env, "cannot use nil as type int in argument to h")
+}
+
 func TestEvalCallTypeExpr(t *testing.T) {
 	type MyInt int // A simple type to test
 
@@ -117,7 +157,8 @@ func TestEvalCallTypeExpr(t *testing.T) {
 
 	expectResult(t, "bogus.MyInt(5)", &env, MyInt(5))
 	// FIXME the package below should be bogus, not eval!
-	expectError(t, "bogus.MyInt(\"abc\")", &env,
-		"Cannot convert abc to type eval.MyInt")
+	expectCheckError(t, "bogus.MyInt(\"abc\")", &env,
+		"cannot convert \"abc\" to type eval.MyInt",
+		"cannot convert \"abc\" (type string) to type eval.MyInt")
 
 }
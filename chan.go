@@ -0,0 +1,181 @@
+package eval
+
+import (
+	"reflect"
+	"time"
+
+	"go/ast"
+)
+
+// chanSelect runs reflect.Select. Unless there is a default case, the
+// select fails with ErrTimeout if it blocks past the deadline of the
+// evaluation, or with ErrCanceled or ErrDeadlineExceeded if ctx.Context is done.
+// Panics, such as a send on a closed channel, are returned as errors.
+// Read only code may not operate on channels.
+func chanSelect(ctx *Ctx, cases []reflect.SelectCase, node ast.Node) (chosen int, recv reflect.Value, recvOK bool, err error) {
+	if ctx.Policy.readOnly() {
+		return 0, reflect.Value{}, false, ErrDenied{at(ctx, node), "channel operation"}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrPanic{r}
+		}
+	}()
+
+	n := len(cases)
+	for _, c := range cases {
+		if c.Dir == reflect.SelectDefault {
+			n = -1
+		}
+	}
+	if n != -1 && !ctx.deadline.IsZero() {
+		// Once the deadline has passed, only ready cases may proceed
+		timeout := reflect.SelectCase{Dir: reflect.SelectDefault}
+		if wait := time.Until(ctx.deadline); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			timeout = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)}
+		}
+		cases = append(cases[:len(cases):len(cases)], timeout)
+	}
+	if n != -1 && ctx.Context != nil {
+		cases = append(cases[:len(cases):len(cases)],
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Context.Done())})
+	}
+
+	chosen, recv, recvOK = reflect.Select(cases)
+	if n == -1 || chosen < n {
+		return chosen, recv, recvOK, nil
+	} else if chosen == len(cases)-1 && ctx.Context != nil {
+		return chosen, reflect.Value{}, false, contextError(ctx.Context)
+	}
+	return chosen, reflect.Value{}, false, ErrTimeout{at(ctx, node), ctx.Timeout}
+}
+
+// chanCase returns the select case for a send to, or receive from, ch.
+// ch is the channel expression, used for errors.
+func chanCase(ctx *Ctx, v reflect.Value, dir reflect.SelectDir, ch Expr) (reflect.SelectCase, error) {
+	send := dir == reflect.SelectSend
+	if !v.IsValid() {
+		// A nil channel is never ready
+		return reflect.SelectCase{Dir: dir}, nil
+	} else if v.Kind() != reflect.Chan ||
+		send && v.Type().ChanDir() == reflect.RecvDir ||
+		!send && v.Type().ChanDir() == reflect.SendDir {
+		return reflect.SelectCase{}, ErrInvalidChanOp{at(ctx, ch), v.Type(), send}
+	}
+	return reflect.SelectCase{Dir: dir, Chan: v}, nil
+}
+
+// chanRecv receives from the channel v, the value of the expression ch
+func chanRecv(ctx *Ctx, v reflect.Value, ch Expr, node ast.Node) (reflect.Value, bool, error) {
+	c, err := chanCase(ctx, v, reflect.SelectRecv, ch)
+	if err != nil {
+		return reflect.Value{}, false, err
+	}
+	_, recv, ok, err := chanSelect(ctx, []reflect.SelectCase{c}, node)
+	return recv, ok, err
+}
+
+// evalRecv evaluates a receive expression, <-ch
+func evalRecv(ctx *Ctx, recv *UnaryExpr, env *Env) (reflect.Value, bool, error) {
+	ch := recv.X.(Expr)
+	v, _, err := evalSingle(ctx, ch, env)
+	if err != nil {
+		return reflect.Value{}, false, err
+	}
+	return chanRecv(ctx, v, ch, recv)
+}
+
+func evalSendStmt(ctx *Ctx, stmt *SendStmt, env *Env) error {
+	ch, value := stmt.Chan.(Expr), stmt.Value.(Expr)
+	c, err := evalSendCase(ctx, ch, value, env)
+	if err != nil {
+		return err
+	}
+	_, _, _, err = chanSelect(ctx, []reflect.SelectCase{c}, stmt)
+	return err
+}
+
+// evalSendCase evaluates the channel and value of a send
+func evalSendCase(ctx *Ctx, ch, value Expr, env *Env) (reflect.SelectCase, error) {
+	v, _, err := evalSingle(ctx, ch, env)
+	if err != nil {
+		return reflect.SelectCase{}, err
+	}
+	c, err := chanCase(ctx, v, reflect.SelectSend, ch)
+	if err != nil {
+		return c, err
+	}
+	x, typed, err := evalSingle(ctx, value, env)
+	if err != nil {
+		return c, err
+	}
+	if v.IsValid() {
+		if c.Send, err = assignValue(ctx, x, typed, v.Type().Elem(), value); err != nil {
+			return c, err
+		}
+	} else {
+		c.Send = x
+	}
+	return c, nil
+}
+
+func evalSelectStmt(ctx *Ctx, stmt *SelectStmt, label string, b *block, fr *frame) (flow, error) {
+	// The channels and sent values are evaluated in source order
+	cases := make([]reflect.SelectCase, len(stmt.Body.List))
+	for i, clause := range stmt.Body.List {
+		clause := clause.(*CommClause)
+		var err error
+		switch {
+		case clause.ch == nil:
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectDefault}
+		case clause.value != nil:
+			cases[i], err = evalSendCase(ctx, clause.ch, clause.value, b.env)
+		default:
+			var v reflect.Value
+			if v, _, err = evalSingle(ctx, clause.ch, b.env); err == nil {
+				cases[i], err = chanCase(ctx, v, reflect.SelectRecv, clause.ch)
+			}
+		}
+		if err != nil {
+			return flowNext, err
+		}
+	}
+
+	chosen, recv, recvOK, err := chanSelect(ctx, cases, stmt)
+	if err != nil {
+		return flowNext, err
+	}
+
+	clause := stmt.Body.List[chosen].(*CommClause)
+	body := b.nested()
+	defer body.restore()
+	values := []reflect.Value{recv, reflect.ValueOf(recvOK)}
+	for i, lhs := range clause.lhs {
+		if clause.define {
+			ptr := reflect.New(values[i].Type())
+			ptr.Elem().Set(values[i])
+			body.declareVar(lhs.(*Ident).Name, ptr)
+		} else if dst, store, err := evalLhs(ctx, lhs.(Expr), body.env); err != nil {
+			return flowNext, err
+		} else if dst.IsValid() {
+			if v, err := assignValue(ctx, values[i], true, dst.Type(), lhs); err != nil {
+				return flowNext, err
+			} else {
+				dst.Set(v)
+			}
+			if store != nil {
+				store()
+			}
+		}
+	}
+
+	f, err := evalStmtList(ctx, clause.Body, body, fr)
+	if err != nil {
+		return f, err
+	} else if breaks(f, label, fr) || f == flowContinue {
+		return f, nil
+	}
+	return flowNext, nil
+}
@@ -0,0 +1,144 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChanSendRecv(t *testing.T) {
+	ch := make(chan int, 2)
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	run(t, "ch <- 1\nch <- 2\nclose(ch)\na := <-ch\nb, ok := <-ch\n_, closed := <-ch", env)
+
+	expectResult(t, "a", env, int(1))
+	expectResult(t, "b", env, int(2))
+	expectResult(t, "ok", env, true)
+	expectResult(t, "closed", env, false)
+}
+
+func TestChanRange(t *testing.T) {
+	ch := make(chan int, 3)
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	run(t, "ch <- 1\nch <- 2\nch <- 3\nclose(ch)\nsum := 0\nfor i := range ch {\n\tsum += i\n}", env)
+
+	expectResult(t, "sum", env, int(6))
+}
+
+func TestSelect(t *testing.T) {
+	ch := make(chan int, 1)
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	run(t, `s := ""
+for i := 0; i < 3; i++ {
+	select {
+	case ch <- i:
+		s += "send"
+	case v := <-ch:
+		s += "recv"
+		if v != i-1 {
+			s += "!"
+		}
+	}
+}
+var x int
+var ok bool
+select {
+case x, ok = <-ch:
+default:
+	s += "default"
+}`, env)
+
+	expectResult(t, "s", env, "sendrecvsend")
+	expectResult(t, "x", env, int(2))
+	expectResult(t, "ok", env, true)
+}
+
+func TestSelectDefault(t *testing.T) {
+	ch := make(chan int)
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	run(t, `s := ""
+Loop:
+for {
+	select {
+	case <-ch:
+		s += "recv"
+	default:
+		s += "default"
+		break Loop
+	}
+}`, env)
+
+	expectResult(t, "s", env, "default")
+}
+
+func TestChanTimeout(t *testing.T) {
+	ch := make(chan int)
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	ctx := &Ctx{Timeout: time.Millisecond}
+
+	for _, src := range []string{"<-ch", "ch <- 1", "select {\ncase <-ch:\n}", "select {}"} {
+		errs := EvalSource(ctx, src, env)
+		if len(errs) != 1 {
+			t.Fatalf("Expected a timeout from '%s', got %v", src, errs)
+		} else if errs[0].Error() != "1:1: timed out after 1ms waiting on channel operation" {
+			t.Fatalf("Wrong timeout error from '%s': %v", src, errs[0])
+		}
+	}
+}
+
+func TestChanTimeoutPerEvaluation(t *testing.T) {
+	ch := make(chan int)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case ch <- 1:
+				time.Sleep(40 * time.Millisecond)
+			case <-done:
+				return
+			}
+		}
+	}()
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	ctx := &Ctx{Timeout: 100 * time.Millisecond}
+
+	// Each receive waits for less than the timeout, but together they
+	// wait for longer
+	errs := EvalSource(ctx, "for i := 0; i < 5; i += 1 {\n\t<-ch\n}", env)
+	if len(errs) != 1 {
+		t.Fatalf("Expected a timeout, got %v", errs)
+	} else if _, ok := errs[0].(*SourceError).Err.(ErrTimeout); !ok {
+		t.Fatalf("Expected a timeout, got %v", errs[0])
+	}
+
+	// Functions declared by the code time out from when they are called
+	if errs := EvalSource(ctx, "func recv() int {\n\treturn <-ch\n}", env); errs != nil {
+		t.Fatal(errs)
+	}
+	time.Sleep(ctx.Timeout)
+	if n := env.Funcs["recv"].Interface().(func() int)(); n != 1 {
+		t.Fatalf("Expected to receive 1, got %d", n)
+	}
+}
+
+func TestChanErrors(t *testing.T) {
+	var recv <-chan int
+	var send chan<- int
+	env := makeEnv()
+	env.Vars["recv"] = reflect.ValueOf(&recv)
+	env.Vars["send"] = reflect.ValueOf(&send)
+
+	expectStmtError(t, "recv <- 1", env,
+		"invalid operation: cannot send to receive-only channel recv (type <-chan int)")
+	expectStmtError(t, "x := <-send", env,
+		"invalid operation: cannot receive from send-only channel send (type chan<- int)")
+	expectStmtError(t, "select {\ndefault:\ndefault:\n}", env,
+		"multiple defaults in select")
+}
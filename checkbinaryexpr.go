@@ -39,6 +39,24 @@ func checkBinaryExpr(ctx *Ctx, binary *ast.BinaryExpr, env *Env) (aexpr *BinaryE
 		return aexpr, errs
 	}
 
+	// The type of a shift is that of its left operand alone
+	if binary.Op == token.SHL || binary.Op == token.SHR {
+		if xa.IsConst() && ya.IsConst() {
+			var z constValue
+			if z, moreErrs = evalConstShiftExpr(ctx, aexpr, xa, ya); moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			} else {
+				aexpr.constValue = z
+				if n, ok := z.Const().Interface().(*ConstNumber); ok {
+					aexpr.knownType = knownType{n.Type}
+				} else {
+					aexpr.knownType = xt
+				}
+			}
+		}
+		return aexpr, errs
+	}
+
 	xc, xuntyped := xt[0].(ConstType)
 	yc, yuntyped := yt[0].(ConstType)
 	if xa.IsConst() && ya.IsConst() {
@@ -82,7 +100,16 @@ func checkBinaryExpr(ctx *Ctx, binary *ast.BinaryExpr, env *Env) (aexpr *BinaryE
 				aexpr.constValue = z
 			}
 		} else {
-			panic("Unimplemented")
+			if z, moreErrs := evalConstTypedBinaryExpr(ctx, aexpr, xa, ya); moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			} else {
+				if isBooleanOp(binary.Op) {
+					aexpr.knownType = []reflect.Type{ConstBool}
+				} else {
+					aexpr.knownType = xt
+				}
+				aexpr.constValue = z
+			}
 		}
 	}
 	return aexpr, errs
@@ -229,27 +256,23 @@ func evalConstTypedUntypedBinaryExpr(ctx *Ctx, expr *BinaryExpr, typedExpr, unty
 	switch xt.(type) {
 	case ConstIntType, ConstRuneType, ConstFloatType, ConstComplexType:
 		x := untypedExpr.Const().Interface().(*ConstNumber)
-		var y *ConstNumber
-		switch yt.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			y = NewConstInt64(typedExpr.Const().Int())
-
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			y = NewConstUint64(typedExpr.Const().Uint())
-
-		case reflect.Float32, reflect.Float64:
-			y = NewConstFloat64(typedExpr.Const().Float())
-
-		case reflect.Complex64, reflect.Complex128:
-			y = NewConstComplex128(typedExpr.Const().Complex())
-
-		default:
+		y := typedConstNumber(typedExpr.Const())
+		if y == nil {
 			// This will result in a bad conversion error
 			_, errs := convertConstToTyped(ctx, x.Type, constValueOf(x), yt, untypedExpr)
 			return constValue{}, errs
 		}
 
-		z, errs := evalConstBinaryNumericExpr(ctx, expr, x, y)
+		var z constValue
+		var errs []error
+		if typedExpr == expr.X {
+			z, errs = evalConstBinaryNumericExpr(ctx, expr, y, x)
+		} else {
+			z, errs = evalConstBinaryNumericExpr(ctx, expr, x, y)
+		}
+		if errs != nil || isBooleanOp(expr.Op) {
+			return z, errs
+		}
 		r, moreErrs := convertConstToTyped(ctx, x.Type, z, yt, untypedExpr)
 		errs = append(errs, moreErrs...)
 		return constValue(r), errs
@@ -258,7 +281,13 @@ func evalConstTypedUntypedBinaryExpr(ctx *Ctx, expr *BinaryExpr, typedExpr, unty
 		if yt.Kind() == reflect.String {
 			xstring := untypedExpr.Const().String()
 			ystring := typedExpr.Const().String()
+			if typedExpr == expr.X {
+				xstring, ystring = ystring, xstring
+			}
 			z, errs := evalConstBinaryStringExpr(ctx, expr, xstring, ystring)
+			if errs != nil || isBooleanOp(expr.Op) {
+				return z, errs
+			}
 			r, moreErrs := convertConstToTyped(ctx, ConstString, z, yt, untypedExpr)
 			errs = append(errs, moreErrs...)
 			return constValue(r), errs
@@ -269,14 +298,109 @@ func evalConstTypedUntypedBinaryExpr(ctx *Ctx, expr *BinaryExpr, typedExpr, unty
 			xbool := untypedExpr.Const().Bool()
 			ybool := typedExpr.Const().Bool()
 			z, errs := evalConstBinaryBoolExpr(ctx, expr, xbool, ybool)
+			if errs != nil {
+				return z, errs
+			}
 			r, moreErrs := convertConstToTyped(ctx, ConstBool, z, yt, untypedExpr)
 			errs = append(errs, moreErrs...)
 			return constValue(r), errs
 		}
 
-	case ConstNilType:
-		panic("func")
 	}
-	panic("func")
+	return constValue{}, []error{ErrInvalidBinaryOperation{at(ctx, expr)}}
+}
+
+// Evaluate x op y, where x and y are typed constants
+func evalConstTypedBinaryExpr(ctx *Ctx, expr *BinaryExpr, xexpr, yexpr Expr) (constValue, []error) {
+	xt := xexpr.KnownType()[0]
+	yt := yexpr.KnownType()[0]
+	if xt != yt {
+		return constValue{}, []error{ErrInvalidBinaryOperation{at(ctx, expr)}}
+	}
+
+	x := xexpr.Const()
+	y := yexpr.Const()
+	if xn := typedConstNumber(x); xn != nil {
+		z, errs := evalConstBinaryNumericExpr(ctx, expr, xn, typedConstNumber(y))
+		if errs != nil || isBooleanOp(expr.Op) {
+			return z, errs
+		}
+		return convertConstToTyped(ctx, xn.Type, z, xt, expr)
+	}
+
+	switch xt.Kind() {
+	case reflect.String:
+		z, errs := evalConstBinaryStringExpr(ctx, expr, x.String(), y.String())
+		if errs != nil || isBooleanOp(expr.Op) {
+			return z, errs
+		}
+		return convertConstToTyped(ctx, ConstString, z, xt, expr)
+	case reflect.Bool:
+		return evalConstBinaryBoolExpr(ctx, expr, x.Bool(), y.Bool())
+	}
+	return constValue{}, []error{ErrInvalidBinaryOperation{at(ctx, expr)}}
+}
 
+// Evaluate x << y or x >> y. The shift count y must be a non-negative
+// integer, and x an integer, or an untyped constant representable as one.
+func evalConstShiftExpr(ctx *Ctx, expr *BinaryExpr, xexpr, yexpr Expr) (constValue, []error) {
+	var count *ConstNumber
+	if n, ok := yexpr.Const().Interface().(*ConstNumber); ok {
+		count = n
+	} else if _, untyped := yexpr.KnownType()[0].(ConstType); untyped {
+		// An untyped nil, bool or string is not a count
+	} else if yt := yexpr.KnownType()[0]; yt.Kind() != reflect.Float32 && yt.Kind() != reflect.Float64 {
+		count = typedConstNumber(yexpr.Const())
+	}
+
+	// Keep the shift to a size gc would not already consider an overflow
+	if count == nil || !count.Value.IsInteger() ||
+		count.Value.Re.Sign() < 0 || count.Value.Re.Num().BitLen() > 16 {
+		return constValue{}, []error{ErrInvalidShiftCount{at(ctx, yexpr), count}}
+	}
+	shift := uint(count.Value.Re.Num().Int64())
+
+	xt := xexpr.KnownType()[0]
+	var x *ConstNumber
+	if n, ok := xexpr.Const().Interface().(*ConstNumber); ok && n.Value.IsInteger() {
+		x = n
+	} else if _, untyped := xt.(ConstType); !ok && !untyped && xt.Kind() != reflect.Float32 && xt.Kind() != reflect.Float64 {
+		x = typedConstNumber(xexpr.Const())
+	}
+	if x == nil {
+		return constValue{}, []error{ErrInvalidShiftOperand{at(ctx, expr), xt}}
+	}
+
+	z := &ConstNumber{Type: ConstInt}
+	if x.Type == ConstRune {
+		z.Type = ConstRune
+	}
+	z.Value.Re.SetInt(x.Value.Re.Num())
+	if expr.Op == token.SHL {
+		z.Value.Re.Num().Lsh(z.Value.Re.Num(), shift)
+	} else {
+		z.Value.Re.Num().Rsh(z.Value.Re.Num(), shift)
+	}
+
+	if _, untyped := xt.(ConstType); untyped {
+		return constValueOf(z), nil
+	}
+	return convertConstToTyped(ctx, z.Type, constValueOf(z), xt, expr)
+}
+
+// typedConstNumber converts the value of a typed numeric constant to a
+// *ConstNumber. Returns nil if v is not numeric.
+func typedConstNumber(v reflect.Value) *ConstNumber {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewConstInt64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewConstUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return NewConstFloat64(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return NewConstComplex128(v.Complex())
+	default:
+		return nil
+	}
 }
@@ -7,27 +7,56 @@ import (
 
 func checkCallExpr(ctx *Ctx, callExpr *ast.CallExpr, env *Env) (acall *CallExpr, errs []error) {
 	acall = &CallExpr{CallExpr: callExpr}
+	if isGenericCall(callExpr, env) {
+		return checkGenericCall(ctx, acall, env)
+	}
 
-	var moreErrs []error
-	if acall.Fun, moreErrs = CheckExpr(ctx, callExpr.Fun, env); moreErrs != nil {
-		errs = append(errs, moreErrs...)
+	var funErrs, moreErrs []error
+	if isTypeLit(callExpr.Fun) {
+		acall.Fun, funErrs = checkTypeExpr(ctx, callExpr.Fun, env)
+	} else {
+		acall.Fun, funErrs = CheckExpr(ctx, callExpr.Fun, env)
 	}
 
+	// The first argument of new and make is a type
+	typeArg := funErrs == nil && (isBuiltinCall(acall, "new", env) || isBuiltinCall(acall, "make", env))
 	for i := range callExpr.Args {
-		if acall.Args[i], moreErrs = CheckExpr(ctx, callExpr.Args[i], env); moreErrs != nil {
+		if i == 0 && typeArg && isTypeLit(callExpr.Args[0]) {
+			if acall.Args[0], moreErrs = checkTypeExpr(ctx, callExpr.Args[0], env); moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			}
+		} else if acall.Args[i], moreErrs = CheckExpr(ctx, callExpr.Args[i], env); moreErrs != nil {
 			errs = append(errs, moreErrs...)
 		}
 	}
 
-	if errs != nil {
-		return acall, errs
+	if funErrs != nil {
+		return acall, append(funErrs, errs...)
 	}
 
+	// If only the arguments are poisoned, what can be checked without
+	// their types still is
 	fun := acall.Fun.(Expr)
 	if to, err := evalType(ctx, acall.Fun.(Expr), env); err == nil {
+		if errs != nil {
+			acall.knownType = []reflect.Type{to}
+			acall.isTypeConversion = true
+			if len(acall.Args) != 1 {
+				errs = append(errs, ErrWrongNumberOfArgs{at(ctx, acall)})
+			}
+			return acall, errs
+		}
 		return checkCallTypeExpr(ctx, to, acall, env)
 	} else if fun.IsConst() && fun.KnownType()[0] == ConstNil {
-		return acall, []error{ErrUntypedNil{at(ctx, fun)}}
+		return acall, append([]error{ErrUntypedNil{at(ctx, fun)}}, errs...)
+	} else if errs != nil {
+		return acall, errs
+	} else if t := staticType(fun, env); t != nil && !isCallableType(t) {
+		return acall, []error{ErrUncallable{at(ctx, fun), t}}
+	} else if err := checkReadOnlyCall(ctx, acall, env); err != nil {
+		return acall, []error{err}
+	} else if err := ctx.Policy.checkReadOnlyVarsCall(ctx, acall, env); err != nil {
+		return acall, []error{err}
 	}
 	// TODO eval function calls
 	return acall, errs
@@ -47,7 +76,12 @@ func checkCallTypeExpr(ctx *Ctx, to reflect.Type, call *CallExpr, env *Env) (*Ca
 		return call, []error{err}
 	}
 
-	if arg.IsConst() {
+	if arg.IsConst() && isStringToSliceConversion(from, to) {
+		// []byte("abc") is valid, but not constant
+		return call, nil
+	} else if _, untyped := from.(ConstType); arg.IsConst() && !untyped {
+		return checkTypedConstConversion(ctx, from, to, call, arg)
+	} else if arg.IsConst() {
 		// For bad constant conversions, gc produces two error messages. E.g. string to uint64
 		// cannot convert "abc" to type uint64
 		// cannot convert "abc" (type string) to type uint64
@@ -73,3 +107,65 @@ func checkCallTypeExpr(ctx *Ctx, to reflect.Type, call *CallExpr, env *Env) (*Ca
 		}
 	}
 }
+
+// Conversion of a typed constant. Numeric constants are converted through
+// ConstNumber, so that the result is checked for overflow. The result is
+// a constant if it is of a basic type.
+func checkTypedConstConversion(ctx *Ctx, from, to reflect.Type, call *CallExpr, arg Expr) (*CallExpr, []error) {
+	if !from.ConvertibleTo(unhackType(to)) {
+		return call, []error{ErrBadConversion{at(ctx, arg), from, to, arg.Const()}}
+	} else if n := typedConstNumber(arg.Const()); n != nil && isNumericType(to) {
+		v, errs := convertConstToTyped(ctx, n.Type, constValueOf(n), to, arg)
+		if errs == nil {
+			call.constValue = v
+		}
+		return call, errs
+	}
+
+	switch to.Kind() {
+	case reflect.Bool:
+		call.constValue = constValue(arg.Const().Convert(unhackType(to)))
+	case reflect.String:
+		// int to string conversions are left to the runtime
+		if from.Kind() == reflect.String {
+			call.constValue = constValue(arg.Const().Convert(unhackType(to)))
+		}
+	}
+	return call, nil
+}
+
+func isNumericType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
+func isStringToSliceConversion(from, to reflect.Type) bool {
+	if _, untyped := from.(ConstType); untyped && from != ConstString {
+		return false
+	} else if !untyped && from.Kind() != reflect.String {
+		return false
+	} else if to.Kind() != reflect.Slice {
+		return false
+	}
+	switch to.Elem().Kind() {
+	case reflect.Uint8, reflect.Int32:
+		return true
+	default:
+		return false
+	}
+}
+
+// isCallableType reports whether values of type t can be called, either
+// as functions or, for values holding a reflect.Type, as conversions
+func isCallableType(t reflect.Type) bool {
+	return t.Kind() == reflect.Func || t.Implements(reflectTypeType) ||
+		reflect.PtrTo(t).Implements(reflectTypeType)
+}
+
+var reflectTypeType = reflect.TypeOf((*reflect.Type)(nil)).Elem()
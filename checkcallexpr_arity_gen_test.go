@@ -0,0 +1,497 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test Nullary()
+func TestCheckCallExprArityNullaryNone(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectVoid(t, `f0()`, env)
+}
+
+// Test Nullary(1)
+func TestCheckCallExprArityNullaryInt(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0(1)`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary("b")
+func TestCheckCallExprArityNullaryString(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0("b")`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary(1.5)
+func TestCheckCallExprArityNullaryFloat(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0(1.5)`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary(nil)
+func TestCheckCallExprArityNullaryNil(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0(nil)`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary(1, 2)
+func TestCheckCallExprArityNullaryIntInt(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0(1, 2)`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary(1, "b")
+func TestCheckCallExprArityNullaryIntString(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0(1, "b")`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary(1, "b", 3)
+func TestCheckCallExprArityNullaryIntStringInt(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+
+	expectCompileError(t, `f0(1, "b", 3)`, env,
+		`too many arguments in call to f0`,
+	)
+}
+
+// Test Nullary(s...)
+func TestCheckCallExprArityNullarySpread(t *testing.T) {
+	env := makeEnv()
+	var f0 func() = func() {}
+	env.Vars["f0"] = reflect.ValueOf(&f0)
+	var s []int = []int{1, 2}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `f0(s...)`, env,
+		`cannot use ... in call to non-variadic f0`,
+	)
+}
+
+// Test Unary()
+func TestCheckCallExprArityUnaryNone(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1()`, env,
+		`not enough arguments in call to f1`,
+	)
+}
+
+// Test Unary(1)
+func TestCheckCallExprArityUnaryInt(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectResult(t, `f1(1)`, env, f1(1))
+}
+
+// Test Unary("b")
+func TestCheckCallExprArityUnaryString(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1("b")`, env,
+		`cannot use "b" (type string) as type int in argument to f1`,
+	)
+}
+
+// Test Unary(1.5)
+func TestCheckCallExprArityUnaryFloat(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1(1.5)`, env,
+		`cannot use 1.5 (type float64) as type int in argument to f1`,
+	)
+}
+
+// Test Unary(nil)
+func TestCheckCallExprArityUnaryNil(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1(nil)`, env,
+		`cannot use nil as type int in argument to f1`,
+	)
+}
+
+// Test Unary(1, 2)
+func TestCheckCallExprArityUnaryIntInt(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1(1, 2)`, env,
+		`too many arguments in call to f1`,
+	)
+}
+
+// Test Unary(1, "b")
+func TestCheckCallExprArityUnaryIntString(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1(1, "b")`, env,
+		`too many arguments in call to f1`,
+	)
+}
+
+// Test Unary(1, "b", 3)
+func TestCheckCallExprArityUnaryIntStringInt(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+
+	expectCompileError(t, `f1(1, "b", 3)`, env,
+		`too many arguments in call to f1`,
+	)
+}
+
+// Test Unary(s...)
+func TestCheckCallExprArityUnarySpread(t *testing.T) {
+	env := makeEnv()
+	var f1 func(int) int = func(x int) int { return x }
+	env.Vars["f1"] = reflect.ValueOf(&f1)
+	var s []int = []int{1, 2}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `f1(s...)`, env,
+		`cannot use ... in call to non-variadic f1`,
+	)
+}
+
+// Test Binary()
+func TestCheckCallExprArityBinaryNone(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2()`, env,
+		`not enough arguments in call to f2`,
+	)
+}
+
+// Test Binary(1)
+func TestCheckCallExprArityBinaryInt(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2(1)`, env,
+		`not enough arguments in call to f2`,
+	)
+}
+
+// Test Binary("b")
+func TestCheckCallExprArityBinaryString(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2("b")`, env,
+		`not enough arguments in call to f2`,
+	)
+}
+
+// Test Binary(1.5)
+func TestCheckCallExprArityBinaryFloat(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2(1.5)`, env,
+		`not enough arguments in call to f2`,
+	)
+}
+
+// Test Binary(nil)
+func TestCheckCallExprArityBinaryNil(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2(nil)`, env,
+		`not enough arguments in call to f2`,
+	)
+}
+
+// Test Binary(1, 2)
+func TestCheckCallExprArityBinaryIntInt(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2(1, 2)`, env,
+		`cannot use 2 (type int) as type string in argument to f2`,
+	)
+}
+
+// Test Binary(1, "b")
+func TestCheckCallExprArityBinaryIntString(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectResult(t, `f2(1, "b")`, env, f2(1, "b"))
+}
+
+// Test Binary(1, "b", 3)
+func TestCheckCallExprArityBinaryIntStringInt(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+
+	expectCompileError(t, `f2(1, "b", 3)`, env,
+		`too many arguments in call to f2`,
+	)
+}
+
+// Test Binary(s...)
+func TestCheckCallExprArityBinarySpread(t *testing.T) {
+	env := makeEnv()
+	var f2 func(int, string) int = func(x int, y string) int { return x + len(y) }
+	env.Vars["f2"] = reflect.ValueOf(&f2)
+	var s []int = []int{1, 2}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `f2(s...)`, env,
+		`cannot use ... in call to non-variadic f2`,
+	)
+}
+
+// Test Variadic()
+func TestCheckCallExprArityVariadicNone(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectResult(t, `fv()`, env, fv())
+}
+
+// Test Variadic(1)
+func TestCheckCallExprArityVariadicInt(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectResult(t, `fv(1)`, env, fv(1))
+}
+
+// Test Variadic("b")
+func TestCheckCallExprArityVariadicString(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectCompileError(t, `fv("b")`, env,
+		`cannot use "b" (type string) as type int in argument to fv`,
+	)
+}
+
+// Test Variadic(1.5)
+func TestCheckCallExprArityVariadicFloat(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectCompileError(t, `fv(1.5)`, env,
+		`cannot use 1.5 (type float64) as type int in argument to fv`,
+	)
+}
+
+// Test Variadic(nil)
+func TestCheckCallExprArityVariadicNil(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectCompileError(t, `fv(nil)`, env,
+		`cannot use nil as type int in argument to fv`,
+	)
+}
+
+// Test Variadic(1, 2)
+func TestCheckCallExprArityVariadicIntInt(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectResult(t, `fv(1, 2)`, env, fv(1, 2))
+}
+
+// Test Variadic(1, "b")
+func TestCheckCallExprArityVariadicIntString(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectCompileError(t, `fv(1, "b")`, env,
+		`cannot use "b" (type string) as type int in argument to fv`,
+	)
+}
+
+// Test Variadic(1, "b", 3)
+func TestCheckCallExprArityVariadicIntStringInt(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+
+	expectCompileError(t, `fv(1, "b", 3)`, env,
+		`cannot use "b" (type string) as type int in argument to fv`,
+	)
+}
+
+// Test Variadic(s...)
+func TestCheckCallExprArityVariadicSpread(t *testing.T) {
+	env := makeEnv()
+	var fv func(...int) int = func(xs ...int) int { return len(xs) }
+	env.Vars["fv"] = reflect.ValueOf(&fv)
+	var s []int = []int{1, 2}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `fv(s...)`, env, fv(s...))
+}
+
+// Test Int()
+func TestCheckCallExprArityIntNone(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n()`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(1)
+func TestCheckCallExprArityIntInt(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n(1)`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int("b")
+func TestCheckCallExprArityIntString(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n("b")`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(1.5)
+func TestCheckCallExprArityIntFloat(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n(1.5)`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(nil)
+func TestCheckCallExprArityIntNil(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n(nil)`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(1, 2)
+func TestCheckCallExprArityIntIntInt(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n(1, 2)`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(1, "b")
+func TestCheckCallExprArityIntIntString(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n(1, "b")`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(1, "b", 3)
+func TestCheckCallExprArityIntIntStringInt(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n(1, "b", 3)`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
+
+// Test Int(s...)
+func TestCheckCallExprArityIntSpread(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+	var s []int = []int{1, 2}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `n(s...)`, env,
+		`cannot call non-function n (type int)`,
+	)
+}
@@ -1,8 +1,8 @@
 package eval
 
 import (
-	"testing"
 	"reflect"
+	"testing"
 )
 
 // Test int8(0x7f)
@@ -2146,30 +2146,21 @@ func TestCheckCallExprStringFrom31bits(t *testing.T) {
 func TestCheckCallExprStringFrom32bits(t *testing.T) {
 	env := makeEnv()
 
-	expectCheckError(t, `string(0xffffffff)`, env,
-		`overflow in int -> string`,
-	)
-
+	expectConst(t, `string(0xffffffff)`, env, string(0xffffffff), reflect.TypeOf(string(0xffffffff)))
 }
 
 // Test string(0x7fffffffffffffff)
 func TestCheckCallExprStringFrom63bits(t *testing.T) {
 	env := makeEnv()
 
-	expectCheckError(t, `string(0x7fffffffffffffff)`, env,
-		`overflow in int -> string`,
-	)
-
+	expectConst(t, `string(0x7fffffffffffffff)`, env, string(0x7fffffffffffffff), reflect.TypeOf(string(0x7fffffffffffffff)))
 }
 
 // Test string(0xffffffffffffffff)
 func TestCheckCallExprStringFrom64bits(t *testing.T) {
 	env := makeEnv()
 
-	expectCheckError(t, `string(0xffffffffffffffff)`, env,
-		`overflow in int -> string`,
-	)
-
+	expectConst(t, `string(0xffffffffffffffff)`, env, string(0xffffffffffffffff), reflect.TypeOf(string(0xffffffffffffffff)))
 }
 
 // Test string('d')
@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+
+	"go/ast"
+	"go/token"
+)
+
+// staticType returns the type of x, if it is known when checking. The
+// checker does not track the types of variables in general, but the
+// variables and functions of env which hold values have known types.
+func staticType(x Expr, env *Env) reflect.Type {
+	if t := x.KnownType(); len(t) == 1 {
+		if _, ok := t[0].(ConstType); !ok {
+			return t[0]
+		}
+		return nil
+	}
+	if ident, ok := x.(*Ident); ok {
+		if v, ok := env.Vars[ident.Name]; ok {
+			if v.IsValid() && v.Kind() == reflect.Ptr {
+				return v.Type().Elem()
+			}
+		} else if f, ok := env.Funcs[ident.Name]; ok && f.IsValid() {
+			return f.Type()
+		}
+	}
+	return nil
+}
+
+// checkChanOp checks that a send to, or receive from, ch is allowed by
+// the type of ch, if it is known
+func checkChanOp(ctx *Ctx, ch Expr, send bool, env *Env) []error {
+	t := staticType(ch, env)
+	if t == nil {
+		return nil
+	} else if t.Kind() != reflect.Chan ||
+		send && t.ChanDir() == reflect.RecvDir ||
+		!send && t.ChanDir() == reflect.SendDir {
+		return []error{ErrInvalidChanOp{at(ctx, ch), t, send}}
+	}
+	return nil
+}
+
+// isRecv reports whether x is a receive expression, <-ch, either before
+// or after it is checked
+func isRecv(x ast.Expr) bool {
+	for {
+		switch paren := x.(type) {
+		case *ast.ParenExpr:
+			x = paren.X
+			continue
+		case *ParenExpr:
+			x = paren.X
+			continue
+		}
+		break
+	}
+	switch unary := x.(type) {
+	case *ast.UnaryExpr:
+		return unary.Op == token.ARROW
+	case *UnaryExpr:
+		return unary.Op == token.ARROW
+	}
+	return false
+}
+
+func checkSendStmt(ctx *Ctx, stmt *ast.SendStmt, scope *checkScope) (*SendStmt, []error) {
+	astmt := &SendStmt{SendStmt: stmt}
+
+	var errs, moreErrs []error
+	if stmt.Chan, moreErrs = CheckExpr(ctx, stmt.Chan, scope.Env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	} else if moreErrs = checkChanOp(ctx, stmt.Chan.(Expr), true, scope.Env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	if stmt.Value, moreErrs = CheckExpr(ctx, stmt.Value, scope.Env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	if ctx.Policy.readOnly() {
+		errs = append(errs, ErrDenied{at(ctx, stmt), "send"})
+	}
+	return astmt, errs
+}
+
+func checkSelectStmt(ctx *Ctx, stmt *ast.SelectStmt, scope *checkScope) (*SelectStmt, []error) {
+	astmt := &SelectStmt{SelectStmt: stmt}
+
+	var errs []error
+	hasDefault := false
+	for i, clause := range stmt.Body.List {
+		clause := clause.(*ast.CommClause)
+		aclause := &CommClause{CommClause: clause}
+		body := scope.block()
+		body.canBreak = true
+
+		var moreErrs []error
+		switch comm := clause.Comm.(type) {
+		case nil:
+			if hasDefault {
+				moreErrs = []error{errors.New("multiple defaults in select")}
+			}
+			hasDefault = true
+		case *ast.SendStmt:
+			var send *SendStmt
+			if send, moreErrs = checkSendStmt(ctx, comm, body); moreErrs == nil {
+				aclause.ch, aclause.value = send.Chan.(Expr), send.Value.(Expr)
+			}
+		case *ast.ExprStmt:
+			moreErrs = checkCommRecv(ctx, aclause, comm.X, nil, body)
+		case *ast.AssignStmt:
+			if len(comm.Rhs) != 1 || len(comm.Lhs) > 2 {
+				moreErrs = []error{ErrAssignCount{at(ctx, comm), len(comm.Lhs), len(comm.Rhs)}}
+			} else {
+				moreErrs = checkCommRecv(ctx, aclause, comm.Rhs[0], comm, body)
+			}
+		default:
+			moreErrs = []error{errors.New("select case must be receive, send or assign recv")}
+		}
+		if moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+
+		if moreErrs = checkStmtList(ctx, clause.Body, body); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		stmt.Body.List[i] = aclause
+	}
+	return astmt, errs
+}
+
+// checkCommRecv checks the receive of a select case, which may be
+// assigned to variables
+func checkCommRecv(ctx *Ctx, clause *CommClause, x ast.Expr, assign *ast.AssignStmt, body *checkScope) []error {
+	if !isRecv(x) {
+		return []error{errors.New("select case must be receive, send or assign recv")}
+	}
+	recv, errs := CheckExpr(ctx, x, body.Env)
+	if errs != nil {
+		return errs
+	}
+	clause.ch = skipSuperfluousParens(recv).(*UnaryExpr).X.(Expr)
+	if assign == nil {
+		return nil
+	}
+
+	clause.lhs, clause.define = assign.Lhs, assign.Tok == token.DEFINE
+	for i, lhs := range assign.Lhs {
+		if !clause.define {
+			var moreErrs []error
+			if assign.Lhs[i], moreErrs = checkAssignable(ctx, lhs, body); moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			}
+		} else if ident, ok := lhs.(*ast.Ident); !ok {
+			errs = append(errs, ErrNonName{at(ctx, lhs)})
+		} else {
+			body.declareVar(ident.Name)
+			assign.Lhs[i] = &Ident{Ident: ident}
+		}
+	}
+	return errs
+}
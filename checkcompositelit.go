@@ -1,6 +1,9 @@
 package eval
 
 import (
+	"fmt"
+	"reflect"
+
 	"go/ast"
 )
 
@@ -8,8 +11,17 @@ func checkCompositeLit(ctx *Ctx, lit *ast.CompositeLit, env *Env) (aexpr *Compos
 	aexpr = &CompositeLit{CompositeLit: lit}
 
 	var moreErrs []error
+	var t reflect.Type
 	if aexpr.Type, moreErrs = checkTypeExpr(ctx, lit.Type, env); moreErrs != nil {
 		errs = append(errs, moreErrs...)
+	} else if lit.Type != nil {
+		var err error
+		if t, err = evalType(ctx, aexpr.Type.(Expr), env); err != nil {
+			errs = append(errs, err)
+		} else if t = unhackType(t); t.Kind() == reflect.Struct {
+			aexpr.knownType = knownType{t}
+			return aexpr, checkStructLitElts(ctx, aexpr, t, env)
+		}
 	}
 
 	for i := range lit.Elts {
@@ -17,5 +29,133 @@ func checkCompositeLit(ctx *Ctx, lit *ast.CompositeLit, env *Env) (aexpr *Compos
 			errs = append(errs, moreErrs...)
 		}
 	}
+	if errs == nil && t != nil {
+		aexpr.knownType = knownType{t}
+		errs = checkLitKeys(ctx, aexpr, t)
+	}
 	return aexpr, errs
 }
+
+// checkLitKeys checks that the constant keys of an array, slice or map
+// literal are distinct, counting the implicit indices of unkeyed array
+// elements, and that every element of a map literal has a key
+func checkLitKeys(ctx *Ctx, lit *CompositeLit, t reflect.Type) (errs []error) {
+	isMap := t.Kind() == reflect.Map
+	if !isMap && t.Kind() != reflect.Array && t.Kind() != reflect.Slice {
+		return nil
+	}
+	seen := map[string]ast.Node{}
+	var index uint64
+	for _, elt := range lit.Elts {
+		kv, keyed := elt.(*KeyValueExpr)
+		var key, name string
+		var node ast.Node = elt
+		if isMap {
+			if !keyed {
+				errs = append(errs, ErrMissingMapKey{at(ctx, elt)})
+				continue
+			} else if k := kv.Key.(Expr); !k.IsConst() {
+				continue
+			} else {
+				key, node = fmt.Sprint(k.Const()), k
+				name = at(ctx, k).Source()
+			}
+		} else {
+			if keyed {
+				var ok bool
+				// Invalid keys are reported by evaluation
+				if index, ok = arrayKey(kv.Key.(Expr)); !ok {
+					continue
+				}
+				node = kv.Key
+			}
+			key = fmt.Sprint(index)
+			name = key
+			index += 1
+		}
+		if first, ok := seen[key]; ok {
+			errs = append(errs, ErrDuplicateKey{at(ctx, node), t, name, first})
+		} else {
+			seen[key] = node
+		}
+	}
+	return errs
+}
+
+// checkStructLitElts checks the elements of a literal of the struct type
+// t. Keys name the struct's own fields, an embedded field being named
+// after its type. They are not checked as expressions, a key may name a
+// field whatever the name means elsewhere.
+func checkStructLitElts(ctx *Ctx, lit *CompositeLit, t reflect.Type, env *Env) (errs []error) {
+	keyed := false
+	if len(lit.Elts) > 0 {
+		_, keyed = lit.Elts[0].(*ast.KeyValueExpr)
+	}
+	seen := map[string]*ast.Ident{}
+	for i, elt := range lit.Elts {
+		var moreErrs []error
+		kv, isKeyValue := elt.(*ast.KeyValueExpr)
+		if isKeyValue != keyed {
+			errs = append(errs, ErrMixedStructLit{at(ctx, elt)})
+			continue
+		} else if !keyed {
+			if lit.Elts[i], moreErrs = CheckExpr(ctx, elt, env); moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			} else if i < t.NumField() {
+				if err := checkConstLitElt(ctx, lit.Elts[i].(Expr), t.Field(i).Type, env); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		akv := &KeyValueExpr{KeyValueExpr: kv}
+		lit.Elts[i] = akv
+		if akv.Value, moreErrs = CheckExpr(ctx, kv.Value, env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			errs = append(errs, ErrInvalidFieldName{at(ctx, kv.Key)})
+			continue
+		}
+		akv.Key = &Ident{Ident: key}
+		if f, ok := t.FieldByName(key.Name); !ok {
+			errs = append(errs, ErrUnknownField{at(ctx, key), t, key.Name})
+		} else if len(f.Index) > 1 {
+			errs = append(errs, ErrPromotedField{at(ctx, key), t, promotedPath(t, f.Index)})
+		} else if f.PkgPath != "" {
+			errs = append(errs, ErrUnexportedField{at(ctx, key), t, key.Name})
+		} else if first, ok := seen[key.Name]; ok {
+			errs = append(errs, ErrDuplicateField{at(ctx, key), key.Name, first})
+		} else {
+			seen[key.Name] = key
+			if moreErrs == nil {
+				if err := checkConstLitElt(ctx, akv.Value.(Expr), f.Type, env); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	if n := len(lit.Elts); !keyed && n > 0 && n != t.NumField() {
+		errs = append(errs, ErrStructLitCount{at(ctx, lit), t, n < t.NumField()})
+	} else if !keyed && n > 0 {
+		for i, elt := range lit.Elts {
+			if f := t.Field(i); f.PkgPath != "" {
+				errs = append(errs, ErrUnexportedField{at(ctx, elt), t, f.Name})
+			}
+		}
+	}
+	return errs
+}
+
+// checkConstLitElt checks that a constant element can be assigned to a
+// field of type t, so that the error is reported before those of the
+// literal as a whole. Other elements are checked by evaluation.
+func checkConstLitElt(ctx *Ctx, elt Expr, t reflect.Type, env *Env) error {
+	if !elt.IsConst() {
+		return nil
+	}
+	return evalLitElt(ctx, reflect.New(t).Elem(), elt, env)
+}
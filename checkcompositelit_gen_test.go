@@ -0,0 +1,548 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test Slice{Empty}
+func TestCheckCompositeLitSliceEmpty(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[]int{}`, env, []int{})
+}
+
+// Test Slice{One}
+func TestCheckCompositeLitSliceOne(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[]int{1}`, env, []int{1})
+}
+
+// Test Slice{Two}
+func TestCheckCompositeLitSliceTwo(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[]int{1, 2}`, env, []int{1, 2})
+}
+
+// Test Slice{Three}
+func TestCheckCompositeLitSliceThree(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[]int{1, 2, 3}`, env, []int{1, 2, 3})
+}
+
+// Test Slice{Key}
+func TestCheckCompositeLitSliceKey(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[]int{1: 2}`, env, []int{1: 2})
+}
+
+// Test Slice{NegativeKey}
+func TestCheckCompositeLitSliceNegativeKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{-1: 2}`, env,
+		`array index must be non-negative integer constant`,
+	)
+}
+
+// Test Slice{DuplicateKey}
+func TestCheckCompositeLitSliceDuplicateKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{1: 2, 1: 3}`, env,
+		`duplicate index in array literal: 1`,
+	)
+}
+
+// Test Slice{FloatKey}
+func TestCheckCompositeLitSliceFloatKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{1.5: 2}`, env,
+		`array index must be non-negative integer constant`,
+	)
+}
+
+// Test Slice{Mixed}
+func TestCheckCompositeLitSliceMixed(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{1, 0: 2}`, env,
+		`duplicate index in array literal: 0`,
+	)
+}
+
+// Test Slice{String}
+func TestCheckCompositeLitSliceString(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{"a"}`, env,
+		`cannot use "a" (type string) as type int in assignment`,
+	)
+}
+
+// Test Slice{Field}
+func TestCheckCompositeLitSliceField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{A: 1}`, env,
+		`A undefined`,
+	)
+}
+
+// Test Slice{Fields}
+func TestCheckCompositeLitSliceFields(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{A: 1, B: "b"}`, env,
+		`A undefined`,
+	)
+}
+
+// Test Slice{MissingField}
+func TestCheckCompositeLitSliceMissingField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{C: 1}`, env,
+		`C undefined`,
+	)
+}
+
+// Test Slice{Values}
+func TestCheckCompositeLitSliceValues(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[]int{1, "b"}`, env,
+		`cannot use "b" (type string) as type int in assignment`,
+	)
+}
+
+// Test Array{Empty}
+func TestCheckCompositeLitArrayEmpty(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[2]int{}`, env, [2]int{})
+}
+
+// Test Array{One}
+func TestCheckCompositeLitArrayOne(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[2]int{1}`, env, [2]int{1})
+}
+
+// Test Array{Two}
+func TestCheckCompositeLitArrayTwo(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[2]int{1, 2}`, env, [2]int{1, 2})
+}
+
+// Test Array{Three}
+func TestCheckCompositeLitArrayThree(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{1, 2, 3}`, env,
+		`array index 2 out of bounds [0:2]`,
+	)
+}
+
+// Test Array{Key}
+func TestCheckCompositeLitArrayKey(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `[2]int{1: 2}`, env, [2]int{1: 2})
+}
+
+// Test Array{NegativeKey}
+func TestCheckCompositeLitArrayNegativeKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{-1: 2}`, env,
+		`array index must be non-negative integer constant`,
+	)
+}
+
+// Test Array{DuplicateKey}
+func TestCheckCompositeLitArrayDuplicateKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{1: 2, 1: 3}`, env,
+		`duplicate index in array literal: 1`,
+	)
+}
+
+// Test Array{FloatKey}
+func TestCheckCompositeLitArrayFloatKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{1.5: 2}`, env,
+		`array index must be non-negative integer constant`,
+	)
+}
+
+// Test Array{Mixed}
+func TestCheckCompositeLitArrayMixed(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{1, 0: 2}`, env,
+		`duplicate index in array literal: 0`,
+	)
+}
+
+// Test Array{String}
+func TestCheckCompositeLitArrayString(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{"a"}`, env,
+		`cannot use "a" (type string) as type int in assignment`,
+	)
+}
+
+// Test Array{Field}
+func TestCheckCompositeLitArrayField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{A: 1}`, env,
+		`A undefined`,
+	)
+}
+
+// Test Array{Fields}
+func TestCheckCompositeLitArrayFields(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{A: 1, B: "b"}`, env,
+		`A undefined`,
+	)
+}
+
+// Test Array{MissingField}
+func TestCheckCompositeLitArrayMissingField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{C: 1}`, env,
+		`C undefined`,
+	)
+}
+
+// Test Array{Values}
+func TestCheckCompositeLitArrayValues(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[2]int{1, "b"}`, env,
+		`cannot use "b" (type string) as type int in assignment`,
+	)
+}
+
+// Test Map{Empty}
+func TestCheckCompositeLitMapEmpty(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `map[int]int{}`, env, map[int]int{})
+}
+
+// Test Map{One}
+func TestCheckCompositeLitMapOne(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1}`, env,
+		`missing key in map literal`,
+	)
+}
+
+// Test Map{Two}
+func TestCheckCompositeLitMapTwo(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1, 2}`, env,
+		`missing key in map literal`,
+	)
+}
+
+// Test Map{Three}
+func TestCheckCompositeLitMapThree(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1, 2, 3}`, env,
+		`missing key in map literal`,
+	)
+}
+
+// Test Map{Key}
+func TestCheckCompositeLitMapKey(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `map[int]int{1: 2}`, env, map[int]int{1: 2})
+}
+
+// Test Map{NegativeKey}
+func TestCheckCompositeLitMapNegativeKey(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `map[int]int{-1: 2}`, env, map[int]int{-1: 2})
+}
+
+// Test Map{DuplicateKey}
+func TestCheckCompositeLitMapDuplicateKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1: 2, 1: 3}`, env,
+		`duplicate key 1 in map literal`,
+	)
+}
+
+// Test Map{FloatKey}
+func TestCheckCompositeLitMapFloatKey(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1.5: 2}`, env,
+		`cannot use 1.5 (type float64) as type int in assignment`,
+	)
+}
+
+// Test Map{Mixed}
+func TestCheckCompositeLitMapMixed(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1, 0: 2}`, env,
+		`missing key in map literal`,
+	)
+}
+
+// Test Map{String}
+func TestCheckCompositeLitMapString(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{"a"}`, env,
+		`missing key in map literal`,
+	)
+}
+
+// Test Map{Field}
+func TestCheckCompositeLitMapField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{A: 1}`, env,
+		`A undefined`,
+	)
+}
+
+// Test Map{Fields}
+func TestCheckCompositeLitMapFields(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{A: 1, B: "b"}`, env,
+		`A undefined`,
+	)
+}
+
+// Test Map{MissingField}
+func TestCheckCompositeLitMapMissingField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{C: 1}`, env,
+		`C undefined`,
+	)
+}
+
+// Test Map{Values}
+func TestCheckCompositeLitMapValues(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `map[int]int{1, "b"}`, env,
+		`missing key in map literal`,
+	)
+}
+
+// Test Struct{Empty}
+func TestCheckCompositeLitStructEmpty(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectResult(t, `T{}`, env, T{})
+}
+
+// Test Struct{One}
+func TestCheckCompositeLitStructOne(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1}`, env,
+		`too few values in struct literal of type eval.T`,
+	)
+}
+
+// Test Struct{Two}
+func TestCheckCompositeLitStructTwo(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1, 2}`, env,
+		`cannot use 2 (type int) as type string in assignment`,
+	)
+}
+
+// Test Struct{Three}
+func TestCheckCompositeLitStructThree(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1, 2, 3}`, env,
+		`cannot use 2 (type int) as type string in assignment`,
+	)
+}
+
+// Test Struct{Key}
+func TestCheckCompositeLitStructKey(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1: 2}`, env,
+		`invalid field name 1 in struct literal`,
+	)
+}
+
+// Test Struct{NegativeKey}
+func TestCheckCompositeLitStructNegativeKey(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{-1: 2}`, env,
+		`invalid field name -1 in struct literal`,
+	)
+}
+
+// Test Struct{DuplicateKey}
+func TestCheckCompositeLitStructDuplicateKey(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1: 2, 1: 3}`, env,
+		`invalid field name 1 in struct literal`,
+	)
+}
+
+// Test Struct{FloatKey}
+func TestCheckCompositeLitStructFloatKey(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1.5: 2}`, env,
+		`invalid field name 1.5 in struct literal`,
+	)
+}
+
+// Test Struct{Mixed}
+func TestCheckCompositeLitStructMixed(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1, 0: 2}`, env,
+		`mixture of field:value and value elements in struct literal`,
+	)
+}
+
+// Test Struct{String}
+func TestCheckCompositeLitStructString(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{"a"}`, env,
+		`cannot use "a" (type string) as type int in assignment`,
+	)
+}
+
+// Test Struct{Field}
+func TestCheckCompositeLitStructField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectResult(t, `T{A: 1}`, env, T{A: 1})
+}
+
+// Test Struct{Fields}
+func TestCheckCompositeLitStructFields(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectResult(t, `T{A: 1, B: "b"}`, env, T{A: 1, B: "b"})
+}
+
+// Test Struct{MissingField}
+func TestCheckCompositeLitStructMissingField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{C: 1}`, env,
+		`unknown field C in struct literal of type eval.T`,
+	)
+}
+
+// Test Struct{Values}
+func TestCheckCompositeLitStructValues(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectResult(t, `T{1, "b"}`, env, T{1, "b"})
+}
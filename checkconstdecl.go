@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+func checkConstDecl(ctx *Ctx, decl *ast.GenDecl, env *Env) (cdecl *ConstDecl, errs []error) {
+	cdecl = &ConstDecl{GenDecl: decl}
+
+	// Constants declared earlier in the block are visible to later specs
+	scope := newScope(env)
+
+	// The type and unchecked values of the last spec with values. Specs
+	// without values implicitly repeat them, with a different iota.
+	var typ reflect.Type
+	var repeat []ast.Expr
+
+	for iota, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		scope.Consts["iota"] = reflect.ValueOf(NewConstInt64(int64(iota)))
+
+		var values []ast.Expr
+		if spec.Values != nil {
+			repeat = cloneExprs(spec.Values)
+			values = spec.Values
+			typ = nil
+			if spec.Type != nil {
+				var moreErrs []error
+				if typ, moreErrs = checkConstSpecType(ctx, spec, scope); moreErrs != nil {
+					errs = append(errs, moreErrs...)
+					continue
+				}
+			}
+		} else if spec.Type != nil || repeat == nil {
+			errs = append(errs, ErrMissingConstValue{at(ctx, spec)})
+			continue
+		} else {
+			values = cloneExprs(repeat)
+		}
+
+		for i, name := range spec.Names {
+			if i >= len(values) {
+				errs = append(errs, ErrMissingConstValue{at(ctx, spec)})
+				break
+			}
+			value, cexpr, moreErrs := checkConstValue(ctx, values[i], typ, scope)
+			if cexpr != nil {
+				values[i] = cexpr
+			}
+			if moreErrs != nil {
+				errs = append(errs, moreErrs...)
+				continue
+			}
+			cdecl.names = append(cdecl.names, name.Name)
+			cdecl.values = append(cdecl.values, value)
+			if name.Name != "_" {
+				delete(scope.Vars, name.Name)
+				scope.Consts[name.Name] = value
+			}
+		}
+		if len(values) > len(spec.Names) {
+			errs = append(errs, ErrExtraConstValue{at(ctx, values[len(spec.Names)])})
+		}
+	}
+	return cdecl, errs
+}
+
+func checkConstSpecType(ctx *Ctx, spec *ast.ValueSpec, env *Env) (reflect.Type, []error) {
+	texpr, errs := checkTypeExpr(ctx, spec.Type, env)
+	if errs != nil {
+		return nil, errs
+	}
+	spec.Type = texpr
+	if t, err := evalType(ctx, texpr, env); err != nil {
+		return nil, []error{err}
+	} else {
+		return t, nil
+	}
+}
+
+// checkConstValue checks the initializer of a constant, converting it
+// to typ if it is non-nil. The returned value is stored in Env.Consts,
+// see checkIdent for how it is interpreted.
+func checkConstValue(ctx *Ctx, expr ast.Expr, typ reflect.Type, env *Env) (reflect.Value, Expr, []error) {
+	cexpr, errs := CheckExpr(ctx, expr, env)
+	if errs != nil {
+		return reflect.Value{}, cexpr, errs
+	} else if !cexpr.IsConst() || cexpr.KnownType()[0] == ConstNil {
+		return reflect.Value{}, cexpr, []error{ErrNotConstant{at(ctx, cexpr)}}
+	} else if typ == nil {
+		return cexpr.Const(), cexpr, nil
+	}
+
+	from := cexpr.KnownType()[0]
+	if ct, ok := from.(ConstType); ok {
+		v, errs := convertConstToTyped(ctx, ct, constValue(cexpr.Const()), typ, cexpr)
+		return reflect.Value(v), cexpr, errs
+	} else if from.AssignableTo(typ) {
+		return cexpr.Const(), cexpr, nil
+	} else {
+		return reflect.Value{}, cexpr, []error{ErrBadAssignment{at(ctx, cexpr), from, typ, "const initializer"}}
+	}
+}
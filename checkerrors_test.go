@@ -0,0 +1,47 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Independent errors are all reported by a single check
+func TestCheckReportsAllErrors(t *testing.T) {
+	s := []int{1}
+	p := new(int)
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&s)
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCheckError(t, "s[int8(1000):uint8(-1)]", env,
+		"constant 1000 overflows int8",
+		"constant -1 overflows uint8")
+	expectCheckError(t, "*(p + int8(1000))", env, "constant 1000 overflows int8")
+	expectCheckError(t, "[]X{int8(1000)}", env,
+		"undefined type: X",
+		"constant 1000 overflows int8")
+	expectCheckError(t, "func(a, b X, c Y) {}", env,
+		"undefined type: X",
+		"undefined type: Y")
+	expectCheckError(t, "func(x X) int { return int8(1000) }", env,
+		"undefined type: X",
+		"constant 1000 overflows int8")
+	expectCheckError(t, "int8(1000, uint8(-1))", env,
+		"constant -1 overflows uint8",
+		"too many arguments to conversion to int8")
+	expectCheckError(t, "nil(int8(1000))", env,
+		"use of untyped nil",
+		"constant 1000 overflows int8")
+}
+
+// A poisoned subexpression does not cause errors in the expressions
+// built from it
+func TestCheckPoisonedErrors(t *testing.T) {
+	env := makeEnv()
+
+	expectCheckError(t, "int8(1000) + uint8(1)", env, "constant 1000 overflows int8")
+	expectCheckError(t, "func(x X) int { return x + 1 }", env, "undefined type: X")
+	expectCheckError(t, "func() (X, int) { return 1 }", env,
+		"undefined type: X",
+		"not enough arguments to return")
+}
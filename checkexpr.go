@@ -13,58 +13,69 @@ func CheckExpr(ctx *Ctx, expr ast.Expr, env *Env) (Expr, []error) {
 	case *ast.BadExpr:
 		return &BadExpr{BadExpr: expr}, nil
 	case *ast.Ident:
+		if g := lookupGeneric(expr, env); g != nil {
+			return &Ident{Ident: expr}, []error{ErrUninstantiatedGeneric{at(ctx, expr), g}}
+		}
 		return checkIdent(ctx, expr, env)
 	case *ast.Ellipsis:
 		return &Ellipsis{Ellipsis: expr}, nil
 	case *ast.BasicLit:
 		return checkBasicLit(ctx, expr, env)
 	case *ast.FuncLit:
-		return &FuncLit{FuncLit: expr}, nil
+		return checkFuncLit(ctx, expr, env)
 	case *ast.CompositeLit:
 		return checkCompositeLit(ctx, expr, env)
 	case *ast.ParenExpr:
 		return checkParenExpr(ctx, expr, env)
 	case *ast.SelectorExpr:
+		if g := lookupGeneric(expr, env); g != nil {
+			return &SelectorExpr{SelectorExpr: expr}, []error{ErrUninstantiatedGeneric{at(ctx, expr), g}}
+		}
 		return checkSelectorExpr(ctx, expr, env)
 	case *ast.IndexExpr:
+		if lookupGeneric(expr.X, env) != nil {
+			return checkGeneric(ctx, expr, env)
+		}
 		return checkIndexExpr(ctx, expr, env)
+	case *ast.IndexListExpr:
+		return checkGeneric(ctx, expr, env)
 	case *ast.SliceExpr:
-		return &SliceExpr{SliceExpr: expr}, nil
+		return checkSliceExpr(ctx, expr, env)
 	case *ast.TypeAssertExpr:
-		return &TypeAssertExpr{TypeAssertExpr: expr}, nil
+		return checkTypeAssertExpr(ctx, expr, env)
 	case *ast.CallExpr:
 		return checkCallExpr(ctx, expr, env)
 	case *ast.StarExpr:
-		return &StarExpr{StarExpr: expr}, nil
+		return checkStarExpr(ctx, expr, env)
 	case *ast.UnaryExpr:
 		return checkUnaryExpr(ctx, expr, env)
 	case *ast.BinaryExpr:
 		return checkBinaryExpr(ctx, expr, env)
 	case *ast.KeyValueExpr:
 		return checkKeyValueExpr(ctx, expr, env)
+	case *ast.ArrayType, *ast.StructType, *ast.FuncType, *ast.InterfaceType, *ast.MapType, *ast.ChanType:
+		// Types are only checked where a type may appear, see isTypeLit
+		aexpr, _ := checkTypeExpr(ctx, expr, env)
+		return aexpr, []error{ErrNotExpression{at(ctx, expr)}}
 	default:
 		return nil, []error{errors.New(fmt.Sprintf("Type: Bad expr (%+v)", expr))}
 	}
 
 }
 
-func checkTypeExpr(ctx *Ctx, expr ast.Expr, env *Env) (Expr, []error) {
+// isTypeLit reports whether expr is a type literal, such as []int, or a
+// pointer to one, possibly parenthesized. These are checked with
+// checkTypeExpr where a type is expected, as CheckExpr rejects them.
+// Named types are checked by CheckExpr, as the name may be a value.
+func isTypeLit(expr ast.Expr) bool {
 	switch expr := expr.(type) {
-	case *ast.Ident:
-		return &Ident{Ident: expr}, nil
-	case *ast.ArrayType:
-		return &ArrayType{ArrayType: expr}, nil
-	case *ast.StructType:
-		return &StructType{StructType: expr}, nil
-	case *ast.FuncType:
-		return &FuncType{FuncType: expr}, nil
-	case *ast.InterfaceType:
-		return &InterfaceType{InterfaceType: expr}, nil
-	case *ast.MapType:
-		return &MapType{MapType: expr}, nil
-	case *ast.ChanType:
-		return &ChanType{ChanType: expr}, nil
+	case *ast.ParenExpr:
+		return isTypeLit(expr.X)
+	case *ast.StarExpr:
+		return isTypeLit(expr.X)
+	case *ast.ArrayType, *ast.StructType, *ast.FuncType, *ast.InterfaceType, *ast.MapType, *ast.ChanType:
+		return true
 	default:
-		return nil, []error{errors.New(fmt.Sprintf("Type: Bad type (%+v)", expr))}
+		return false
 	}
 }
@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+// function is a checked function literal or declaration
+type function struct {
+	t reflect.Type
+
+	// Names of the parameters and results, "_" if unnamed
+	params, results []string
+
+	body *ast.BlockStmt
+}
+
+func checkFuncDecl(ctx *Ctx, decl *ast.FuncDecl, env *Env) (*FuncDecl, []error) {
+	adecl := &FuncDecl{FuncDecl: decl}
+	if decl.Recv != nil {
+		return adecl, []error{ErrMethodDecl{at(ctx, decl)}}
+	} else if decl.Type.TypeParams != nil {
+		return adecl, []error{ErrUnsupportedType{at(ctx, decl), "generic functions are not implemented"}}
+	} else if decl.Body == nil {
+		return adecl, []error{ErrMissingFuncBody{at(ctx, decl)}}
+	}
+	var errs []error
+	adecl.fn, errs = checkFunc(ctx, decl.Type, decl.Body, env)
+	return adecl, errs
+}
+
+func checkFuncLit(ctx *Ctx, lit *ast.FuncLit, env *Env) (*FuncLit, []error) {
+	aexpr := &FuncLit{FuncLit: lit}
+	fn, errs := checkFunc(ctx, lit.Type, lit.Body, env)
+	if errs == nil {
+		aexpr.fn = fn
+		aexpr.knownType = knownType{fn.t}
+	}
+	return aexpr, errs
+}
+
+// checkFunc checks the signature and body of a function in place. The
+// parameters and results are visible to the body, as is everything in
+// env. The body is checked even if the signature has errors, the
+// parameters are then declared without types.
+func checkFunc(ctx *Ctx, ftype *ast.FuncType, body *ast.BlockStmt, env *Env) (*function, []error) {
+	texpr, errs := checkTypeExpr(ctx, ftype, env)
+	var t reflect.Type
+	if errs == nil {
+		var err error
+		if t, err = evalType(ctx, texpr, env); err != nil {
+			errs = signatureErrors(ctx, ftype, env, err)
+		}
+	}
+
+	fn := &function{
+		t:       t,
+		params:  fieldNames(ftype.Params),
+		results: fieldNames(ftype.Results),
+		body:    body,
+	}
+
+	scope := &checkScope{
+		Env:      newScope(env),
+		declared: map[string]bool{},
+		fn: &funcScope{
+			results: make([]reflect.Type, len(fn.results)),
+			named:   ftype.Results != nil && len(ftype.Results.List) > 0 && ftype.Results.List[0].Names != nil,
+		},
+	}
+	if t != nil {
+		for i := range scope.fn.results {
+			scope.fn.results[i] = t.Out(i)
+		}
+	}
+	for _, name := range fn.params {
+		scope.declareVar(name)
+	}
+	for _, name := range fn.results {
+		scope.declareVar(name)
+	}
+
+	if moreErrs := checkBlock(ctx, body, scope); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	} else if len(fn.results) > 0 && !isTerminatingList(body.List) {
+		errs = append(errs, ErrMissingReturn{at(ctx, body)})
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return fn, nil
+}
+
+// signatureErrors evaluates the parameter and result types of a checked
+// signature one at a time, so that every undefined type is reported
+// rather than only the first. err is returned if no single type fails.
+func signatureErrors(ctx *Ctx, ftype *ast.FuncType, env *Env, err error) (errs []error) {
+	for _, list := range []*ast.FieldList{ftype.Params, ftype.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			texpr := field.Type
+			if ellipsis, ok := texpr.(*Ellipsis); ok {
+				texpr = ellipsis.Elt
+			}
+			if _, err := evalType(ctx, texpr.(Expr), env); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if errs == nil {
+		return []error{err}
+	}
+	return errs
+}
+
+// fieldNames returns the names of a parameter or result list, with one
+// entry per type
+func fieldNames(list *ast.FieldList) (names []string) {
+	if list == nil {
+		return nil
+	}
+	for _, field := range list.List {
+		if field.Names == nil {
+			names = append(names, "_")
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
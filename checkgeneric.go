@@ -0,0 +1,248 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+	"go/token"
+)
+
+// lookupGeneric returns the generic function named by x, an identifier
+// or a package qualified identifier, unless the name is shadowed
+func lookupGeneric(x ast.Expr, env *Env) *Generic {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return envGeneric(x.Name, env)
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok && envGeneric(pkg.Name, env) == nil {
+			_, isVar := env.Vars[pkg.Name]
+			if p, ok := env.Pkgs[pkg.Name]; ok && !isVar {
+				return envGeneric(x.Sel.Name, (*Env)(p))
+			}
+		}
+	}
+	return nil
+}
+
+func envGeneric(name string, env *Env) *Generic {
+	_, isVar := env.Vars[name]
+	_, isConst := env.Consts[name]
+	_, isFunc := env.Funcs[name]
+	_, isType := env.Types[name]
+	if isVar || isConst || isFunc || isType {
+		return nil
+	}
+	return env.Generics[name]
+}
+
+// genericIndex returns the generic function and type arguments of an
+// instantiation, f[T] or f[T, U], or nil if x is not one
+func genericIndex(x ast.Expr, env *Env) (*ast.IndexListExpr, *Generic) {
+	switch x := x.(type) {
+	case *ast.IndexExpr:
+		if g := lookupGeneric(x.X, env); g != nil {
+			return &ast.IndexListExpr{X: x.X, Lbrack: x.Lbrack, Indices: []ast.Expr{x.Index}, Rbrack: x.Rbrack}, g
+		}
+	case *ast.IndexListExpr:
+		return x, lookupGeneric(x.X, env)
+	case *ast.ParenExpr:
+		return genericIndex(x.X, env)
+	}
+	return nil, nil
+}
+
+// isGenericCall reports whether call calls a generic function
+func isGenericCall(call *ast.CallExpr, env *Env) bool {
+	if index, g := genericIndex(call.Fun, env); index != nil {
+		return g != nil
+	}
+	return lookupGeneric(call.Fun, env) != nil
+}
+
+// checkGeneric checks a use of a generic function other than a call
+func checkGeneric(ctx *Ctx, x ast.Expr, env *Env) (Expr, []error) {
+	if index, g := genericIndex(x, env); index == nil {
+		return nil, nil
+	} else if g == nil {
+		return &IndexListExpr{IndexListExpr: index}, []error{ErrNotGeneric{at(ctx, index.X)}}
+	} else {
+		aexpr, errs := checkTypeArgs(ctx, index, g, env)
+		if errs == nil && len(aexpr.targs) != len(g.typeParams) {
+			errs = append(errs, ErrTypeArgCount{at(ctx, index), g, len(aexpr.targs)})
+		} else if errs == nil {
+			var err error
+			if aexpr.fn, err = instantiate(ctx, g, aexpr.targs, index); err != nil {
+				errs = append(errs, err)
+			} else {
+				aexpr.knownType = knownType{aexpr.fn.Type()}
+			}
+		}
+		return aexpr, errs
+	}
+}
+
+// checkTypeArgs evaluates the type arguments of an instantiation. The
+// Indices of index are not annotated, they are cloned before checking.
+func checkTypeArgs(ctx *Ctx, index *ast.IndexListExpr, g *Generic, env *Env) (*IndexListExpr, []error) {
+	aexpr := &IndexListExpr{IndexListExpr: index, generic: g}
+	if len(index.Indices) > len(g.typeParams) {
+		return aexpr, []error{ErrTypeArgCount{at(ctx, index), g, len(index.Indices)}}
+	}
+	var errs []error
+	for _, targ := range index.Indices {
+		texpr, moreErrs := checkTypeExpr(ctx, cloneExpr(targ), env)
+		if moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		} else if t, err := evalType(ctx, texpr, env); err != nil {
+			errs = append(errs, err)
+		} else {
+			aexpr.targs = append(aexpr.targs, unhackType(t))
+		}
+	}
+	return aexpr, errs
+}
+
+// checkGenericCall checks a call of a generic function, whose type
+// arguments may be given explicitly or inferred from the arguments. If
+// the types of the arguments are not all known, the instantiation is
+// left until the call is evaluated.
+func checkGenericCall(ctx *Ctx, call *CallExpr, env *Env) (*CallExpr, []error) {
+	var fun *IndexListExpr
+	var errs []error
+	if index, g := genericIndex(call.Fun, env); index != nil {
+		if fun, errs = checkTypeArgs(ctx, index, g, env); errs != nil {
+			return call, errs
+		}
+	} else {
+		g = lookupGeneric(call.Fun, env)
+		fun = &IndexListExpr{
+			// The brackets are placed so that the node spans the name
+			IndexListExpr: &ast.IndexListExpr{X: call.Fun, Lbrack: token.NoPos, Rbrack: call.Fun.End() - 1},
+			generic:       g,
+		}
+	}
+	call.Fun = fun
+
+	for i := range call.Args {
+		var moreErrs []error
+		if call.Args[i], moreErrs = CheckExpr(ctx, call.Args[i], env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	if errs != nil {
+		return call, errs
+	}
+
+	if len(fun.targs) != len(fun.generic.typeParams) {
+		var args []reflect.Type
+		known := true
+		if len(call.Args) == 1 && len(call.Args[0].(Expr).KnownType()) > 1 {
+			// f(g()), where g returns multiple values
+			args = call.Args[0].(Expr).KnownType()
+		} else {
+			for _, arg := range call.Args {
+				t := staticType(arg.(Expr), env)
+				if kt := arg.(Expr).KnownType(); t == nil && len(kt) == 1 {
+					// Untyped constants
+					t = kt[0]
+				}
+				known = known && t != nil
+				args = append(args, t)
+			}
+		}
+		targs, err := inferTypeArgs(ctx, fun, call, args)
+		if _, ok := err.(ErrCannotInfer); ok && !known {
+			return call, nil
+		} else if err != nil {
+			return call, []error{err}
+		}
+		fun.targs = targs
+	}
+
+	var err error
+	if fun.fn, err = instantiate(ctx, fun.generic, fun.targs, call); err != nil {
+		return call, []error{err}
+	}
+	fun.knownType = knownType{fun.fn.Type()}
+	return call, nil
+}
+
+// inferTypeArgs infers the type arguments of a call to fun, given the
+// types of the arguments. The type of an untyped constant is its
+// ConstType, and that of an argument whose type is not known is nil.
+// Untyped constants are only used for type parameters which are not
+// otherwise inferred, and then take the default type of the constant
+// of the largest kind, so that T is float64 in f[T any](T, T)(1, 2.5).
+func inferTypeArgs(ctx *Ctx, fun *IndexListExpr, call *CallExpr, args []reflect.Type) ([]reflect.Type, error) {
+	g := fun.generic
+	targs := make([]reflect.Type, len(g.typeParams))
+	copy(targs, fun.targs)
+
+	spread := call.Ellipsis.IsValid()
+	param := func(i int) ast.Expr {
+		if last := len(g.params) - 1; g.variadic && i >= last {
+			if spread {
+				return g.params[last]
+			}
+			return g.params[last].(*ast.ArrayType).Elt
+		} else if i < len(g.params) {
+			return g.params[i]
+		}
+		return nil
+	}
+	arg := func(i int) ast.Node {
+		if i < len(call.Args) {
+			return call.Args[i]
+		}
+		return call
+	}
+
+	for i, t := range args {
+		if _, untyped := t.(ConstType); untyped || t == nil || param(i) == nil {
+			continue
+		} else if !g.unify(param(i), unhackType(t), targs) {
+			return nil, ErrInferMismatch{at(ctx, arg(i)), g, t, param(i)}
+		}
+	}
+	consts := make([]ConstType, len(targs))
+	for i, t := range args {
+		if c, untyped := t.(ConstType); untyped && param(i) != nil {
+			if ident, ok := param(i).(*ast.Ident); ok {
+				if j := g.typeParam(ident.Name); j >= 0 && targs[j] == nil {
+					if consts[j] == nil || constKindRank(c) > constKindRank(consts[j]) {
+						consts[j] = c
+					}
+				}
+			}
+		}
+	}
+	for j, c := range consts {
+		if c != nil {
+			targs[j] = defaultConstType(c)
+		}
+	}
+	for i, t := range targs {
+		if t == nil {
+			return nil, ErrCannotInfer{at(ctx, call), g, g.typeParams[i]}
+		}
+	}
+	return targs, nil
+}
+
+// instantiate returns the instantiation of g for targs, with any error
+// positioned at node
+func instantiate(ctx *Ctx, g *Generic, targs []reflect.Type, node ast.Node) (reflect.Value, error) {
+	fn, err := g.Instantiate(targs)
+	switch e := err.(type) {
+	case ErrNotInstantiated:
+		e.ErrorContext = at(ctx, node)
+		return fn, e
+	case ErrBadInstance:
+		e.ErrorContext = at(ctx, node)
+		return fn, e
+	case ErrTypeArgCount:
+		e.ErrorContext = at(ctx, node)
+		return fn, e
+	}
+	return fn, err
+}
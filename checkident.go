@@ -19,7 +19,45 @@ func checkIdent(ctx *Ctx, ident *ast.Ident, env *Env) (*Ident, []error) {
 	case "false":
 		aexpr.constValue = constValueOf(false)
 		aexpr.knownType = []reflect.Type{ConstBool}
+
+	default:
+		// Variables shadow constants, as in EvalIdentExpr
+		if _, ok := env.Vars[aexpr.Name]; ok {
+			break
+		} else if v, ok := env.Consts[aexpr.Name]; ok && v.IsValid() {
+			aexpr.constValue, aexpr.knownType = envConst(v)
+		} else if v, ok := env.Funcs[aexpr.Name]; ok {
+			callee := Callee{Pkg: env.Path, Name: aexpr.Name}
+			if err := ctx.Policy.checkFunc(ctx, "use of", callee, v, ident); err != nil {
+				return aexpr, []error{err}
+			}
+		}
 	}
 
 	return aexpr, nil
 }
+
+// envConst interprets a value stored in Env.Consts. Untyped numeric
+// constants are stored as a *ConstNumber. Strings, bools and the int64,
+// float64 and complex128 values the evaluator uses to represent
+// untyped numbers are also treated as untyped. Any other value is a
+// constant of its own type.
+func envConst(v reflect.Value) (constValue, knownType) {
+	if n, ok := v.Interface().(*ConstNumber); ok {
+		return constValueOf(n), knownType{n.Type}
+	}
+	switch v.Type() {
+	case reflect.TypeOf(int64(0)):
+		return constValueOf(NewConstInt64(v.Int())), knownType{ConstInt}
+	case reflect.TypeOf(float64(0)):
+		return constValueOf(NewConstFloat64(v.Float())), knownType{ConstFloat}
+	case reflect.TypeOf(complex128(0)):
+		return constValueOf(NewConstComplex128(v.Complex())), knownType{ConstComplex}
+	case reflect.TypeOf(""):
+		return constValueOf(v.String()), knownType{ConstString}
+	case reflect.TypeOf(false):
+		return constValueOf(v.Bool()), knownType{ConstBool}
+	default:
+		return constValue(v), knownType{v.Type()}
+	}
+}
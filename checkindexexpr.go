@@ -17,3 +17,25 @@ func checkIndexExpr(ctx *Ctx, index *ast.IndexExpr, env *Env) (aexpr *IndexExpr,
 
 	return aexpr, errs
 }
+
+// isIndex reports whether x is an index expression, either before or
+// after it is checked. Whether it indexes a map, and so may be assigned
+// to v, ok, is only known at run time.
+func isIndex(x ast.Expr) bool {
+	for {
+		switch paren := x.(type) {
+		case *ast.ParenExpr:
+			x = paren.X
+			continue
+		case *ParenExpr:
+			x = paren.X
+			continue
+		}
+		break
+	}
+	switch x.(type) {
+	case *ast.IndexExpr, *IndexExpr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,834 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test Slice[Int]
+func TestCheckIndexExprSliceInt(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[1]`, env, s[1])
+}
+
+// Test Slice[Negative]
+func TestCheckIndexExprSliceNegative(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[-1]`, env,
+		`invalid slice index -1 (index must be non-negative)`,
+	)
+}
+
+// Test Slice[OutOfRange]
+func TestCheckIndexExprSliceOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[3]`, env, s[3])
+}
+
+// Test Slice[Float]
+func TestCheckIndexExprSliceFloat(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[1.5]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test Slice[IntegralFloat]
+func TestCheckIndexExprSliceIntegralFloat(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[1.0]`, env, s[1.0])
+}
+
+// Test Slice[String]
+func TestCheckIndexExprSliceString(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s["a"]`, env,
+		`non-integer slice index "a"`,
+	)
+}
+
+// Test Slice[Bool]
+func TestCheckIndexExprSliceBool(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[true]`, env,
+		`non-integer slice index true`,
+	)
+}
+
+// Test Slice[Nil]
+func TestCheckIndexExprSliceNil(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[nil]`, env,
+		`non-integer slice index nil`,
+	)
+}
+
+// Test Slice[IntVar]
+func TestCheckIndexExprSliceIntVar(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `s[i]`, env, s[i])
+}
+
+// Test Slice[FloatVar]
+func TestCheckIndexExprSliceFloatVar(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `s[f]`, env,
+		`non-integer slice index f`,
+	)
+}
+
+// Test Array[Int]
+func TestCheckIndexExprArrayInt(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[1]`, env, a[1])
+}
+
+// Test Array[Negative]
+func TestCheckIndexExprArrayNegative(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[-1]`, env,
+		`invalid array index -1 (index must be non-negative)`,
+	)
+}
+
+// Test Array[OutOfRange]
+func TestCheckIndexExprArrayOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[3]`, env,
+		`invalid array index 3 (out of bounds for 3-element array)`,
+	)
+}
+
+// Test Array[Float]
+func TestCheckIndexExprArrayFloat(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[1.5]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test Array[IntegralFloat]
+func TestCheckIndexExprArrayIntegralFloat(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[1.0]`, env, a[1.0])
+}
+
+// Test Array[String]
+func TestCheckIndexExprArrayString(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a["a"]`, env,
+		`non-integer array index "a"`,
+	)
+}
+
+// Test Array[Bool]
+func TestCheckIndexExprArrayBool(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[true]`, env,
+		`non-integer array index true`,
+	)
+}
+
+// Test Array[Nil]
+func TestCheckIndexExprArrayNil(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[nil]`, env,
+		`non-integer array index nil`,
+	)
+}
+
+// Test Array[IntVar]
+func TestCheckIndexExprArrayIntVar(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `a[i]`, env, a[i])
+}
+
+// Test Array[FloatVar]
+func TestCheckIndexExprArrayFloatVar(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `a[f]`, env,
+		`non-integer array index f`,
+	)
+}
+
+// Test ArrayPtr[Int]
+func TestCheckIndexExprArrayPtrInt(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[1]`, env, p[1])
+}
+
+// Test ArrayPtr[Negative]
+func TestCheckIndexExprArrayPtrNegative(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[-1]`, env,
+		`invalid array index -1 (index must be non-negative)`,
+	)
+}
+
+// Test ArrayPtr[OutOfRange]
+func TestCheckIndexExprArrayPtrOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[3]`, env,
+		`invalid array index 3 (out of bounds for 3-element array)`,
+	)
+}
+
+// Test ArrayPtr[Float]
+func TestCheckIndexExprArrayPtrFloat(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[1.5]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test ArrayPtr[IntegralFloat]
+func TestCheckIndexExprArrayPtrIntegralFloat(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[1.0]`, env, p[1.0])
+}
+
+// Test ArrayPtr[String]
+func TestCheckIndexExprArrayPtrString(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p["a"]`, env,
+		`non-integer array index "a"`,
+	)
+}
+
+// Test ArrayPtr[Bool]
+func TestCheckIndexExprArrayPtrBool(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[true]`, env,
+		`non-integer array index true`,
+	)
+}
+
+// Test ArrayPtr[Nil]
+func TestCheckIndexExprArrayPtrNil(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[nil]`, env,
+		`non-integer array index nil`,
+	)
+}
+
+// Test ArrayPtr[IntVar]
+func TestCheckIndexExprArrayPtrIntVar(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `p[i]`, env, p[i])
+}
+
+// Test ArrayPtr[FloatVar]
+func TestCheckIndexExprArrayPtrFloatVar(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `p[f]`, env,
+		`non-integer array index f`,
+	)
+}
+
+// Test String[Int]
+func TestCheckIndexExprStringInt(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[1]`, env, str[1])
+}
+
+// Test String[Negative]
+func TestCheckIndexExprStringNegative(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[-1]`, env,
+		`invalid string index -1 (index must be non-negative)`,
+	)
+}
+
+// Test String[OutOfRange]
+func TestCheckIndexExprStringOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[3]`, env, str[3])
+}
+
+// Test String[Float]
+func TestCheckIndexExprStringFloat(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[1.5]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test String[IntegralFloat]
+func TestCheckIndexExprStringIntegralFloat(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[1.0]`, env, str[1.0])
+}
+
+// Test String[String]
+func TestCheckIndexExprStringString(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str["a"]`, env,
+		`non-integer string index "a"`,
+	)
+}
+
+// Test String[Bool]
+func TestCheckIndexExprStringBool(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[true]`, env,
+		`non-integer string index true`,
+	)
+}
+
+// Test String[Nil]
+func TestCheckIndexExprStringNil(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[nil]`, env,
+		`non-integer string index nil`,
+	)
+}
+
+// Test String[IntVar]
+func TestCheckIndexExprStringIntVar(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `str[i]`, env, str[i])
+}
+
+// Test String[FloatVar]
+func TestCheckIndexExprStringFloatVar(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `str[f]`, env,
+		`non-integer string index f`,
+	)
+}
+
+// Test ConstString[Int]
+func TestCheckIndexExprConstStringInt(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `"abc"[1]`, env, "abc"[1])
+}
+
+// Test ConstString[Negative]
+func TestCheckIndexExprConstStringNegative(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[-1]`, env,
+		`invalid string index -1 (index must be non-negative)`,
+	)
+}
+
+// Test ConstString[OutOfRange]
+func TestCheckIndexExprConstStringOutOfRange(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[3]`, env,
+		`invalid string index 3 (out of bounds for 3-byte string)`,
+	)
+}
+
+// Test ConstString[Float]
+func TestCheckIndexExprConstStringFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[1.5]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test ConstString[IntegralFloat]
+func TestCheckIndexExprConstStringIntegralFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `"abc"[1.0]`, env, "abc"[1.0])
+}
+
+// Test ConstString[String]
+func TestCheckIndexExprConstStringString(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"["a"]`, env,
+		`non-integer string index "a"`,
+	)
+}
+
+// Test ConstString[Bool]
+func TestCheckIndexExprConstStringBool(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[true]`, env,
+		`non-integer string index true`,
+	)
+}
+
+// Test ConstString[Nil]
+func TestCheckIndexExprConstStringNil(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[nil]`, env,
+		`non-integer string index nil`,
+	)
+}
+
+// Test ConstString[IntVar]
+func TestCheckIndexExprConstStringIntVar(t *testing.T) {
+	env := makeEnv()
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `"abc"[i]`, env, "abc"[i])
+}
+
+// Test ConstString[FloatVar]
+func TestCheckIndexExprConstStringFloatVar(t *testing.T) {
+	env := makeEnv()
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `"abc"[f]`, env,
+		`non-integer string index f`,
+	)
+}
+
+// Test Map[Int]
+func TestCheckIndexExprMapInt(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectResult(t, `m[1]`, env, m[1])
+}
+
+// Test Map[Negative]
+func TestCheckIndexExprMapNegative(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectResult(t, `m[-1]`, env, m[-1])
+}
+
+// Test Map[OutOfRange]
+func TestCheckIndexExprMapOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectResult(t, `m[3]`, env, m[3])
+}
+
+// Test Map[Float]
+func TestCheckIndexExprMapFloat(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectCompileError(t, `m[1.5]`, env,
+		`cannot convert 1.5 (type float64) to type int`,
+	)
+}
+
+// Test Map[IntegralFloat]
+func TestCheckIndexExprMapIntegralFloat(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectResult(t, `m[1.0]`, env, m[1.0])
+}
+
+// Test Map[String]
+func TestCheckIndexExprMapString(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectCompileError(t, `m["a"]`, env,
+		`cannot convert "a" (type string) to type int`,
+	)
+}
+
+// Test Map[Bool]
+func TestCheckIndexExprMapBool(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectCompileError(t, `m[true]`, env,
+		`cannot convert true (type bool) to type int`,
+	)
+}
+
+// Test Map[Nil]
+func TestCheckIndexExprMapNil(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectCompileError(t, `m[nil]`, env,
+		`cannot convert nil to type int`,
+	)
+}
+
+// Test Map[IntVar]
+func TestCheckIndexExprMapIntVar(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `m[i]`, env, m[i])
+}
+
+// Test Map[FloatVar]
+func TestCheckIndexExprMapFloatVar(t *testing.T) {
+	env := makeEnv()
+	var m map[int]int = map[int]int{1: 2}
+	env.Vars["m"] = reflect.ValueOf(&m)
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `m[f]`, env,
+		`cannot convert f (type float64) to type int`,
+	)
+}
+
+// Test Int[Int]
+func TestCheckIndexExprIntInt(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1]`, env,
+		`invalid operation: n[1] (index of type int)`,
+	)
+}
+
+// Test Int[Negative]
+func TestCheckIndexExprIntNegative(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[-1]`, env,
+		`invalid operation: n[-1] (index of type int)`,
+	)
+}
+
+// Test Int[OutOfRange]
+func TestCheckIndexExprIntOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[3]`, env,
+		`invalid operation: n[3] (index of type int)`,
+	)
+}
+
+// Test Int[Float]
+func TestCheckIndexExprIntFloat(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1.5]`, env,
+		`invalid operation: n[1.5] (index of type int)`,
+	)
+}
+
+// Test Int[IntegralFloat]
+func TestCheckIndexExprIntIntegralFloat(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1.0]`, env,
+		`invalid operation: n[1.0] (index of type int)`,
+	)
+}
+
+// Test Int[String]
+func TestCheckIndexExprIntString(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n["a"]`, env,
+		`invalid operation: n["a"] (index of type int)`,
+	)
+}
+
+// Test Int[Bool]
+func TestCheckIndexExprIntBool(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[true]`, env,
+		`invalid operation: n[true] (index of type int)`,
+	)
+}
+
+// Test Int[Nil]
+func TestCheckIndexExprIntNil(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[nil]`, env,
+		`invalid operation: n[nil] (index of type int)`,
+	)
+}
+
+// Test Int[IntVar]
+func TestCheckIndexExprIntIntVar(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectCompileError(t, `n[i]`, env,
+		`invalid operation: n[i] (index of type int)`,
+	)
+}
+
+// Test Int[FloatVar]
+func TestCheckIndexExprIntFloatVar(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `n[f]`, env,
+		`invalid operation: n[f] (index of type int)`,
+	)
+}
+
+// Test Nil[Int]
+func TestCheckIndexExprNilInt(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[Negative]
+func TestCheckIndexExprNilNegative(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[-1]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[OutOfRange]
+func TestCheckIndexExprNilOutOfRange(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[3]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[Float]
+func TestCheckIndexExprNilFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1.5]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[IntegralFloat]
+func TestCheckIndexExprNilIntegralFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1.0]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[String]
+func TestCheckIndexExprNilString(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil["a"]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[Bool]
+func TestCheckIndexExprNilBool(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[true]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[Nil]
+func TestCheckIndexExprNilNil(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[nil]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[IntVar]
+func TestCheckIndexExprNilIntVar(t *testing.T) {
+	env := makeEnv()
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectCompileError(t, `nil[i]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[FloatVar]
+func TestCheckIndexExprNilFloatVar(t *testing.T) {
+	env := makeEnv()
+	var f float64 = 1
+	env.Vars["f"] = reflect.ValueOf(&f)
+
+	expectCompileError(t, `nil[f]`, env,
+		`use of untyped nil`,
+	)
+}
@@ -10,6 +10,10 @@ func checkParenExpr(ctx *Ctx, paren *ast.ParenExpr, env *Env) (aexpr *ParenExpr,
 	var moreErrs []error
 	if aexpr.X, moreErrs = CheckExpr(ctx, paren.X, env); moreErrs != nil {
 		errs = append(errs, moreErrs...)
+	} else {
+		x := aexpr.X.(Expr)
+		aexpr.knownType = knownType(x.KnownType())
+		aexpr.constValue = constValue(x.Const())
 	}
 	return aexpr, errs
 }
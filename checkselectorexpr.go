@@ -1,6 +1,8 @@
 package eval
 
 import (
+	"reflect"
+
 	"go/ast"
 )
 
@@ -10,6 +12,75 @@ func checkSelectorExpr(ctx *Ctx, selector *ast.SelectorExpr, env *Env) (aexpr *S
 	var moreErrs []error
 	if aexpr.X, moreErrs = CheckExpr(ctx, selector.X, env); moreErrs != nil {
 		errs = append(errs, moreErrs...)
+	} else if x := aexpr.X.(Expr); x.IsConst() && isConstNil(x) {
+		errs = append(errs, ErrUntypedNil{at(ctx, x)})
+	} else if err := checkInterfaceMethod(ctx, aexpr, env); err != nil {
+		errs = append(errs, err)
+	} else if err := checkUnexportedField(ctx, aexpr, env); err != nil {
+		errs = append(errs, err)
+	} else if err := checkSelectorPolicy(ctx, aexpr, env); err != nil {
+		errs = append(errs, err)
 	}
 	return aexpr, errs
 }
+
+func isConstNil(x Expr) bool {
+	_, ok := x.KnownType()[0].(ConstNilType)
+	return ok
+}
+
+// checkInterfaceMethod checks that a selector on a value of a known
+// interface type selects one of its methods
+func checkInterfaceMethod(ctx *Ctx, selector *SelectorExpr, env *Env) error {
+	t := staticType(selector.X.(Expr), env)
+	if t == nil || t.Kind() != reflect.Interface {
+		return nil
+	} else if _, ok := t.MethodByName(selector.Sel.Name); !ok {
+		return ErrNoMethod{at(ctx, selector), t, selector.Sel.Name}
+	}
+	return nil
+}
+
+// checkSelectorPolicy checks the Policy of ctx for the packages,
+// functions and methods a selector is known to use
+func checkSelectorPolicy(ctx *Ctx, selector *SelectorExpr, env *Env) error {
+	policy := ctx.Policy
+	if policy == nil {
+		return nil
+	}
+	sel := selector.Sel.Name
+	if pkg, ok := packageOf(selector, env); ok {
+		penv := (*Env)(pkg)
+		if !policy.allowPackage(penv.Path) {
+			return ErrDenied{at(ctx, selector), "use of package " + penv.Path}
+		} else if v, ok := penv.Funcs[sel]; ok {
+			return policy.checkFunc(ctx, "use of", Callee{Pkg: penv.Path, Name: sel}, v, selector)
+		}
+		return nil
+	}
+
+	t := staticType(selector.X.(Expr), env)
+	if t == nil {
+		return nil
+	} else if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		if _, isField := t.Elem().FieldByName(sel); isField {
+			return nil
+		}
+	} else if t.Kind() == reflect.Struct {
+		if _, isField := t.FieldByName(sel); isField {
+			return nil
+		} else if _, ok := t.MethodByName(sel); !ok {
+			if _, ok := reflect.PtrTo(t).MethodByName(sel); ok {
+				// The method takes the address of x
+				if name, ok := readOnlyPath(ctx, selector.X.(Expr), env); ok {
+					return ErrDenied{at(ctx, selector), "taking the address of " + name}
+				}
+				t = reflect.PtrTo(t)
+			}
+		}
+	}
+	if _, ok := t.MethodByName(sel); ok {
+		return policy.checkMethod(ctx, t, sel, selector)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test Struct.Field
+func TestCheckSelectorExprStructField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var st T = T{1, "b"}
+	env.Vars["st"] = reflect.ValueOf(&st)
+
+	expectResult(t, `st.A`, env, st.A)
+}
+
+// Test Struct.StringField
+func TestCheckSelectorExprStructStringField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var st T = T{1, "b"}
+	env.Vars["st"] = reflect.ValueOf(&st)
+
+	expectResult(t, `st.B`, env, st.B)
+}
+
+// Test Struct.Missing
+func TestCheckSelectorExprStructMissing(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var st T = T{1, "b"}
+	env.Vars["st"] = reflect.ValueOf(&st)
+
+	expectCompileError(t, `st.C`, env,
+		`st.C undefined (type eval.T has no field or method C)`,
+	)
+}
+
+// Test Struct.Lower
+func TestCheckSelectorExprStructLower(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var st T = T{1, "b"}
+	env.Vars["st"] = reflect.ValueOf(&st)
+
+	expectCompileError(t, `st.a`, env,
+		`st.a undefined (type eval.T has no field or method a)`,
+	)
+}
+
+// Test StructPtr.Field
+func TestCheckSelectorExprStructPtrField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var pt *T = &T{1, "b"}
+	env.Vars["pt"] = reflect.ValueOf(&pt)
+
+	expectResult(t, `pt.A`, env, pt.A)
+}
+
+// Test StructPtr.StringField
+func TestCheckSelectorExprStructPtrStringField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var pt *T = &T{1, "b"}
+	env.Vars["pt"] = reflect.ValueOf(&pt)
+
+	expectResult(t, `pt.B`, env, pt.B)
+}
+
+// Test StructPtr.Missing
+func TestCheckSelectorExprStructPtrMissing(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var pt *T = &T{1, "b"}
+	env.Vars["pt"] = reflect.ValueOf(&pt)
+
+	expectCompileError(t, `pt.C`, env,
+		`pt.C undefined (type *eval.T has no field or method C)`,
+	)
+}
+
+// Test StructPtr.Lower
+func TestCheckSelectorExprStructPtrLower(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var pt *T = &T{1, "b"}
+	env.Vars["pt"] = reflect.ValueOf(&pt)
+
+	expectCompileError(t, `pt.a`, env,
+		`pt.a undefined (type *eval.T has no field or method a)`,
+	)
+}
+
+// Test StructLit.Field
+func TestCheckSelectorExprStructLitField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectResult(t, `T{1, "b"}.A`, env, T{1, "b"}.A)
+}
+
+// Test StructLit.StringField
+func TestCheckSelectorExprStructLitStringField(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectResult(t, `T{1, "b"}.B`, env, T{1, "b"}.B)
+}
+
+// Test StructLit.Missing
+func TestCheckSelectorExprStructLitMissing(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1, "b"}.C`, env,
+		`T{1, "b"}.C undefined (type eval.T has no field or method C)`,
+	)
+}
+
+// Test StructLit.Lower
+func TestCheckSelectorExprStructLitLower(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectCompileError(t, `T{1, "b"}.a`, env,
+		`T{1, "b"}.a undefined (type eval.T has no field or method a)`,
+	)
+}
+
+// Test Int.Field
+func TestCheckSelectorExprIntField(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n.A`, env,
+		`n.A undefined (type int has no field or method A)`,
+	)
+}
+
+// Test Int.StringField
+func TestCheckSelectorExprIntStringField(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n.B`, env,
+		`n.B undefined (type int has no field or method B)`,
+	)
+}
+
+// Test Int.Missing
+func TestCheckSelectorExprIntMissing(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n.C`, env,
+		`n.C undefined (type int has no field or method C)`,
+	)
+}
+
+// Test Int.Lower
+func TestCheckSelectorExprIntLower(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n.a`, env,
+		`n.a undefined (type int has no field or method a)`,
+	)
+}
+
+// Test Nil.Field
+func TestCheckSelectorExprNilField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil.A`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil.StringField
+func TestCheckSelectorExprNilStringField(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil.B`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil.Missing
+func TestCheckSelectorExprNilMissing(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil.C`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil.Lower
+func TestCheckSelectorExprNilLower(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil.a`, env,
+		`use of untyped nil`,
+	)
+}
@@ -0,0 +1,84 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+func checkSliceExpr(ctx *Ctx, slice *ast.SliceExpr, env *Env) (aexpr *SliceExpr, errs []error) {
+	aexpr = &SliceExpr{SliceExpr: slice}
+
+	var moreErrs []error
+	if aexpr.X, moreErrs = CheckExpr(ctx, slice.X, env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	for _, index := range []*ast.Expr{&aexpr.Low, &aexpr.High, &aexpr.Max} {
+		if *index == nil {
+			continue
+		}
+		if *index, moreErrs = CheckExpr(ctx, *index, env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	if errs == nil {
+		if err := checkSliceOperand(ctx, aexpr, env); err != nil {
+			errs = append(errs, err)
+		} else if t := staticType(aexpr.X.(Expr), env); t != nil && t.Kind() == reflect.Array {
+			// Slicing an array takes its address
+			if name, ok := readOnlyPath(ctx, aexpr.X.(Expr), env); ok {
+				errs = append(errs, ErrDenied{at(ctx, slice), "slicing " + name})
+			}
+		}
+	}
+
+	return aexpr, errs
+}
+
+// checkSliceOperand checks that the operand of a slice expression can be
+// sliced, if its type is known
+func checkSliceOperand(ctx *Ctx, slice *SliceExpr, env *Env) error {
+	x := slice.X.(Expr)
+	if x.IsConst() {
+		switch x.KnownType()[0].(type) {
+		case ConstNilType:
+			return ErrUntypedNil{at(ctx, x)}
+		case ConstStringType:
+			if slice.Slice3 {
+				return ErrThreeIndexString{at(ctx, slice)}
+			}
+			return nil
+		}
+	}
+	t := staticType(x, env)
+	if t == nil {
+		return nil
+	} else if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Array {
+		t = t.Elem()
+	} else if t.Kind() == reflect.Array && !isAddressable(x) {
+		return ErrUnaddressableSlice{at(ctx, slice)}
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice:
+		return nil
+	case reflect.String:
+		if slice.Slice3 {
+			return ErrThreeIndexString{at(ctx, slice)}
+		}
+		return nil
+	}
+	return ErrCannotSlice{at(ctx, x), t}
+}
+
+// isAddressable reports whether x is addressable, as far as can be told
+// from its syntax. Evaluation does not track addressability, a composite
+// literal is stored in an addressable value like any other.
+func isAddressable(x Expr) bool {
+	switch node := x.(type) {
+	case *ParenExpr:
+		return isAddressable(node.X.(Expr))
+	case *CompositeLit, *CallExpr, *BasicLit, *FuncLit, *TypeAssertExpr:
+		return false
+	}
+	return true
+}
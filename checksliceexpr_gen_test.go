@@ -0,0 +1,858 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test Slice[:]
+func TestCheckSliceExprSliceAll(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[:]`, env, s[:])
+}
+
+// Test Slice[1:]
+func TestCheckSliceExprSliceLow(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[1:]`, env, s[1:])
+}
+
+// Test Slice[:2]
+func TestCheckSliceExprSliceHigh(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[:2]`, env, s[:2])
+}
+
+// Test Slice[1:2]
+func TestCheckSliceExprSliceLowHigh(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[1:2]`, env, s[1:2])
+}
+
+// Test Slice[1:2:3]
+func TestCheckSliceExprSliceLowHighMax(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[1:2:3]`, env, s[1:2:3])
+}
+
+// Test Slice[:2:3]
+func TestCheckSliceExprSliceHighMax(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[:2:3]`, env, s[:2:3])
+}
+
+// Test Slice[2:1]
+func TestCheckSliceExprSliceInverted(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[2:1]`, env,
+		`invalid slice index: 2 > 1`,
+	)
+}
+
+// Test Slice[-1:]
+func TestCheckSliceExprSliceNegative(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[-1:]`, env,
+		`invalid slice index -1 (index must be non-negative)`,
+	)
+}
+
+// Test Slice[:4]
+func TestCheckSliceExprSliceOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectResult(t, `s[:4]`, env, s[:4])
+}
+
+// Test Slice[1.5:]
+func TestCheckSliceExprSliceFloat(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectCompileError(t, `s[1.5:]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test Slice[i:]
+func TestCheckSliceExprSliceIntVar(t *testing.T) {
+	env := makeEnv()
+	var s []int = []int{1, 2, 3, 4}
+	env.Vars["s"] = reflect.ValueOf(&s)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `s[i:]`, env, s[i:])
+}
+
+// Test Array[:]
+func TestCheckSliceExprArrayAll(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[:]`, env, a[:])
+}
+
+// Test Array[1:]
+func TestCheckSliceExprArrayLow(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[1:]`, env, a[1:])
+}
+
+// Test Array[:2]
+func TestCheckSliceExprArrayHigh(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[:2]`, env, a[:2])
+}
+
+// Test Array[1:2]
+func TestCheckSliceExprArrayLowHigh(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[1:2]`, env, a[1:2])
+}
+
+// Test Array[1:2:3]
+func TestCheckSliceExprArrayLowHighMax(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[1:2:3]`, env, a[1:2:3])
+}
+
+// Test Array[:2:3]
+func TestCheckSliceExprArrayHighMax(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectResult(t, `a[:2:3]`, env, a[:2:3])
+}
+
+// Test Array[2:1]
+func TestCheckSliceExprArrayInverted(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[2:1]`, env,
+		`invalid slice index: 2 > 1`,
+	)
+}
+
+// Test Array[-1:]
+func TestCheckSliceExprArrayNegative(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[-1:]`, env,
+		`invalid slice index -1 (index must be non-negative)`,
+	)
+}
+
+// Test Array[:4]
+func TestCheckSliceExprArrayOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[:4]`, env,
+		`invalid slice index 4 (out of bounds for 3-element array)`,
+	)
+}
+
+// Test Array[1.5:]
+func TestCheckSliceExprArrayFloat(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+
+	expectCompileError(t, `a[1.5:]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test Array[i:]
+func TestCheckSliceExprArrayIntVar(t *testing.T) {
+	env := makeEnv()
+	var a [3]int = [3]int{1, 2, 3}
+	env.Vars["a"] = reflect.ValueOf(&a)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `a[i:]`, env, a[i:])
+}
+
+// Test ArrayPtr[:]
+func TestCheckSliceExprArrayPtrAll(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[:]`, env, p[:])
+}
+
+// Test ArrayPtr[1:]
+func TestCheckSliceExprArrayPtrLow(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[1:]`, env, p[1:])
+}
+
+// Test ArrayPtr[:2]
+func TestCheckSliceExprArrayPtrHigh(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[:2]`, env, p[:2])
+}
+
+// Test ArrayPtr[1:2]
+func TestCheckSliceExprArrayPtrLowHigh(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[1:2]`, env, p[1:2])
+}
+
+// Test ArrayPtr[1:2:3]
+func TestCheckSliceExprArrayPtrLowHighMax(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[1:2:3]`, env, p[1:2:3])
+}
+
+// Test ArrayPtr[:2:3]
+func TestCheckSliceExprArrayPtrHighMax(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, `p[:2:3]`, env, p[:2:3])
+}
+
+// Test ArrayPtr[2:1]
+func TestCheckSliceExprArrayPtrInverted(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[2:1]`, env,
+		`invalid slice index: 2 > 1`,
+	)
+}
+
+// Test ArrayPtr[-1:]
+func TestCheckSliceExprArrayPtrNegative(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[-1:]`, env,
+		`invalid slice index -1 (index must be non-negative)`,
+	)
+}
+
+// Test ArrayPtr[:4]
+func TestCheckSliceExprArrayPtrOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[:4]`, env,
+		`invalid slice index 4 (out of bounds for 3-element array)`,
+	)
+}
+
+// Test ArrayPtr[1.5:]
+func TestCheckSliceExprArrayPtrFloat(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectCompileError(t, `p[1.5:]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test ArrayPtr[i:]
+func TestCheckSliceExprArrayPtrIntVar(t *testing.T) {
+	env := makeEnv()
+	var p *[3]int = &[3]int{1, 2, 3}
+	env.Vars["p"] = reflect.ValueOf(&p)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `p[i:]`, env, p[i:])
+}
+
+// Test ArrayLit[:]
+func TestCheckSliceExprArrayLitAll(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[:]`, env,
+		`invalid operation [3]int{1, 2, 3}[:] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[1:]
+func TestCheckSliceExprArrayLitLow(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[1:]`, env,
+		`invalid operation [3]int{1, 2, 3}[1:] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[:2]
+func TestCheckSliceExprArrayLitHigh(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[:2]`, env,
+		`invalid operation [3]int{1, 2, 3}[:2] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[1:2]
+func TestCheckSliceExprArrayLitLowHigh(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[1:2]`, env,
+		`invalid operation [3]int{1, 2, 3}[1:2] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[1:2:3]
+func TestCheckSliceExprArrayLitLowHighMax(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[1:2:3]`, env,
+		`invalid operation [3]int{1, 2, 3}[1:2:3] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[:2:3]
+func TestCheckSliceExprArrayLitHighMax(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[:2:3]`, env,
+		`invalid operation [3]int{1, 2, 3}[:2:3] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[2:1]
+func TestCheckSliceExprArrayLitInverted(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[2:1]`, env,
+		`invalid operation [3]int{1, 2, 3}[2:1] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[-1:]
+func TestCheckSliceExprArrayLitNegative(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[-1:]`, env,
+		`invalid operation [3]int{1, 2, 3}[-1:] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[:4]
+func TestCheckSliceExprArrayLitOutOfRange(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[:4]`, env,
+		`invalid operation [3]int{1, 2, 3}[:4] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[1.5:]
+func TestCheckSliceExprArrayLitFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `[3]int{1, 2, 3}[1.5:]`, env,
+		`invalid operation [3]int{1, 2, 3}[1.5:] (slice of unaddressable value)`,
+	)
+}
+
+// Test ArrayLit[i:]
+func TestCheckSliceExprArrayLitIntVar(t *testing.T) {
+	env := makeEnv()
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectCompileError(t, `[3]int{1, 2, 3}[i:]`, env,
+		`invalid operation [3]int{1, 2, 3}[i:] (slice of unaddressable value)`,
+	)
+}
+
+// Test String[:]
+func TestCheckSliceExprStringAll(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[:]`, env, str[:])
+}
+
+// Test String[1:]
+func TestCheckSliceExprStringLow(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[1:]`, env, str[1:])
+}
+
+// Test String[:2]
+func TestCheckSliceExprStringHigh(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[:2]`, env, str[:2])
+}
+
+// Test String[1:2]
+func TestCheckSliceExprStringLowHigh(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[1:2]`, env, str[1:2])
+}
+
+// Test String[1:2:3]
+func TestCheckSliceExprStringLowHighMax(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[1:2:3]`, env,
+		`invalid operation str[1:2:3] (3-index slice of string)`,
+	)
+}
+
+// Test String[:2:3]
+func TestCheckSliceExprStringHighMax(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[:2:3]`, env,
+		`invalid operation str[:2:3] (3-index slice of string)`,
+	)
+}
+
+// Test String[2:1]
+func TestCheckSliceExprStringInverted(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[2:1]`, env,
+		`invalid slice index: 2 > 1`,
+	)
+}
+
+// Test String[-1:]
+func TestCheckSliceExprStringNegative(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[-1:]`, env,
+		`invalid slice index -1 (index must be non-negative)`,
+	)
+}
+
+// Test String[:4]
+func TestCheckSliceExprStringOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectResult(t, `str[:4]`, env, str[:4])
+}
+
+// Test String[1.5:]
+func TestCheckSliceExprStringFloat(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+
+	expectCompileError(t, `str[1.5:]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test String[i:]
+func TestCheckSliceExprStringIntVar(t *testing.T) {
+	env := makeEnv()
+	var str string = "abcd"
+	env.Vars["str"] = reflect.ValueOf(&str)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `str[i:]`, env, str[i:])
+}
+
+// Test ConstString[:]
+func TestCheckSliceExprConstStringAll(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `"abc"[:]`, env, "abc"[:])
+}
+
+// Test ConstString[1:]
+func TestCheckSliceExprConstStringLow(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `"abc"[1:]`, env, "abc"[1:])
+}
+
+// Test ConstString[:2]
+func TestCheckSliceExprConstStringHigh(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `"abc"[:2]`, env, "abc"[:2])
+}
+
+// Test ConstString[1:2]
+func TestCheckSliceExprConstStringLowHigh(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, `"abc"[1:2]`, env, "abc"[1:2])
+}
+
+// Test ConstString[1:2:3]
+func TestCheckSliceExprConstStringLowHighMax(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[1:2:3]`, env,
+		`invalid operation "abc"[1:2:3] (3-index slice of string)`,
+	)
+}
+
+// Test ConstString[:2:3]
+func TestCheckSliceExprConstStringHighMax(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[:2:3]`, env,
+		`invalid operation "abc"[:2:3] (3-index slice of string)`,
+	)
+}
+
+// Test ConstString[2:1]
+func TestCheckSliceExprConstStringInverted(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[2:1]`, env,
+		`invalid slice index: 2 > 1`,
+	)
+}
+
+// Test ConstString[-1:]
+func TestCheckSliceExprConstStringNegative(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[-1:]`, env,
+		`invalid slice index -1 (index must be non-negative)`,
+	)
+}
+
+// Test ConstString[:4]
+func TestCheckSliceExprConstStringOutOfRange(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[:4]`, env,
+		`invalid slice index 4 (out of bounds for 3-byte string)`,
+	)
+}
+
+// Test ConstString[1.5:]
+func TestCheckSliceExprConstStringFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `"abc"[1.5:]`, env,
+		`constant 1.5 truncated to integer`,
+	)
+}
+
+// Test ConstString[i:]
+func TestCheckSliceExprConstStringIntVar(t *testing.T) {
+	env := makeEnv()
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectResult(t, `"abc"[i:]`, env, "abc"[i:])
+}
+
+// Test Int[:]
+func TestCheckSliceExprIntAll(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[:]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[1:]
+func TestCheckSliceExprIntLow(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1:]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[:2]
+func TestCheckSliceExprIntHigh(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[:2]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[1:2]
+func TestCheckSliceExprIntLowHigh(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1:2]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[1:2:3]
+func TestCheckSliceExprIntLowHighMax(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1:2:3]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[:2:3]
+func TestCheckSliceExprIntHighMax(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[:2:3]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[2:1]
+func TestCheckSliceExprIntInverted(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[2:1]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[-1:]
+func TestCheckSliceExprIntNegative(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[-1:]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[:4]
+func TestCheckSliceExprIntOutOfRange(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[:4]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[1.5:]
+func TestCheckSliceExprIntFloat(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	expectCompileError(t, `n[1.5:]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Int[i:]
+func TestCheckSliceExprIntIntVar(t *testing.T) {
+	env := makeEnv()
+	var n int = 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectCompileError(t, `n[i:]`, env,
+		`cannot slice n (type int)`,
+	)
+}
+
+// Test Nil[:]
+func TestCheckSliceExprNilAll(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[:]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[1:]
+func TestCheckSliceExprNilLow(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1:]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[:2]
+func TestCheckSliceExprNilHigh(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[:2]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[1:2]
+func TestCheckSliceExprNilLowHigh(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1:2]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[1:2:3]
+func TestCheckSliceExprNilLowHighMax(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1:2:3]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[:2:3]
+func TestCheckSliceExprNilHighMax(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[:2:3]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[2:1]
+func TestCheckSliceExprNilInverted(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[2:1]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[-1:]
+func TestCheckSliceExprNilNegative(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[-1:]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[:4]
+func TestCheckSliceExprNilOutOfRange(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[:4]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[1.5:]
+func TestCheckSliceExprNilFloat(t *testing.T) {
+	env := makeEnv()
+
+	expectCompileError(t, `nil[1.5:]`, env,
+		`use of untyped nil`,
+	)
+}
+
+// Test Nil[i:]
+func TestCheckSliceExprNilIntVar(t *testing.T) {
+	env := makeEnv()
+	var i int = 1
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	expectCompileError(t, `nil[i:]`, env,
+		`use of untyped nil`,
+	)
+}
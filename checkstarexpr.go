@@ -0,0 +1,18 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+func checkStarExpr(ctx *Ctx, star *ast.StarExpr, env *Env) (aexpr *StarExpr, errs []error) {
+	aexpr = &StarExpr{StarExpr: star}
+	if aexpr.X, errs = CheckExpr(ctx, star.X, env); errs != nil {
+		return aexpr, errs
+	}
+	if t := staticType(aexpr.X.(Expr), env); t != nil && t.Kind() == reflect.Ptr {
+		aexpr.knownType = knownType{t.Elem()}
+	}
+	return aexpr, nil
+}
@@ -0,0 +1,757 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go/ast"
+	"go/token"
+)
+
+// checkScope is the checker's view of a block. Each block checks its
+// statements against a copy of the enclosing block's Env, with declared
+// variables present in Vars so that they shadow constants. The Values of
+// these variables are invalid, only their names are known.
+type checkScope struct {
+	*Env
+
+	// Names declared in this block, so that := can tell a redeclaration
+	// from a new variable
+	declared map[string]bool
+
+	// The enclosing function, nil at the top level
+	fn *funcScope
+
+	// Valid targets of unlabeled break and continue statements
+	canBreak, canContinue bool
+
+	// Labels of enclosing statements, mapped to whether they label a loop
+	labels map[string]bool
+
+	// The label of the statement being checked, if any
+	label string
+
+	// Set while checking the last statement of a case clause that may
+	// fall through
+	canFallthrough bool
+}
+
+// funcScope describes the function enclosing a statement
+type funcScope struct {
+	results []reflect.Type
+	named   bool
+}
+
+// CheckStmt type checks a statement, as parsed by go/parser. As with
+// CheckDecl, env is not modified. Names declared by a top level
+// statement become visible once it is passed to EvalStmt.
+func CheckStmt(ctx *Ctx, stmt ast.Stmt, env *Env) (Stmt, []error) {
+	return checkStmt(ctx, stmt, topLevelScope(env))
+}
+
+func topLevelScope(env *Env) *checkScope {
+	scope := &checkScope{Env: newScope(env), declared: map[string]bool{}}
+	for name := range env.Vars {
+		scope.declared[name] = true
+	}
+	return scope
+}
+
+// block returns the scope of a block nested in scope
+func (scope *checkScope) block() *checkScope {
+	inner := *scope
+	inner.Env = newScope(scope.Env)
+	inner.declared = map[string]bool{}
+	inner.label = ""
+	inner.canFallthrough = false
+	return &inner
+}
+
+// declareVar makes name a variable for the remainder of the block
+func (scope *checkScope) declareVar(name string) {
+	if name == "_" {
+		return
+	}
+	scope.declared[name] = true
+	scope.Vars[name] = reflect.Value{}
+	delete(scope.Consts, name)
+	delete(scope.Types, name)
+}
+
+// declare makes the names of a checked declaration visible for the
+// remainder of the block
+func (scope *checkScope) declare(decl Decl) {
+	switch decl := decl.(type) {
+	case *VarDecl:
+		for _, spec := range decl.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				scope.declareVar(name.Name)
+			}
+		}
+	case *ConstDecl:
+		for i, name := range decl.names {
+			if name != "_" {
+				scope.declared[name] = true
+				delete(scope.Vars, name)
+				scope.Consts[name] = decl.values[i]
+			}
+		}
+	case *TypeDecl:
+		for i, name := range decl.names {
+			if name != "_" {
+				scope.declared[name] = true
+				scope.Types[name] = decl.types[i]
+			}
+		}
+	}
+}
+
+func checkStmt(ctx *Ctx, stmt ast.Stmt, scope *checkScope) (Stmt, []error) {
+	switch stmt := stmt.(type) {
+	case *ast.BadStmt:
+		return &BadStmt{BadStmt: stmt}, []error{errors.New("bad statement")}
+	case *ast.DeclStmt:
+		return checkDeclStmt(ctx, stmt, scope)
+	case *ast.EmptyStmt:
+		return &EmptyStmt{EmptyStmt: stmt}, nil
+	case *ast.LabeledStmt:
+		return checkLabeledStmt(ctx, stmt, scope)
+	case *ast.ExprStmt:
+		return checkExprStmt(ctx, stmt, scope)
+	case *ast.IncDecStmt:
+		return checkIncDecStmt(ctx, stmt, scope)
+	case *ast.AssignStmt:
+		return checkAssignStmt(ctx, stmt, scope)
+	case *ast.ReturnStmt:
+		return checkReturnStmt(ctx, stmt, scope)
+	case *ast.BranchStmt:
+		return checkBranchStmt(ctx, stmt, scope)
+	case *ast.BlockStmt:
+		errs := checkBlock(ctx, stmt, scope.block())
+		return &BlockStmt{BlockStmt: stmt}, errs
+	case *ast.IfStmt:
+		return checkIfStmt(ctx, stmt, scope)
+	case *ast.SwitchStmt:
+		return checkSwitchStmt(ctx, stmt, scope)
+	case *ast.ForStmt:
+		return checkForStmt(ctx, stmt, scope)
+	case *ast.RangeStmt:
+		return checkRangeStmt(ctx, stmt, scope)
+	case *ast.GoStmt:
+		astmt := &GoStmt{GoStmt: stmt}
+		var errs []error
+		astmt.call, errs = checkDeferredCall(ctx, stmt, stmt.Call, "go", scope)
+		return astmt, errs
+	case *ast.DeferStmt:
+		astmt := &DeferStmt{DeferStmt: stmt}
+		var errs []error
+		astmt.call, errs = checkDeferredCall(ctx, stmt, stmt.Call, "defer", scope)
+		return astmt, errs
+	case *ast.SendStmt:
+		return checkSendStmt(ctx, stmt, scope)
+	case *ast.SelectStmt:
+		return checkSelectStmt(ctx, stmt, scope)
+	case *ast.TypeSwitchStmt:
+		return checkTypeSwitchStmt(ctx, stmt, scope)
+	default:
+		return nil, []error{errors.New(fmt.Sprintf("Stmt: Bad stmt (%+v)", stmt))}
+	}
+}
+
+// checkBlock checks the statements of a block in place
+func checkBlock(ctx *Ctx, block *ast.BlockStmt, scope *checkScope) []error {
+	return checkStmtList(ctx, block.List, scope)
+}
+
+func checkStmtList(ctx *Ctx, list []ast.Stmt, scope *checkScope) (errs []error) {
+	canFallthrough := scope.canFallthrough
+	for i := range list {
+		scope.canFallthrough = canFallthrough && i == len(list)-1
+		var moreErrs []error
+		if list[i], moreErrs = checkStmt(ctx, list[i], scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	scope.canFallthrough = false
+	return errs
+}
+
+func checkDeclStmt(ctx *Ctx, stmt *ast.DeclStmt, scope *checkScope) (*DeclStmt, []error) {
+	astmt := &DeclStmt{DeclStmt: stmt}
+	decl, errs := CheckDecl(ctx, stmt.Decl, scope.Env)
+	if errs == nil {
+		astmt.decl = decl
+		scope.declare(decl)
+	}
+	return astmt, errs
+}
+
+func checkLabeledStmt(ctx *Ctx, stmt *ast.LabeledStmt, scope *checkScope) (*LabeledStmt, []error) {
+	astmt := &LabeledStmt{LabeledStmt: stmt}
+
+	labeled := *scope
+	labeled.labels = make(map[string]bool, len(scope.labels)+1)
+	for label, isLoop := range scope.labels {
+		labeled.labels[label] = isLoop
+	}
+	switch stmt.Stmt.(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+		labeled.labels[stmt.Label.Name] = true
+	default:
+		labeled.labels[stmt.Label.Name] = false
+	}
+	labeled.label = stmt.Label.Name
+
+	var errs []error
+	stmt.Stmt, errs = checkStmt(ctx, stmt.Stmt, &labeled)
+	return astmt, errs
+}
+
+func checkExprStmt(ctx *Ctx, stmt *ast.ExprStmt, scope *checkScope) (*ExprStmt, []error) {
+	astmt := &ExprStmt{ExprStmt: stmt}
+	x, errs := CheckExpr(ctx, stmt.X, scope.Env)
+	if errs != nil {
+		return astmt, errs
+	}
+	stmt.X = x
+
+	// Only calls and receives may be used as statements
+	switch x := skipSuperfluousParens(x).(type) {
+	case *CallExpr:
+		if !x.isTypeConversion {
+			return astmt, nil
+		}
+	case *UnaryExpr:
+		if x.Op == token.ARROW {
+			return astmt, nil
+		}
+	}
+	return astmt, []error{ErrUnusedExpr{at(ctx, stmt.X)}}
+}
+
+// checkDeferredCall checks the call of a go or defer statement
+func checkDeferredCall(ctx *Ctx, stmt ast.Stmt, call *ast.CallExpr, keyword string, scope *checkScope) (*CallExpr, []error) {
+	x, errs := CheckExpr(ctx, call, scope.Env)
+	if errs != nil {
+		return nil, errs
+	}
+	acall := x.(*CallExpr)
+	if acall.isTypeConversion {
+		return acall, []error{ErrBadDeferCall{at(ctx, stmt), keyword}}
+	}
+	return acall, nil
+}
+
+func checkIncDecStmt(ctx *Ctx, stmt *ast.IncDecStmt, scope *checkScope) (*IncDecStmt, []error) {
+	astmt := &IncDecStmt{IncDecStmt: stmt}
+	x, errs := checkAssignable(ctx, stmt.X, scope)
+	if errs == nil {
+		stmt.X = x
+	}
+	return astmt, errs
+}
+
+// checkAssignable checks the left hand side of an assignment
+func checkAssignable(ctx *Ctx, lhs ast.Expr, scope *checkScope) (Expr, []error) {
+	if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "_" {
+		return &Ident{Ident: ident}, nil
+	}
+	x, errs := CheckExpr(ctx, lhs, scope.Env)
+	if errs != nil {
+		return x, errs
+	} else if x.IsConst() {
+		return x, []error{ErrCannotAssign{at(ctx, lhs)}}
+	} else if name, ok := readOnlyPath(ctx, x, scope.Env); ok {
+		return x, []error{ErrDenied{at(ctx, lhs), "assignment to " + name}}
+	} else if err := checkReadOnlyAssign(ctx, x, scope.Env); err != nil {
+		return x, []error{err}
+	}
+	return x, nil
+}
+
+func checkAssignStmt(ctx *Ctx, stmt *ast.AssignStmt, scope *checkScope) (*AssignStmt, []error) {
+	astmt := &AssignStmt{AssignStmt: stmt}
+
+	var errs, moreErrs []error
+	if stmt.Tok != token.DEFINE && stmt.Tok != token.ASSIGN {
+		// op=
+		if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+			return astmt, []error{ErrAssignCount{at(ctx, stmt), len(stmt.Lhs), len(stmt.Rhs)}}
+		}
+	} else if err := checkAssignCount(ctx, stmt, len(stmt.Lhs), stmt.Rhs); err != nil {
+		errs = append(errs, err)
+	}
+
+	// The right hand side is checked before any new variables are declared
+	for i := range stmt.Rhs {
+		if stmt.Rhs[i], moreErrs = CheckExpr(ctx, stmt.Rhs[i], scope.Env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	if stmt.Tok == token.DEFINE {
+		astmt.newVars = make([]bool, len(stmt.Lhs))
+		anyNew := false
+		for i, lhs := range stmt.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				errs = append(errs, ErrNonName{at(ctx, lhs)})
+				continue
+			}
+			astmt.newVars[i] = ident.Name != "_" && !scope.declared[ident.Name]
+			anyNew = anyNew || astmt.newVars[i]
+		}
+		if !anyNew && errs == nil {
+			errs = append(errs, ErrNoNewVariables{at(ctx, stmt)})
+		}
+		for i, lhs := range stmt.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				if astmt.newVars[i] {
+					scope.declareVar(ident.Name)
+				}
+				stmt.Lhs[i] = &Ident{Ident: ident}
+			}
+		}
+		return astmt, errs
+	}
+
+	for i := range stmt.Lhs {
+		if stmt.Lhs[i], moreErrs = checkAssignable(ctx, stmt.Lhs[i], scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	return astmt, errs
+}
+
+// checkAssignCount checks that n variables are assigned as many values.
+// A single call on the right may return multiple values, which is only
+// known at run time.
+func checkAssignCount(ctx *Ctx, node ast.Node, n int, rhs []ast.Expr) error {
+	if n == len(rhs) {
+		return nil
+	} else if len(rhs) == 1 {
+		if _, ok := rhs[0].(*ast.CallExpr); ok {
+			return nil
+		} else if n == 2 && (isRecv(rhs[0]) || isTypeAssert(rhs[0]) || isIndex(rhs[0])) {
+			// v, ok := <-ch, v, ok := x.(T) or v, ok := m[k]
+			return nil
+		}
+	}
+	return ErrAssignCount{at(ctx, node), n, len(rhs)}
+}
+
+func checkReturnStmt(ctx *Ctx, stmt *ast.ReturnStmt, scope *checkScope) (*ReturnStmt, []error) {
+	astmt := &ReturnStmt{ReturnStmt: stmt}
+	if scope.fn == nil {
+		return astmt, []error{ErrReturnOutsideFunction{at(ctx, stmt)}}
+	}
+
+	var errs, moreErrs []error
+	for i := range stmt.Results {
+		if stmt.Results[i], moreErrs = CheckExpr(ctx, stmt.Results[i], scope.Env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	n, want := len(stmt.Results), len(scope.fn.results)
+	if n == want || n == 0 && scope.fn.named {
+		return astmt, errs
+	} else if n == 1 && want > 1 {
+		if _, ok := stmt.Results[0].(*CallExpr); ok {
+			return astmt, errs
+		}
+	}
+	return astmt, append(errs, ErrWrongNumberOfReturns{at(ctx, stmt), n > want})
+}
+
+func checkBranchStmt(ctx *Ctx, stmt *ast.BranchStmt, scope *checkScope) (*BranchStmt, []error) {
+	astmt := &BranchStmt{BranchStmt: stmt}
+	switch stmt.Tok {
+	case token.BREAK, token.CONTINUE:
+		if stmt.Label != nil {
+			if isLoop, ok := scope.labels[stmt.Label.Name]; !ok {
+				return astmt, []error{ErrUndefinedLabel{at(ctx, stmt)}}
+			} else if stmt.Tok == token.CONTINUE && !isLoop {
+				return astmt, []error{ErrMisplacedBranch{at(ctx, stmt)}}
+			}
+		} else if stmt.Tok == token.BREAK && !scope.canBreak || stmt.Tok == token.CONTINUE && !scope.canContinue {
+			return astmt, []error{ErrMisplacedBranch{at(ctx, stmt)}}
+		}
+		return astmt, nil
+	case token.FALLTHROUGH:
+		if !scope.canFallthrough {
+			return astmt, []error{ErrMisplacedBranch{at(ctx, stmt)}}
+		}
+		return astmt, nil
+	default:
+		return astmt, []error{errors.New(stmt.Tok.String() + " statements not implemented")}
+	}
+}
+
+// checkCondition checks the condition of an if or for statement
+func checkCondition(ctx *Ctx, cond ast.Expr, context string, scope *checkScope) (Expr, []error) {
+	x, errs := CheckExpr(ctx, cond, scope.Env)
+	if errs != nil {
+		return x, errs
+	}
+	if t := x.KnownType(); len(t) == 1 && t[0] != ConstBool && t[0].Kind() != reflect.Bool {
+		return x, []error{ErrNonBoolCondition{at(ctx, cond), context}}
+	}
+	return x, nil
+}
+
+func checkIfStmt(ctx *Ctx, stmt *ast.IfStmt, scope *checkScope) (*IfStmt, []error) {
+	astmt := &IfStmt{IfStmt: stmt}
+	scope = scope.block()
+
+	var errs, moreErrs []error
+	if stmt.Init != nil {
+		if stmt.Init, moreErrs = checkStmt(ctx, stmt.Init, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	if stmt.Cond, moreErrs = checkCondition(ctx, stmt.Cond, "if", scope); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	if moreErrs = checkBlock(ctx, stmt.Body, scope.block()); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	if stmt.Else != nil {
+		if stmt.Else, moreErrs = checkStmt(ctx, stmt.Else, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	return astmt, errs
+}
+
+func checkSwitchStmt(ctx *Ctx, stmt *ast.SwitchStmt, scope *checkScope) (*SwitchStmt, []error) {
+	astmt := &SwitchStmt{SwitchStmt: stmt}
+	scope = scope.block()
+	scope.canBreak = true
+
+	var errs, moreErrs []error
+	if stmt.Init != nil {
+		if stmt.Init, moreErrs = checkStmt(ctx, stmt.Init, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	if stmt.Tag != nil {
+		if stmt.Tag, moreErrs = CheckExpr(ctx, stmt.Tag, scope.Env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	var hasDefault bool
+	for i, clause := range stmt.Body.List {
+		clause := clause.(*ast.CaseClause)
+		if clause.List == nil {
+			if hasDefault {
+				errs = append(errs, errors.New("multiple defaults in switch"))
+			}
+			hasDefault = true
+		}
+		for j := range clause.List {
+			if stmt.Tag == nil {
+				clause.List[j], moreErrs = checkCondition(ctx, clause.List[j], "case", scope)
+			} else {
+				clause.List[j], moreErrs = CheckExpr(ctx, clause.List[j], scope.Env)
+			}
+			if moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			}
+		}
+		body := scope.block()
+		body.canFallthrough = i < len(stmt.Body.List)-1
+		if moreErrs = checkStmtList(ctx, clause.Body, body); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		stmt.Body.List[i] = &CaseClause{CaseClause: clause}
+	}
+	return astmt, errs
+}
+
+func checkTypeSwitchStmt(ctx *Ctx, stmt *ast.TypeSwitchStmt, scope *checkScope) (*TypeSwitchStmt, []error) {
+	astmt := &TypeSwitchStmt{TypeSwitchStmt: stmt}
+	scope = scope.block()
+	scope.canBreak = true
+
+	var errs, moreErrs []error
+	if stmt.Init != nil {
+		if stmt.Init, moreErrs = checkStmt(ctx, stmt.Init, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	// The guard is x.(type) or v := x.(type)
+	var guard ast.Expr
+	switch assign := stmt.Assign.(type) {
+	case *ast.ExprStmt:
+		guard = assign.X
+	case *ast.AssignStmt:
+		if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+			astmt.name = ident.Name
+		}
+		guard = assign.Rhs[0]
+	}
+	assert := guard.(*ast.TypeAssertExpr)
+	var xt reflect.Type
+	if assert.X, moreErrs = CheckExpr(ctx, assert.X, scope.Env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	} else if x := assert.X.(Expr); x.IsConst() {
+		if isConstNil(x) {
+			errs = append(errs, ErrUntypedNil{at(ctx, x)})
+		} else {
+			errs = append(errs, ErrInvalidTypeSwitch{at(ctx, x), x.KnownType()[0]})
+		}
+	} else if xt = staticType(x, scope.Env); xt != nil && xt.Kind() != reflect.Interface {
+		errs = append(errs, ErrInvalidTypeSwitch{at(ctx, x), xt})
+	} else {
+		astmt.x = x
+	}
+
+	var hasDefault bool
+	for i, clause := range stmt.Body.List {
+		clause := clause.(*ast.CaseClause)
+		aclause := &TypeCaseClause{CaseClause: clause}
+		if clause.List == nil {
+			if hasDefault {
+				errs = append(errs, errors.New("multiple defaults in switch"))
+			}
+			hasDefault = true
+		}
+		for j := range clause.List {
+			if ident, ok := clause.List[j].(*ast.Ident); ok && ident.Name == "nil" {
+				if _, isType := scope.Types["nil"]; !isType {
+					clause.List[j] = &Ident{Ident: ident}
+					aclause.types = append(aclause.types, nil)
+					continue
+				}
+			}
+			t, moreErrs := checkTypeSwitchCase(ctx, &clause.List[j], xt, scope)
+			if moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			}
+			aclause.types = append(aclause.types, t)
+		}
+
+		body := scope.block()
+		if astmt.name != "" {
+			body.declareVar(astmt.name)
+		}
+		if moreErrs = checkStmtList(ctx, clause.Body, body); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		stmt.Body.List[i] = aclause
+	}
+	return astmt, errs
+}
+
+// checkTypeSwitchCase checks a type of a type switch case, which must be
+// a type the interface xt, if it is known, can hold
+func checkTypeSwitchCase(ctx *Ctx, expr *ast.Expr, xt reflect.Type, scope *checkScope) (reflect.Type, []error) {
+	texpr, errs := checkTypeExpr(ctx, *expr, scope.Env)
+	if errs != nil {
+		return nil, errs
+	}
+	*expr = texpr
+	t, err := evalType(ctx, texpr, scope.Env)
+	if err != nil {
+		return nil, []error{err}
+	}
+	t = unhackType(t)
+	if xt != nil && t.Kind() != reflect.Interface {
+		if missing := missingMethod(t, xt); missing != "" {
+			return t, []error{ErrImpossibleTypeAssert{at(ctx, texpr), t, xt, missing}}
+		}
+	}
+	return t, nil
+}
+
+func checkForStmt(ctx *Ctx, stmt *ast.ForStmt, scope *checkScope) (*ForStmt, []error) {
+	astmt := &ForStmt{ForStmt: stmt}
+	scope = scope.block()
+
+	var errs, moreErrs []error
+	if stmt.Init != nil {
+		if stmt.Init, moreErrs = checkStmt(ctx, stmt.Init, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	if stmt.Cond != nil {
+		if stmt.Cond, moreErrs = checkCondition(ctx, stmt.Cond, "for", scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	if stmt.Post != nil {
+		if assign, ok := stmt.Post.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+			errs = append(errs, errors.New("cannot declare in post statement of for loop"))
+		} else if stmt.Post, moreErrs = checkStmt(ctx, stmt.Post, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	body := scope.block()
+	body.canBreak, body.canContinue = true, true
+	if moreErrs = checkBlock(ctx, stmt.Body, body); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	return astmt, errs
+}
+
+func checkRangeStmt(ctx *Ctx, stmt *ast.RangeStmt, scope *checkScope) (*RangeStmt, []error) {
+	astmt := &RangeStmt{RangeStmt: stmt}
+	scope = scope.block()
+
+	var errs, moreErrs []error
+	if stmt.X, moreErrs = CheckExpr(ctx, stmt.X, scope.Env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+
+	for _, x := range []*ast.Expr{&stmt.Key, &stmt.Value} {
+		if *x == nil {
+			continue
+		}
+		if stmt.Tok == token.DEFINE {
+			if ident, ok := (*x).(*ast.Ident); !ok {
+				errs = append(errs, ErrNonName{at(ctx, *x)})
+			} else {
+				scope.declareVar(ident.Name)
+				*x = &Ident{Ident: ident}
+			}
+		} else if *x, moreErrs = checkAssignable(ctx, *x, scope); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	body := scope.block()
+	body.canBreak, body.canContinue = true, true
+	if moreErrs = checkBlock(ctx, stmt.Body, body); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	return astmt, errs
+}
+
+// isTerminating reports whether a checked statement is a terminating
+// statement, as defined by the go spec. A function with results must end
+// in one.
+func isTerminating(stmt ast.Stmt, label string) bool {
+	switch stmt := stmt.(type) {
+	case *ReturnStmt:
+		return true
+	case *BranchStmt:
+		return stmt.Tok == token.GOTO
+	case *ExprStmt:
+		if call, ok := stmt.X.(*CallExpr); ok {
+			if ident, ok := call.Fun.(*Ident); ok && ident.Name == "panic" {
+				return true
+			}
+		}
+		return false
+	case *BlockStmt:
+		return isTerminatingList(stmt.List)
+	case *LabeledStmt:
+		return isTerminating(stmt.Stmt, stmt.Label.Name)
+	case *IfStmt:
+		return stmt.Else != nil && isTerminatingList(stmt.Body.List) && isTerminating(stmt.Else, "")
+	case *ForStmt:
+		return stmt.Cond == nil && !hasBreakList(stmt.Body.List, label, true)
+	case *SelectStmt:
+		for _, clause := range stmt.Body.List {
+			clause := clause.(*CommClause)
+			if hasBreakList(clause.Body, label, true) || !isTerminatingList(clause.Body) {
+				return false
+			}
+		}
+		return true
+	case *TypeSwitchStmt:
+		hasDefault := false
+		for _, clause := range stmt.Body.List {
+			clause := clause.(*TypeCaseClause)
+			hasDefault = hasDefault || clause.List == nil
+			if hasBreakList(clause.Body, label, true) || !isTerminatingList(clause.Body) {
+				return false
+			}
+		}
+		return hasDefault
+	case *SwitchStmt:
+		hasDefault := false
+		for _, clause := range stmt.Body.List {
+			clause := clause.(*CaseClause)
+			hasDefault = hasDefault || clause.List == nil
+			n := len(clause.Body)
+			if n == 0 || hasBreakList(clause.Body, label, true) {
+				return false
+			} else if branch, ok := clause.Body[n-1].(*BranchStmt); ok && branch.Tok == token.FALLTHROUGH {
+				continue
+			} else if !isTerminating(clause.Body[n-1], "") {
+				return false
+			}
+		}
+		return hasDefault
+	default:
+		return false
+	}
+}
+
+func isTerminatingList(list []ast.Stmt) bool {
+	return len(list) > 0 && isTerminating(list[len(list)-1], "")
+}
+
+// hasBreak reports whether a checked statement contains a break
+// targeting the statement labeled label. implicit is true if an
+// unlabeled break would also target it.
+func hasBreak(stmt ast.Stmt, label string, implicit bool) bool {
+	switch stmt := stmt.(type) {
+	case *BranchStmt:
+		if stmt.Tok != token.BREAK {
+			return false
+		} else if stmt.Label == nil {
+			return implicit
+		}
+		return stmt.Label.Name == label
+	case *BlockStmt:
+		return hasBreakList(stmt.List, label, implicit)
+	case *LabeledStmt:
+		return hasBreak(stmt.Stmt, label, implicit)
+	case *IfStmt:
+		return hasBreakList(stmt.Body.List, label, implicit) ||
+			stmt.Else != nil && hasBreak(stmt.Else, label, implicit)
+	case *ForStmt:
+		return hasBreakList(stmt.Body.List, label, false)
+	case *RangeStmt:
+		return hasBreakList(stmt.Body.List, label, false)
+	case *SwitchStmt:
+		for _, clause := range stmt.Body.List {
+			if hasBreakList(clause.(*CaseClause).Body, label, false) {
+				return true
+			}
+		}
+		return false
+	case *TypeSwitchStmt:
+		for _, clause := range stmt.Body.List {
+			if hasBreakList(clause.(*TypeCaseClause).Body, label, false) {
+				return true
+			}
+		}
+		return false
+	case *SelectStmt:
+		for _, clause := range stmt.Body.List {
+			if hasBreakList(clause.(*CommClause).Body, label, false) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func hasBreakList(list []ast.Stmt, label string, implicit bool) bool {
+	for _, stmt := range list {
+		if hasBreak(stmt, label, implicit) {
+			return true
+		}
+	}
+	return false
+}
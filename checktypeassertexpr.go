@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+func checkTypeAssertExpr(ctx *Ctx, assert *ast.TypeAssertExpr, env *Env) (aexpr *TypeAssertExpr, errs []error) {
+	aexpr = &TypeAssertExpr{TypeAssertExpr: assert}
+
+	var moreErrs []error
+	if aexpr.X, moreErrs = CheckExpr(ctx, assert.X, env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	if assert.Type == nil {
+		return aexpr, append(errs, ErrOutsideTypeSwitch{at(ctx, assert)})
+	} else if aexpr.Type, moreErrs = checkTypeExpr(ctx, assert.Type, env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	if errs != nil {
+		return aexpr, errs
+	}
+
+	t, err := evalType(ctx, aexpr.Type.(Expr), env)
+	if err != nil {
+		return aexpr, []error{err}
+	}
+	t = unhackType(t)
+	aexpr.knownType = knownType{t}
+
+	x := aexpr.X.(Expr)
+	if x.IsConst() {
+		if _, ok := x.KnownType()[0].(ConstNilType); ok {
+			return aexpr, []error{ErrUntypedNil{at(ctx, x)}}
+		}
+		return aexpr, []error{ErrInvalidTypeAssert{at(ctx, x), x.KnownType()[0]}}
+	} else if xt := staticType(x, env); xt == nil {
+		return aexpr, nil
+	} else if xt.Kind() != reflect.Interface {
+		return aexpr, []error{ErrInvalidTypeAssert{at(ctx, x), xt}}
+	} else if t.Kind() != reflect.Interface {
+		if missing := missingMethod(t, xt); missing != "" {
+			return aexpr, []error{ErrImpossibleTypeAssert{at(ctx, assert), t, xt, missing}}
+		}
+	}
+	return aexpr, nil
+}
+
+// missingMethod returns the name of a method of the interface iface which
+// t does not have, or "" if t implements iface
+func missingMethod(t, iface reflect.Type) string {
+	for i := 0; i < iface.NumMethod(); i += 1 {
+		name := iface.Method(i).Name
+		if _, ok := t.MethodByName(name); !ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// isTypeAssert reports whether x is a type assertion, either before or
+// after it is checked
+func isTypeAssert(x ast.Expr) bool {
+	for {
+		switch paren := x.(type) {
+		case *ast.ParenExpr:
+			x = paren.X
+			continue
+		case *ParenExpr:
+			x = paren.X
+			continue
+		}
+		break
+	}
+	switch x.(type) {
+	case *ast.TypeAssertExpr, *TypeAssertExpr:
+		return true
+	}
+	return false
+}
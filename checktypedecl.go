@@ -0,0 +1,79 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+// checkTypeDecl checks a type declaration, constructing each type with
+// reflect. Since reflect cannot create named types, a defined type
+// type T U is represented by the underlying type of U, and is therefore
+// identical to, rather than merely convertible to, that type. An alias
+// type T = U is represented by U itself. Each defined type is reported
+// to ctx.Warn with a WarnDefinedType.
+func checkTypeDecl(ctx *Ctx, decl *ast.GenDecl, env *Env) (tdecl *TypeDecl, errs []error) {
+	tdecl = &TypeDecl{GenDecl: decl}
+
+	// Types declared earlier in the block are visible to later specs
+	scope := newScope(env)
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.TypeSpec)
+		t, moreErrs := checkTypeSpec(ctx, spec, scope)
+		if moreErrs != nil {
+			errs = append(errs, moreErrs...)
+			continue
+		}
+		tdecl.names = append(tdecl.names, spec.Name.Name)
+		tdecl.types = append(tdecl.types, t)
+		if spec.Name.Name != "_" {
+			scope.Types[spec.Name.Name] = t
+		}
+	}
+	return tdecl, errs
+}
+
+func checkTypeSpec(ctx *Ctx, spec *ast.TypeSpec, env *Env) (reflect.Type, []error) {
+	if spec.TypeParams != nil {
+		return nil, []error{ErrUnsupportedType{at(ctx, spec), "generic types are not implemented"}}
+	} else if refersTo(spec.Type, spec.Name.Name) {
+		return nil, []error{ErrRecursiveType{at(ctx, spec), spec.Name.Name}}
+	}
+
+	texpr, errs := checkTypeExpr(ctx, spec.Type, env)
+	if errs != nil {
+		return nil, errs
+	}
+	spec.Type = texpr
+	t, err := evalType(ctx, texpr, env)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if spec.Assign.IsValid() {
+		return t, nil
+	}
+	u := underlyingType(t)
+	ctx.warn(WarnDefinedType{at(ctx, spec.Name), spec.Name.Name, u})
+	return u, nil
+}
+
+// refersTo reports whether the type expression expr refers to the type
+// name. Field names and package qualified names are not references.
+func refersTo(expr ast.Expr, name string) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Ident:
+			found = found || node.Name == name
+		case *ast.Field:
+			found = found || refersTo(node.Type, name)
+			return false
+		case *ast.SelectorExpr:
+			found = found || refersTo(node.X, name)
+			return false
+		}
+		return !found
+	})
+	return found
+}
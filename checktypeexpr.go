@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+
+	"go/ast"
+)
+
+// checkTypeExpr checks an expression in a type context, such as the type
+// of a composite literal or the right hand side of a type declaration.
+// Names are resolved by evalType, checkTypeExpr only checks the structure
+// of the type and any array lengths.
+func checkTypeExpr(ctx *Ctx, expr ast.Expr, env *Env) (Expr, []error) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return &Ident{Ident: expr}, nil
+	case *ast.ParenExpr:
+		aexpr := &ParenExpr{ParenExpr: expr}
+		var errs []error
+		aexpr.X, errs = checkTypeExpr(ctx, expr.X, env)
+		return aexpr, errs
+	case *ast.SelectorExpr:
+		return &SelectorExpr{SelectorExpr: expr}, nil
+	case *ast.StarExpr:
+		aexpr := &StarExpr{StarExpr: expr}
+		var errs []error
+		aexpr.X, errs = checkTypeExpr(ctx, expr.X, env)
+		return aexpr, errs
+	case *ast.ArrayType:
+		return checkArrayType(ctx, expr, env)
+	case *ast.StructType:
+		aexpr := &StructType{StructType: expr}
+		return aexpr, checkFieldListTypes(ctx, expr.Fields, env)
+	case *ast.FuncType:
+		aexpr := &FuncType{FuncType: expr}
+		errs := checkFieldListTypes(ctx, expr.Params, env)
+		errs = append(errs, checkFieldListTypes(ctx, expr.Results, env)...)
+		return aexpr, errs
+	case *ast.InterfaceType:
+		return &InterfaceType{InterfaceType: expr}, nil
+	case *ast.MapType:
+		aexpr := &MapType{MapType: expr}
+		var errs, moreErrs []error
+		if aexpr.Key, moreErrs = checkTypeExpr(ctx, expr.Key, env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		if aexpr.Value, moreErrs = checkTypeExpr(ctx, expr.Value, env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		return aexpr, errs
+	case *ast.ChanType:
+		aexpr := &ChanType{ChanType: expr}
+		var errs []error
+		aexpr.Value, errs = checkTypeExpr(ctx, expr.Value, env)
+		return aexpr, errs
+	default:
+		return nil, []error{errors.New(fmt.Sprintf("Type: Bad type (%+v)", expr))}
+	}
+}
+
+func checkArrayType(ctx *Ctx, array *ast.ArrayType, env *Env) (aexpr *ArrayType, errs []error) {
+	aexpr = &ArrayType{ArrayType: array}
+
+	var moreErrs []error
+	if array.Len != nil {
+		if _, ok := array.Len.(*ast.Ellipsis); ok {
+			errs = append(errs, ErrUnsupportedType{at(ctx, array), "[...] arrays are not implemented"})
+		} else if aexpr.Len, moreErrs = CheckExpr(ctx, array.Len, env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		} else if _, ok := arrayLength(aexpr.Len.(Expr)); !ok {
+			errs = append(errs, ErrInvalidArrayBound{at(ctx, aexpr.Len)})
+		}
+	}
+	if aexpr.Elt, moreErrs = checkTypeExpr(ctx, array.Elt, env); moreErrs != nil {
+		errs = append(errs, moreErrs...)
+	}
+	return aexpr, errs
+}
+
+// checkFieldListTypes checks the types of a struct, parameter or result
+// list in place.
+func checkFieldListTypes(ctx *Ctx, list *ast.FieldList, env *Env) (errs []error) {
+	if list == nil {
+		return nil
+	}
+	for _, field := range list.List {
+		var moreErrs []error
+		if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
+			// Variadic parameter. The Ellipsis node is kept so that
+			// evalFuncType can tell a ...T parameter from a []T one.
+			if ellipsis.Elt, moreErrs = checkTypeExpr(ctx, ellipsis.Elt, env); moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			}
+			field.Type = &Ellipsis{Ellipsis: ellipsis}
+		} else if field.Type, moreErrs = checkTypeExpr(ctx, field.Type, env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	return errs
+}
@@ -1,6 +1,8 @@
 package eval
 
 import (
+	"reflect"
+
 	"go/ast"
 	"go/token"
 )
@@ -13,6 +15,32 @@ func checkUnaryExpr(ctx *Ctx, unary *ast.UnaryExpr, env *Env) (aexpr *UnaryExpr,
 		errs = append(errs, moreErrs...)
 	}
 
+	if errs == nil && unary.Op == token.AND {
+		// Composite literals are the exception to addressability
+		x := aexpr.X.(Expr)
+		if _, isLit := x.(*CompositeLit); !isLit && (x.IsConst() || !isAddressable(x)) {
+			return aexpr, []error{ErrUnaddressable{at(ctx, x)}}
+		} else if name, ok := readOnlyPath(ctx, x, env); ok {
+			errs = append(errs, ErrDenied{at(ctx, unary), "taking the address of " + name})
+		} else if t := staticType(x, env); t != nil {
+			aexpr.knownType = knownType{reflect.PtrTo(t)}
+		}
+		return aexpr, errs
+	}
+
+	if errs == nil && unary.Op == token.ARROW {
+		// Receive. The result type is known if the channel type is
+		ch := aexpr.X.(Expr)
+		if ctx.Policy.readOnly() {
+			return aexpr, []error{ErrDenied{at(ctx, unary), "receive"}}
+		} else if errs = checkChanOp(ctx, ch, false, env); errs == nil {
+			if t := staticType(ch, env); t != nil {
+				aexpr.knownType = knownType{t.Elem()}
+			}
+		}
+		return aexpr, errs
+	}
+
 	if errs == nil {
 		a := aexpr.X.(Expr)
 		t := a.KnownType()
@@ -28,11 +56,13 @@ func checkUnaryExpr(ctx *Ctx, unary *ast.UnaryExpr, env *Env) (aexpr *UnaryExpr,
 		if a.IsConst() {
 			if c, ok := t[0].(ConstType); ok {
 				aexpr.constValue, moreErrs = evalConstUnaryExpr(ctx, aexpr, c)
-				if moreErrs != nil {
-					errs = append(errs, moreErrs...)
-				} else {
-					aexpr.knownType = t
-				}
+			} else {
+				aexpr.constValue, moreErrs = evalConstTypedUnaryExpr(ctx, aexpr, t[0])
+			}
+			if moreErrs != nil {
+				errs = append(errs, moreErrs...)
+			} else {
+				aexpr.knownType = t
 			}
 		}
 	}
@@ -79,3 +109,31 @@ func evalConstUnaryBoolExpr(ctx *Ctx, constExpr *UnaryExpr, x bool) (constValue,
 		return constValue{}, []error{ErrInvalidUnaryOperation{at(ctx, constExpr)}}
 	}
 }
+
+// Evaluates a unary Expr whose operand is a typed constant of type t
+func evalConstTypedUnaryExpr(ctx *Ctx, constExpr *UnaryExpr, t reflect.Type) (constValue, []error) {
+	x := constExpr.X.(Expr).Const()
+	if t.Kind() == reflect.Bool {
+		return evalConstUnaryBoolExpr(ctx, constExpr, x.Bool())
+	}
+
+	xx := typedConstNumber(x)
+	if xx == nil {
+		return constValue{}, []error{ErrInvalidUnaryOperation{at(ctx, constExpr)}}
+	}
+
+	// ^x of an unsigned x complements only the bits of its type
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if constExpr.Op == token.XOR {
+			mask := ^uint64(0) >> uint(64-t.Bits())
+			return convertConstToTyped(ctx, ConstInt, constValueOf(NewConstUint64(^x.Uint()&mask)), t, constExpr)
+		}
+	}
+
+	z, errs := evalConstUnaryNumericExpr(ctx, constExpr, xx)
+	if errs != nil {
+		return z, errs
+	}
+	return convertConstToTyped(ctx, xx.Type, z, t, constExpr)
+}
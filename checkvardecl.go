@@ -0,0 +1,61 @@
+package eval
+
+import (
+	"reflect"
+
+	"go/ast"
+)
+
+// checkVarDecl checks a var declaration. The declared types are
+// constructed by the checker, the values are left to EvalDecl.
+func checkVarDecl(ctx *Ctx, decl *ast.GenDecl, env *Env) (vdecl *VarDecl, errs []error) {
+	vdecl = &VarDecl{GenDecl: decl}
+
+	// Variables declared by earlier specs are visible to later ones
+	scope := newScope(env)
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		t, moreErrs := checkVarSpec(ctx, spec, scope)
+		if moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+		vdecl.types = append(vdecl.types, t)
+		for _, name := range spec.Names {
+			if name.Name != "_" {
+				scope.Vars[name.Name] = reflect.Value{}
+			}
+		}
+	}
+	return vdecl, errs
+}
+
+// checkVarSpec checks the values of a spec, returning its type if one
+// is given
+func checkVarSpec(ctx *Ctx, spec *ast.ValueSpec, env *Env) (t reflect.Type, errs []error) {
+	if spec.Type != nil {
+		texpr, moreErrs := checkTypeExpr(ctx, spec.Type, env)
+		if moreErrs != nil {
+			return nil, moreErrs
+		}
+		spec.Type = texpr
+		var err error
+		if t, err = evalType(ctx, texpr, env); err != nil {
+			return nil, []error{err}
+		}
+		t = unhackType(t)
+	}
+
+	if len(spec.Values) != 0 {
+		if err := checkAssignCount(ctx, spec, len(spec.Names), spec.Values); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := range spec.Values {
+		var moreErrs []error
+		if spec.Values[i], moreErrs = CheckExpr(ctx, spec.Values[i], env); moreErrs != nil {
+			errs = append(errs, moreErrs...)
+		}
+	}
+	return t, errs
+}
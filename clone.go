@@ -0,0 +1,316 @@
+package eval
+
+import (
+	"go/ast"
+)
+
+// cloneExpr returns a deep copy of an unchecked ast.Expr. Checking an
+// expression annotates it in place, so an expression that must be
+// checked more than once, such as the implicitly repeated values of a
+// const declaration, must be cloned before its first check.
+// Positions are preserved, so errors refer to the original source.
+func cloneExpr(expr ast.Expr) ast.Expr {
+	switch expr := expr.(type) {
+	case nil:
+		return nil
+	case *ast.BadExpr:
+		e := *expr
+		return &e
+	case *ast.Ident:
+		e := *expr
+		return &e
+	case *ast.Ellipsis:
+		e := *expr
+		e.Elt = cloneExpr(expr.Elt)
+		return &e
+	case *ast.BasicLit:
+		e := *expr
+		return &e
+	case *ast.FuncLit:
+		e := *expr
+		e.Type = cloneExpr(expr.Type).(*ast.FuncType)
+		e.Body = cloneStmt(expr.Body).(*ast.BlockStmt)
+		return &e
+	case *ast.CompositeLit:
+		e := *expr
+		e.Type = cloneExpr(expr.Type)
+		e.Elts = cloneExprs(expr.Elts)
+		return &e
+	case *ast.ParenExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		return &e
+	case *ast.SelectorExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		e.Sel = cloneExpr(expr.Sel).(*ast.Ident)
+		return &e
+	case *ast.IndexExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		e.Index = cloneExpr(expr.Index)
+		return &e
+	case *ast.SliceExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		e.Low = cloneExpr(expr.Low)
+		e.High = cloneExpr(expr.High)
+		e.Max = cloneExpr(expr.Max)
+		return &e
+	case *ast.TypeAssertExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		e.Type = cloneExpr(expr.Type)
+		return &e
+	case *ast.CallExpr:
+		e := *expr
+		e.Fun = cloneExpr(expr.Fun)
+		e.Args = cloneExprs(expr.Args)
+		return &e
+	case *ast.StarExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		return &e
+	case *ast.UnaryExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		return &e
+	case *ast.BinaryExpr:
+		e := *expr
+		e.X = cloneExpr(expr.X)
+		e.Y = cloneExpr(expr.Y)
+		return &e
+	case *ast.KeyValueExpr:
+		e := *expr
+		e.Key = cloneExpr(expr.Key)
+		e.Value = cloneExpr(expr.Value)
+		return &e
+	case *ast.ArrayType:
+		e := *expr
+		e.Len = cloneExpr(expr.Len)
+		e.Elt = cloneExpr(expr.Elt)
+		return &e
+	case *ast.StructType:
+		e := *expr
+		e.Fields = cloneFieldList(expr.Fields)
+		return &e
+	case *ast.FuncType:
+		e := *expr
+		e.Params = cloneFieldList(expr.Params)
+		e.Results = cloneFieldList(expr.Results)
+		return &e
+	case *ast.InterfaceType:
+		e := *expr
+		e.Methods = cloneFieldList(expr.Methods)
+		return &e
+	case *ast.MapType:
+		e := *expr
+		e.Key = cloneExpr(expr.Key)
+		e.Value = cloneExpr(expr.Value)
+		return &e
+	case *ast.ChanType:
+		e := *expr
+		e.Value = cloneExpr(expr.Value)
+		return &e
+	default:
+		panic("go-interactive: cannot clone expression")
+	}
+}
+
+func cloneExprs(exprs []ast.Expr) []ast.Expr {
+	if exprs == nil {
+		return nil
+	}
+	clones := make([]ast.Expr, len(exprs))
+	for i, expr := range exprs {
+		clones[i] = cloneExpr(expr)
+	}
+	return clones
+}
+
+func cloneIdents(idents []*ast.Ident) []*ast.Ident {
+	if idents == nil {
+		return nil
+	}
+	clones := make([]*ast.Ident, len(idents))
+	for i, ident := range idents {
+		clones[i] = cloneExpr(ident).(*ast.Ident)
+	}
+	return clones
+}
+
+func cloneFieldList(list *ast.FieldList) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+	l := *list
+	l.List = make([]*ast.Field, len(list.List))
+	for i, field := range list.List {
+		f := *field
+		f.Names = cloneIdents(field.Names)
+		f.Type = cloneExpr(field.Type)
+		l.List[i] = &f
+	}
+	return &l
+}
+
+// cloneStmt is the ast.Stmt counterpart of cloneExpr.
+func cloneStmt(stmt ast.Stmt) ast.Stmt {
+	switch stmt := stmt.(type) {
+	case nil:
+		return nil
+	case *ast.BadStmt:
+		s := *stmt
+		return &s
+	case *ast.DeclStmt:
+		s := *stmt
+		s.Decl = cloneDecl(stmt.Decl)
+		return &s
+	case *ast.EmptyStmt:
+		s := *stmt
+		return &s
+	case *ast.LabeledStmt:
+		s := *stmt
+		s.Label = cloneExpr(stmt.Label).(*ast.Ident)
+		s.Stmt = cloneStmt(stmt.Stmt)
+		return &s
+	case *ast.ExprStmt:
+		s := *stmt
+		s.X = cloneExpr(stmt.X)
+		return &s
+	case *ast.SendStmt:
+		s := *stmt
+		s.Chan = cloneExpr(stmt.Chan)
+		s.Value = cloneExpr(stmt.Value)
+		return &s
+	case *ast.IncDecStmt:
+		s := *stmt
+		s.X = cloneExpr(stmt.X)
+		return &s
+	case *ast.AssignStmt:
+		s := *stmt
+		s.Lhs = cloneExprs(stmt.Lhs)
+		s.Rhs = cloneExprs(stmt.Rhs)
+		return &s
+	case *ast.GoStmt:
+		s := *stmt
+		s.Call = cloneExpr(stmt.Call).(*ast.CallExpr)
+		return &s
+	case *ast.DeferStmt:
+		s := *stmt
+		s.Call = cloneExpr(stmt.Call).(*ast.CallExpr)
+		return &s
+	case *ast.ReturnStmt:
+		s := *stmt
+		s.Results = cloneExprs(stmt.Results)
+		return &s
+	case *ast.BranchStmt:
+		s := *stmt
+		if stmt.Label != nil {
+			s.Label = cloneExpr(stmt.Label).(*ast.Ident)
+		}
+		return &s
+	case *ast.BlockStmt:
+		s := *stmt
+		s.List = cloneStmts(stmt.List)
+		return &s
+	case *ast.IfStmt:
+		s := *stmt
+		s.Init = cloneStmt(stmt.Init)
+		s.Cond = cloneExpr(stmt.Cond)
+		s.Body = cloneStmt(stmt.Body).(*ast.BlockStmt)
+		s.Else = cloneStmt(stmt.Else)
+		return &s
+	case *ast.CaseClause:
+		s := *stmt
+		s.List = cloneExprs(stmt.List)
+		s.Body = cloneStmts(stmt.Body)
+		return &s
+	case *ast.SwitchStmt:
+		s := *stmt
+		s.Init = cloneStmt(stmt.Init)
+		s.Tag = cloneExpr(stmt.Tag)
+		s.Body = cloneStmt(stmt.Body).(*ast.BlockStmt)
+		return &s
+	case *ast.TypeSwitchStmt:
+		s := *stmt
+		s.Init = cloneStmt(stmt.Init)
+		s.Assign = cloneStmt(stmt.Assign)
+		s.Body = cloneStmt(stmt.Body).(*ast.BlockStmt)
+		return &s
+	case *ast.CommClause:
+		s := *stmt
+		s.Comm = cloneStmt(stmt.Comm)
+		s.Body = cloneStmts(stmt.Body)
+		return &s
+	case *ast.SelectStmt:
+		s := *stmt
+		s.Body = cloneStmt(stmt.Body).(*ast.BlockStmt)
+		return &s
+	case *ast.ForStmt:
+		s := *stmt
+		s.Init = cloneStmt(stmt.Init)
+		s.Cond = cloneExpr(stmt.Cond)
+		s.Post = cloneStmt(stmt.Post)
+		s.Body = cloneStmt(stmt.Body).(*ast.BlockStmt)
+		return &s
+	case *ast.RangeStmt:
+		s := *stmt
+		s.Key = cloneExpr(stmt.Key)
+		s.Value = cloneExpr(stmt.Value)
+		s.X = cloneExpr(stmt.X)
+		s.Body = cloneStmt(stmt.Body).(*ast.BlockStmt)
+		return &s
+	default:
+		panic("go-interactive: cannot clone statement")
+	}
+}
+
+func cloneStmts(stmts []ast.Stmt) []ast.Stmt {
+	if stmts == nil {
+		return nil
+	}
+	clones := make([]ast.Stmt, len(stmts))
+	for i, stmt := range stmts {
+		clones[i] = cloneStmt(stmt)
+	}
+	return clones
+}
+
+func cloneDecl(decl ast.Decl) ast.Decl {
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		d := *decl
+		d.Specs = make([]ast.Spec, len(decl.Specs))
+		for i, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				s := *spec
+				s.Names = cloneIdents(spec.Names)
+				s.Type = cloneExpr(spec.Type)
+				s.Values = cloneExprs(spec.Values)
+				d.Specs[i] = &s
+			case *ast.TypeSpec:
+				s := *spec
+				s.Name = cloneExpr(spec.Name).(*ast.Ident)
+				s.Type = cloneExpr(spec.Type)
+				d.Specs[i] = &s
+			case *ast.ImportSpec:
+				s := *spec
+				d.Specs[i] = &s
+			}
+		}
+		return &d
+	case *ast.FuncDecl:
+		d := *decl
+		d.Recv = cloneFieldList(decl.Recv)
+		d.Name = cloneExpr(decl.Name).(*ast.Ident)
+		d.Type = cloneExpr(decl.Type).(*ast.FuncType)
+		if decl.Body != nil {
+			d.Body = cloneStmt(decl.Body).(*ast.BlockStmt)
+		}
+		return &d
+	default:
+		panic("go-interactive: cannot clone declaration")
+	}
+}
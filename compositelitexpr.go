@@ -4,10 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strconv"
 
 	"go/ast"
-	"go/token"
 )
 
 func evalCompositeLit(ctx *Ctx, lit *CompositeLit, env *Env) (*reflect.Value, bool, error) {
@@ -17,10 +15,19 @@ func evalCompositeLit(ctx *Ctx, lit *CompositeLit, env *Env) (*reflect.Value, bo
 	}
 
 	switch t.Kind() {
-	//case reflect.Map:
-	case reflect.Array, reflect.Slice:
+	case reflect.Map:
+		return evalCompositeLitMap(ctx, t, lit, env)
+	case reflect.Array:
+		if err := ctx.Limits.allocate(t.Len(), int64(t.Size())); err != nil {
+			return nil, true, err
+		}
+		return evalCompositeLitArrayOrSlice(ctx, t, lit, env)
+	case reflect.Slice:
 		return evalCompositeLitArrayOrSlice(ctx, t, lit, env)
 	case reflect.Struct:
+		if err := ctx.Limits.allocate(0, int64(t.Size())); err != nil {
+			return nil, true, err
+		}
 		return evalCompositeLitStruct(ctx, t, lit, env)
 	default:
 		return nil, true, errors.New(fmt.Sprintf("invalid type for composite literal %s", t.Name()))
@@ -31,62 +38,118 @@ func evalCompositeLitArrayOrSlice(ctx *Ctx, t reflect.Type, lit *CompositeLit, e
 
 	v := reflect.New(t).Elem()
 
+	// Check all keys are valid and calculate slice size.
+	// Elements with key are placed at the keyed position.
+	// Elements without are placed one after the previous.
+	// For example, []int{1, 2:1, 1} -> [1, 0, 1, 1]
+	keys := make([]uint64, len(lit.Elts))
 	var curKey uint64 = 0
 	var size uint64 = 0
-	if t.Kind() == reflect.Array {
-		size = uint64(t.Len())
-	} else {
-		// Check all keys are valid and calculate slice size.
-		// Elements with key are placed at the keyed position.
-		// Elements without are placed one after the previous.
-		// For example, []int{1, 2:1, 1} -> [1, 0, 1, 1]
-		for _, elt := range lit.Elts {
-			if kv, ok := elt.(*KeyValueExpr); !ok {
-				size += 1
-			} else if k, ok := kv.Key.(*BasicLit); !ok || k.Kind != token.INT {
-				return nil, false, ErrArrayKey
-
-			// The limit of 2^31 elements is infered from the go implementation
-			// The actual limit is "largest value representable by an int"
-			} else if i, err := strconv.ParseUint(k.Value, 0, 31); err != nil {
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*KeyValueExpr); ok {
+			if curKey, ok = arrayKey(kv.Key.(Expr)); !ok {
+				// Report why a key such as an undefined name is invalid
+				if _, _, err := EvalExpr(ctx, kv.Key.(Expr), env); err != nil {
+					return nil, false, err
+				}
 				return nil, false, ErrArrayKey
-			} else if !(i < size) {
-				size = i + 1
 			}
 		}
+		keys[i] = curKey
+		curKey += 1
+		if size < curKey {
+			size = curKey
+		}
+	}
+
+	if t.Kind() == reflect.Array {
+		size = uint64(t.Len())
+	} else {
 		// Allocate the slice
+		if err := ctx.Limits.allocate(int(size), int64(size)*int64(t.Elem().Size())); err != nil {
+			return nil, false, err
+		}
 		v.Set(reflect.MakeSlice(t, int(size), int(size)))
 	}
 
 	// Fill the array or slice, the reflect interface is identical for both
-	for _, elt := range lit.Elts {
-		var expr ast.Expr
-		if kv, ok := elt.(*KeyValueExpr); !ok {
-			expr = elt
-		} else {
-			// We know this expression to be valid from above.
-			curKey, _ = strconv.ParseUint(kv.Key.(*BasicLit).Value, 0, 31)
+	for i, elt := range lit.Elts {
+		var expr ast.Expr = elt
+		if kv, ok := elt.(*KeyValueExpr); ok {
 			expr = kv.Value
 		}
 
-		if !(curKey < size) {
-			return nil, false, ErrArrayIndexOutOfBounds{t, curKey}
+		if !(keys[i] < size) {
+			return nil, false, ErrArrayIndexOutOfBounds{t, keys[i]}
 		}
 
 		// Evaluate and set the element
-		elem := v.Index(int(curKey))
-		if values, typed, err := EvalExpr(ctx, expr.(Expr), env); err != nil {
+		if err := evalLitElt(ctx, v.Index(int(keys[i])), expr.(Expr), env); err != nil {
 			return nil, false, err
-		} else if value, err := expectSingleValue(ctx, *values, elt); err != nil {
+		}
+	}
+	return &v, true, nil
+}
+
+// arrayKey returns the index of a constant array or slice key. The limit
+// of 2^31 elements is infered from the go implementation, the actual
+// limit is "largest value representable by an int"
+func arrayKey(key Expr) (uint64, bool) {
+	if !key.IsConst() {
+		return 0, false
+	} else if n, ok := key.Const().Interface().(*ConstNumber); !ok {
+		return 0, false
+	} else if i, truncation, overflow := n.Value.Int(32); truncation || overflow || i < 0 {
+		return 0, false
+	} else {
+		return uint64(i), true
+	}
+}
+
+func evalCompositeLitMap(ctx *Ctx, t reflect.Type, lit *CompositeLit, env *Env) (*reflect.Value, bool, error) {
+	if err := ctx.Limits.allocate(len(lit.Elts), int64(len(lit.Elts))*int64(t.Key().Size()+t.Elem().Size())); err != nil {
+		return nil, false, err
+	}
+	v := reflect.MakeMap(t)
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*KeyValueExpr)
+		if !ok {
+			return nil, false, ErrMissingMapKey{at(ctx, elt)}
+		}
+		key := reflect.New(t.Key()).Elem()
+		if err := evalLitElt(ctx, key, kv.Key.(Expr), env); err != nil {
 			return nil, false, err
-		} else if err := setTypedValue(elem, value, typed); err != nil {
+		}
+		value := reflect.New(t.Elem()).Elem()
+		if err := evalLitElt(ctx, value, kv.Value.(Expr), env); err != nil {
 			return nil, false, err
 		}
-		curKey += 1
+		v.SetMapIndex(key, value)
 	}
 	return &v, true, nil
 }
 
+// evalLitElt sets dst to the value of an element, or a key of a map,
+// of a composite literal
+func evalLitElt(ctx *Ctx, dst reflect.Value, expr Expr, env *Env) error {
+	values, typed, err := EvalExpr(ctx, expr, env)
+	if err != nil {
+		return err
+	}
+	// Untyped nil evaluates to no values
+	var value reflect.Value
+	if values != nil {
+		if value, err = expectSingleValue(ctx, *values, expr); err != nil {
+			return err
+		}
+	}
+	if value, err = assignValue(ctx, value, typed, dst.Type(), expr); err != nil {
+		return err
+	}
+	dst.Set(value)
+	return nil
+}
+
 func evalCompositeLitStruct(ctx *Ctx, t reflect.Type, lit *CompositeLit, env *Env) (*reflect.Value, bool, error) {
 	vp := reflect.New(t)
 	v := vp.Elem()
@@ -97,53 +160,30 @@ func evalCompositeLitStruct(ctx *Ctx, t reflect.Type, lit *CompositeLit, env *En
 
 	_, pairs := lit.Elts[0].(*KeyValueExpr)
 	for i, elt := range lit.Elts {
-		var field, value *reflect.Value
-		var typed bool
-		var fname string
+		var field reflect.Value
+		var expr Expr
 		if kv, ok := elt.(*KeyValueExpr); ok != pairs {
 			return &v, true, errors.New("Elements are either all key value pairs or not")
 		} else if pairs {
 			if k, ok := kv.Key.(*Ident); !ok {
 				return &v, true, errors.New(fmt.Sprintf("Invalid key node %v %T", kv.Key, kv.Key))
-			} else if f := v.FieldByName(k.Name); !f.IsValid() {
-				return &v, true, errors.New(t.Name() + " has no field " + k.Name)
+			} else if tfield, ok := t.FieldByName(k.Name); !ok {
+				return &v, true, ErrUnknownField{at(ctx, k), t, k.Name}
+			} else if len(tfield.Index) > 1 {
+				// Only the struct's own fields, including embedded
+				// fields named by their type, may be keys
+				return &v, true, ErrPromotedField{at(ctx, k), t, promotedPath(t, tfield.Index)}
 			} else {
-				fv, ft, err := EvalExpr(ctx, kv.Value.(Expr), env)
-				if err != nil {
-					return &v, true, err
-				} else if fv == nil {
-					return nil, false, nil
-				} else {
-					field = &f
-					value = &(*fv)[0]
-					typed = ft
-					tfield, _ := v.Type().FieldByName(k.Name)
-					fname = tfield.Name
-				}
+				field, expr = v.Field(tfield.Index[0]), kv.Value.(Expr)
 			}
+		} else if i >= v.NumField() {
+			return &v, true, errors.New("Too many elements for struct " + t.Name())
 		} else {
-			if i >= v.NumField() {
-				return &v, true, errors.New("Too many elements for struct " + t.Name())
-			} else if _, ok := elt.(*KeyValueExpr); ok {
-				return &v, true, errors.New("Elements are either all key value pairs or not")
-			} else {
-				fv, ft, err := EvalExpr(ctx, elt.(Expr), env)
-				if err != nil {
-					return &v, true, err
-				} else if fv == nil {
-					return &v, false, nil
-				} else {
-					fieldi := v.Field(i)
-					field, value = &fieldi, &(*fv)[0]
-					typed = ft
-					fname = v.Type().Field(i).Name
-				}
-			}
+			field, expr = v.Field(i), elt.(Expr)
 		}
 
-		if err := setTypedValue(*field, *value, typed); err != nil {
-			return nil, true, errors.New(fmt.Sprintf("cannot set %s.%s : %v",
-				t.Name(), fname, value.String()))
+		if err := evalLitElt(ctx, field, expr, env); err != nil {
+			return nil, true, err
 		}
 	}
 	return &v, true, nil
@@ -116,3 +116,35 @@ func TestCompositeStructKeyValues(t *testing.T) {
 
 	expectResult(t, expr, env, expected)
 }
+
+func TestCompositeNilAndInterfaceElts(t *testing.T) {
+	type Alice struct {
+		Bob   *int
+		Carol interface{}
+	}
+
+	env := makeEnv()
+	env.Types["Alice"] = reflect.TypeOf(Alice{})
+
+	expectResult(t, "[]*int{nil, nil}", env, []*int{nil, nil})
+	expectResult(t, "[]interface{}{1, 2.5, nil}", env, []interface{}{1, 2.5, nil})
+	expectResult(t, "map[string]interface{}{\"a\": 1, \"b\": nil}", env,
+		map[string]interface{}{"a": 1, "b": nil})
+	expectResult(t, "Alice{ nil, 1 }", env, Alice{nil, 1})
+	expectResult(t, "Alice{ Carol: nil }", env, Alice{})
+}
+
+func TestCompositeStructUnexported(t *testing.T) {
+	type Alice struct {
+		Bob   int
+		carol int
+	}
+
+	env := makeEnv()
+	env.Types["Alice"] = reflect.TypeOf(Alice{})
+
+	expectCheckError(t, "Alice{ carol: 10 }", env,
+		"cannot refer to unexported field carol in struct literal of type eval.Alice")
+	expectCheckError(t, "Alice{ 10, 20 }", env,
+		"cannot refer to unexported field carol in struct literal of type eval.Alice")
+}
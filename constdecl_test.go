@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConstDeclIota(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const ( A = iota; B; C )", env)
+
+	expectConst(t, "A", env, NewConstInt64(0), ConstInt)
+	expectConst(t, "C", env, NewConstInt64(2), ConstInt)
+	expectResult(t, "A + B + C", env, int64(3))
+}
+
+func TestConstDeclIotaShift(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const ( _ = iota; KB = 1 << (10 * iota); MB; GB )", env)
+
+	expectConst(t, "MB", env, NewConstInt64(1<<20), ConstInt)
+	expectResult(t, "GB / MB", env, int64(1<<10))
+}
+
+func TestConstDeclIotaMultipleNames(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const ( A, B = iota, iota * 10; C, D )", env)
+
+	expectConst(t, "C", env, NewConstInt64(1), ConstInt)
+	expectConst(t, "D", env, NewConstInt64(10), ConstInt)
+}
+
+func TestConstDeclTyped(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const ( X int8 = iota * 100; Y )", env)
+
+	expectConst(t, "Y", env, int8(100), reflect.TypeOf(int8(0)))
+	expectConst(t, "Y - 1", env, int8(99), reflect.TypeOf(int8(0)))
+	expectResult(t, "Y", env, int8(100))
+	expectCheckError(t, "Y + 100", env, "constant 200 overflows int8")
+}
+
+func TestConstDeclTypedOverflow(t *testing.T) {
+	env := makeEnv()
+	expectDeclError(t, "const ( X int8 = iota * 100; Y; Z )", env, "constant 200 overflows int8")
+}
+
+func TestConstDeclUntyped(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const Pi = 3.25\nconst TwoPi = 2 * Pi\nconst Name = \"pi\"", env)
+
+	expectConst(t, "TwoPi", env, NewConstFloat64(6.5), ConstFloat)
+	expectConst(t, "Name + \"e\"", env, "pie", ConstString)
+	expectResult(t, "TwoPi", env, float64(6.5))
+	expectResult(t, "float32(TwoPi)", env, float32(6.5))
+}
+
+func TestConstDeclReferencesEarlier(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const ( A = 3; B = A * A; C = B - A )", env)
+
+	expectConst(t, "C", env, NewConstInt64(6), ConstInt)
+}
+
+func TestConstDeclReplacesVar(t *testing.T) {
+	env := makeEnv()
+	a := 5
+	env.Vars["A"] = reflect.ValueOf(&a)
+	declare(t, "const A = 10", env)
+	expectConst(t, "A", env, NewConstInt64(10), ConstInt)
+	expectResult(t, "A", env, int64(10))
+
+	if errs := EvalSource(&Ctx{}, "var x = 1\nconst x = 2\ny := x", env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+	expectResult(t, "x", env, int64(2))
+	expectResult(t, "y", env, int(2))
+}
+
+func TestConstDeclNotConstant(t *testing.T) {
+	env := makeEnv()
+	v := 1
+	env.Vars["v"] = reflect.ValueOf(&v)
+
+	expectDeclError(t, "const A = v", env, "const initializer v is not a constant")
+	expectDeclError(t, "const A = nil", env, "const initializer nil is not a constant")
+}
+
+func TestConstDeclWrongNumberOfValues(t *testing.T) {
+	env := makeEnv()
+
+	expectDeclError(t, "const A, B = 1", env, "missing value in const declaration")
+	expectDeclError(t, "const A = 1, 2", env, "extra expression in const declaration")
+	expectDeclError(t, "const A int", env, "missing value in const declaration")
+}
+
+func TestConstDeclIotaOutsideConstDecl(t *testing.T) {
+	env := makeEnv()
+
+	expectError(t, "iota", env, "iota undefined")
+}
+
+func TestVarDeclReplacesConst(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const x = 2", env)
+	declare(t, "var x = 1", env)
+	expectResult(t, "x", env, int(1))
+}
@@ -119,7 +119,7 @@ func (z *ConstNumber) Mul(x, y *ConstNumber) *ConstNumber {
 func (z *ConstNumber) Quo(x, y *ConstNumber) *ConstNumber {
 	z.Type = promoteConstNumbers(x.Type, y.Type)
 	if z.Type.IsIntegral() {
-		z.Value.Re.Num().Div(x.Value.Re.Num(), y.Value.Re.Num())
+		z.Value.Re.Num().Quo(x.Value.Re.Num(), y.Value.Re.Num())
 	} else {
 		z.Value.Quo(&x.Value, &y.Value)
 	}
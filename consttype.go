@@ -1,6 +1,9 @@
 package eval
 
-import "reflect"
+import (
+	"reflect"
+	"unicode/utf8"
+)
 
 // Type ConstType can annotate information needed for evaluating const
 // expressions. It should not be used with the reflect package.
@@ -172,7 +175,7 @@ func convertConstToTyped(ctx *Ctx, from ConstType, c constValue, to reflect.Type
 			v.SetInt(i)
 			return constValue(v), errs
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 			var errs []error
 			u, truncation, overflow := underlying.Value.Uint(to.Bits())
 			if truncation {
@@ -206,12 +209,12 @@ func convertConstToTyped(ctx *Ctx, from ConstType, c constValue, to reflect.Type
 		// string(97) is legal, equivalent of string('a')
 		case reflect.String:
 			if from.IsIntegral() {
+				// Integers which are not code points convert to "\uFFFD"
 				i, _, overflow := underlying.Value.Int(32)
 				if overflow {
-					err := ErrOverflowedConstant{at(ctx, expr), from, ConstString, underlying}
-					return constValue{}, []error{err}
+					i = utf8.RuneError
 				}
-				v.SetString(string(i))
+				v.SetString(string(rune(i)))
 				return constValue(v), nil
 			}
 		}
@@ -1,5 +1,120 @@
 package eval
 
+import (
+	"context"
+	"time"
+
+	"go/token"
+)
+
 type Ctx struct {
 	Input string
+
+	// Fset, if set, is the FileSet the code was parsed with. It is
+	// needed when the code was parsed within a larger file, as by an
+	// editor evaluating a selection. Base is the offset of Input within
+	// the file the code was parsed from. Without Fset, positions are
+	// taken to start at 1 at the start of that file, as they do when
+	// the code is parsed alone by parser.ParseExpr.
+	Fset *token.FileSet
+	Base int
+
+	// Context, if set, interrupts evaluation when it is canceled or its
+	// deadline passes. It is checked before each expression and statement
+	// is evaluated and on each iteration of a loop, and interrupts
+	// blocked channel operations. Evaluation then fails with ErrCanceled
+	// or ErrDeadlineExceeded. A call of a native function cannot be
+	// interrupted, the error is returned once it returns.
+	Context context.Context
+
+	// Timeout bounds the time for which channel operations may block. It
+	// is counted from the start of evaluation by EvalExpr, EvalStmt,
+	// EvalDecl or EvalSource, or of a call from Go of a function the code
+	// declared. A send, receive or select which is still blocked once it
+	// has passed fails with ErrTimeout. If zero, channel operations may
+	// block forever.
+	Timeout time.Duration
+
+	// Limits, if set, bounds the resources used by evaluation. Evaluation
+	// fails with ErrLimitExceeded when one is exceeded.
+	Limits *Limits
+
+	// Policy, if set, restricts the packages, functions and methods the
+	// code may use, and whether it may modify the variables of its Env
+	Policy *Policy
+
+	// Unexported gives access to the unexported fields of structs, such
+	// as a debugger needs. Unexported methods are not visible through
+	// reflect, and remain inaccessible.
+	Unexported UnexportedAccess
+
+	// Warn, if set, is called with the warnings found while checking,
+	// about code which is accepted but does not behave quite as in Go
+	Warn func(Diagnostic)
+
+	// deadline is when Timeout passes, set as evaluation starts
+	deadline time.Time
+
+	// recovering is the panic of the function whose deferred call is
+	// being made, for a declared function it calls to recover
+	recovering *panicState
+}
+
+// warn reports a warning to ctx.Warn, if it is set
+func (ctx *Ctx) warn(w Diagnoser) {
+	if ctx.Warn != nil {
+		ctx.Warn(w.Diagnostic())
+	}
+}
+
+// start returns ctx with the deadline of its Timeout counted from now,
+// unless it is evaluating already
+func (ctx *Ctx) start() *Ctx {
+	if ctx.Timeout <= 0 || !ctx.deadline.IsZero() {
+		return ctx
+	}
+	started := *ctx
+	started.deadline = time.Now().Add(ctx.Timeout)
+	return &started
+}
+
+// detach returns ctx without the deadline of the evaluation in progress,
+// for declared functions, which may be called once it is over
+func (ctx *Ctx) detach() *Ctx {
+	if ctx.deadline.IsZero() {
+		return ctx
+	}
+	detached := *ctx
+	detached.deadline = time.Time{}
+	return &detached
+}
+
+// step is called before each expression and statement is evaluated,
+// and on each iteration of a loop
+func (ctx *Ctx) step() error {
+	if err := ctx.interrupted(); err != nil {
+		return err
+	}
+	return ctx.Limits.countNode()
+}
+
+// interrupted returns ErrCanceled or ErrDeadlineExceeded once the
+// Context of ctx is done
+func (ctx *Ctx) interrupted() error {
+	if ctx.Context == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Context.Done():
+		return contextError(ctx.Context)
+	default:
+		return nil
+	}
+}
+
+func contextError(c context.Context) error {
+	if c.Err() == context.DeadlineExceeded {
+		return ErrDeadlineExceeded
+	}
+	return ErrCanceled
 }
@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+func TestCtxCanceled(t *testing.T) {
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx := &Ctx{Input: "1 + x", Context: c}
+	env := makeEnv()
+	x := 1
+	env.Vars["x"] = reflect.ValueOf(&x)
+
+	expr, _ := parser.ParseExpr(ctx.Input)
+	if cexpr, errs := CheckExpr(ctx, expr, env); errs != nil {
+		t.Fatalf("Failed to check expression (%v)", errs)
+	} else if _, _, err := EvalExpr(ctx, cexpr, env); err != ErrCanceled {
+		t.Fatalf("Expected ErrCanceled, got %v", err)
+	}
+}
+
+func TestCtxDeadlineLoop(t *testing.T) {
+	env := makeEnv()
+	declare(t, "func spin() {\n\tfor {\n\t}\n}", env)
+
+	// spin was declared without a Context, but runs with its caller's
+	for _, src := range []string{"for {\n}", "for range 1 << 62 {\n}", "spin()"} {
+		c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		errs := EvalSource(&Ctx{Context: c}, src, env)
+		cancel()
+		if len(errs) != 1 || !errors.Is(errs[0], ErrDeadlineExceeded) {
+			t.Fatalf("Expected ErrDeadlineExceeded from '%s', got %v", src, errs)
+		}
+	}
+}
+
+func TestCtxCanceledChan(t *testing.T) {
+	c, cancel := context.WithCancel(context.Background())
+	ch := make(chan int)
+	env := makeEnv()
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+
+	go func() {
+		time.Sleep(time.Millisecond)
+		cancel()
+	}()
+	errs := EvalSource(&Ctx{Context: c}, "<-ch", env)
+	if len(errs) != 1 || !errors.Is(errs[0], ErrCanceled) {
+		t.Fatalf("Expected ErrCanceled, got %v", errs)
+	}
+}
+
+func TestCtxFileSet(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\t_ = n.(int)\n}\n"
+	fset := token.NewFileSet()
+	fset.AddFile("other.go", -1, 100)
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := file.Decls[0].(*ast.FuncDecl).Body.List[0].(*ast.AssignStmt).Rhs[0]
+
+	// An editor evaluates the text of the expression within the file
+	start, end := fset.Position(expr.Pos()).Offset, fset.Position(expr.End()).Offset
+	ctx := &Ctx{Input: src[start:end], Fset: fset, Base: start}
+	env := makeEnv()
+	n := 1
+	env.Vars["n"] = reflect.ValueOf(&n)
+
+	_, errs := CheckExpr(ctx, expr, env)
+	if len(errs) != 1 || errs[0].Error() != "invalid type assertion: n (non-interface type int on left)" {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+	if d := Diagnose(errs[0]); d.Start != (Position{4, 6}) || d.End != (Position{4, 7}) {
+		t.Fatalf("Unexpected positions %+v", d)
+	}
+}
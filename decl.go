@@ -0,0 +1,228 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go/ast"
+	"go/token"
+)
+
+// Annotated ast.Decl nodes
+type Decl interface {
+	ast.Decl
+}
+
+// ConstDecl is a checked const declaration. Constants are evaluated
+// entirely by the checker, EvalDecl merely stores them in an Env.
+type ConstDecl struct {
+	*ast.GenDecl
+	names  []string
+	values []reflect.Value
+}
+
+// VarDecl is a checked var declaration. Unlike constants and types, the
+// values of variables are computed by EvalDecl.
+type VarDecl struct {
+	*ast.GenDecl
+
+	// The type of each spec, or nil if it is inferred from the values
+	types []reflect.Type
+}
+
+// FuncDecl is a checked function declaration. Methods are not supported.
+type FuncDecl struct {
+	*ast.FuncDecl
+	fn *function
+}
+
+// TypeDecl is a checked type declaration. As with constants, the types
+// are constructed by the checker.
+type TypeDecl struct {
+	*ast.GenDecl
+	names []string
+	types []reflect.Type
+}
+
+// CheckDecl type checks a declaration, as parsed by go/parser.
+// CheckDecl does not modify env, the declared names only become
+// visible once the returned Decl is passed to EvalDecl.
+func CheckDecl(ctx *Ctx, decl ast.Decl, env *Env) (Decl, []error) {
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		return checkGenDecl(ctx, decl, env)
+	case *ast.FuncDecl:
+		return checkFuncDecl(ctx, decl, env)
+	default:
+		return nil, []error{errors.New(fmt.Sprintf("Decl: Bad decl (%+v)", decl))}
+	}
+}
+
+func checkGenDecl(ctx *Ctx, decl *ast.GenDecl, env *Env) (Decl, []error) {
+	switch decl.Tok {
+	case token.CONST:
+		return checkConstDecl(ctx, decl, env)
+	case token.TYPE:
+		return checkTypeDecl(ctx, decl, env)
+	case token.VAR:
+		return checkVarDecl(ctx, decl, env)
+	default:
+		return nil, []error{errors.New(decl.Tok.String() + " declarations not implemented")}
+	}
+}
+
+// EvalDecl evaluates a checked declaration, adding the declared names
+// to env.
+func EvalDecl(ctx *Ctx, decl Decl, env *Env) error {
+	ctx = ctx.start()
+	if vdecl, ok := decl.(*VarDecl); ok {
+		err := evalVarDecl(ctx, vdecl, env, func(name string, ptr reflect.Value) {
+			envLock.Lock()
+			delete(env.Consts, name)
+			env.Vars[name] = ptr
+			envLock.Unlock()
+		})
+		if err == nil {
+			envLock.Lock()
+			recordDeclared(ctx, vdecl.GenDecl, env)
+			envLock.Unlock()
+		}
+		return err
+	}
+
+	envLock.Lock()
+	defer envLock.Unlock()
+	switch decl := decl.(type) {
+	case *ConstDecl:
+		declareConsts(decl, env)
+		recordDeclared(ctx, decl.GenDecl, env)
+		return nil
+	case *TypeDecl:
+		declareTypes(decl, env)
+		recordDeclared(ctx, decl.GenDecl, env)
+		return nil
+	case *FuncDecl:
+		if name := decl.Name.Name; name != "_" {
+			fun := makeFunc(ctx.detach(), decl.fn, env)
+			if env.declaredFuncs == nil {
+				env.declaredFuncs = new(sync.Map)
+			}
+			env.declaredFuncs.Store(fun, &declaredFunc{fn: decl.fn, env: env, input: ctx.Input, fset: ctx.Fset, base: ctx.Base})
+			env.Funcs[name] = fun
+			recordDeclared(ctx, decl.FuncDecl, env)
+		}
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("Decl: Bad decl (%+v)", decl))
+	}
+}
+
+// recordDeclared records the span within ctx.Input of each name declared
+// by decl in env.Declared. envLock must be held.
+func recordDeclared(ctx *Ctx, decl ast.Decl, env *Env) {
+	var names []*ast.Ident
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		names = append(names, decl.Name)
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				names = append(names, spec.Names...)
+			case *ast.TypeSpec:
+				names = append(names, spec.Name)
+			}
+		}
+	}
+	for _, name := range names {
+		if name.Name == "_" {
+			continue
+		}
+		start, end := at(ctx, name).inputSpan()
+		if !start.IsValid() {
+			continue
+		}
+		if env.Declared == nil {
+			env.Declared = map[string]Declaration{}
+		}
+		env.Declared[name.Name] = Declaration{ctx.Input, start, end}
+	}
+}
+
+// declareConsts adds the constants of decl to env, replacing variables
+// of the same names as checkConstDecl does
+func declareConsts(decl *ConstDecl, env *Env) {
+	for i, name := range decl.names {
+		if name != "_" {
+			delete(env.Vars, name)
+			env.Consts[name] = decl.values[i]
+		}
+	}
+}
+
+func declareTypes(decl *TypeDecl, env *Env) {
+	for i, name := range decl.names {
+		if name != "_" {
+			env.Types[name] = decl.types[i]
+		}
+	}
+}
+
+// evalVarDecl evaluates the values of a var declaration, passing a
+// pointer to each new variable to declare. The variables of a spec are
+// declared before the values of the next spec are evaluated.
+func evalVarDecl(ctx *Ctx, decl *VarDecl, env *Env, declare func(name string, ptr reflect.Value)) error {
+	for i, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		t := decl.types[i]
+		if len(spec.Values) == 0 {
+			for _, name := range spec.Names {
+				if name.Name == "_" {
+					continue
+				} else if err := ctx.Limits.allocateVar(t); err != nil {
+					return err
+				}
+				declare(name.Name, reflect.New(t))
+			}
+			continue
+		}
+
+		values, typed, err := evalValues(ctx, spec.Values, len(spec.Names), env)
+		if err != nil {
+			return err
+		}
+		ptrs := make([]reflect.Value, len(spec.Names))
+		for j := range spec.Names {
+			src := spec.Values[0]
+			if len(spec.Values) == len(spec.Names) {
+				src = spec.Values[j]
+			}
+			if vt := t; vt != nil || values[j].IsValid() {
+				if vt == nil {
+					vt = defaultValue(values[j], typed[j]).Type()
+				}
+				if err := ctx.Limits.allocateVar(vt); err != nil {
+					return err
+				}
+			}
+			if t == nil {
+				if ptrs[j], err = newVar(ctx, values[j], typed[j], src); err != nil {
+					return err
+				}
+			} else if v, err := assignValue(ctx, values[j], typed[j], t, src); err != nil {
+				return err
+			} else {
+				ptrs[j] = reflect.New(t)
+				ptrs[j].Elem().Set(v)
+			}
+		}
+		for j, name := range spec.Names {
+			if name.Name != "_" {
+				declare(name.Name, ptrs[j])
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package eval
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDeferOrder(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func f() (s string) {
+	defer func() { s += "1" }()
+	defer func() { s += "2" }()
+	return "x"
+}`, env)
+
+	expectResult(t, "f()", env, "x21")
+}
+
+func TestDeferArgsEvaluatedImmediately(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func f() (r int) {
+	x := 1
+	defer func(v int) { r = v }(x)
+	x = 2
+	return x
+}`, env)
+
+	expectResult(t, "f()", env, int(1))
+}
+
+func TestDeferNative(t *testing.T) {
+	var calls []string
+	env := makeEnv()
+	env.Funcs["record"] = reflect.ValueOf(func(s string) { calls = append(calls, s) })
+	declare(t, `func f() {
+	for _, s := range []string{"a", "b"} {
+		defer record(s)
+	}
+	record("c")
+}`, env)
+
+	expectVoid(t, "f()", env)
+	if !reflect.DeepEqual(calls, []string{"c", "b", "a"}) {
+		t.Fatalf("Wrong deferred calls %v", calls)
+	}
+}
+
+func TestDeferSnippet(t *testing.T) {
+	var calls []string
+	env := makeEnv()
+	env.Funcs["record"] = reflect.ValueOf(func(s string) { calls = append(calls, s) })
+	expectSourceErrors(t, "defer record(\"a\")\nrecord(\"b\")", env)
+
+	if !reflect.DeepEqual(calls, []string{"b", "a"}) {
+		t.Fatalf("Wrong deferred calls %v", calls)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["Sprint"] = reflect.ValueOf(fmt.Sprint)
+	env.Funcs["explode"] = reflect.ValueOf(func() { panic("native") })
+	declare(t, `func safe(f func()) (msg string) {
+	defer func() { msg = Sprint(recover()) }()
+	f()
+	return "ok"
+}
+func div(a, b int) int { return a / b }`, env)
+
+	run(t, `a := safe(func() {})
+b := safe(func() { panic("boom") })
+c := safe(func() { div(1, 0) })
+d := safe(explode)`, env)
+
+	expectResult(t, "a", env, "<nil>")
+	expectResult(t, "b", env, "boom")
+	expectResult(t, "c", env, "runtime error: integer divide by zero")
+	expectResult(t, "d", env, "native")
+}
+
+func TestRecoverDeclared(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["Sprint"] = reflect.ValueOf(fmt.Sprint)
+	declare(t, `func handle(msg *string) { *msg = Sprint(recover()) }
+func indirect(msg *string) { handle(msg) }
+func safe(f func()) (msg string) {
+	defer handle(&msg)
+	f()
+	return "ok"
+}
+func unsafe(f func()) (msg string) {
+	defer indirect(&msg)
+	f()
+	return "ok"
+}`, env)
+
+	expectResult(t, `safe(func() { panic("boom") })`, env, "boom")
+	expectError(t, `unsafe(func() { panic("boom") })`, env, "panic: boom")
+}
+
+func TestRecoverOnlyPanics(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func rec(n int) int {
+	defer func() { recover() }()
+	return rec(n + 1)
+}`, env)
+
+	expectLimitExceeded(t, "rec(0)", env, &Limits{CallDepth: 50}, "CallDepth")
+}
+
+func TestPanic(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["explode"] = reflect.ValueOf(func() { panic("native") })
+	declare(t, `func f() int { panic("boom") }`, env)
+
+	expectError(t, "f()", env, "panic: boom")
+	expectError(t, "explode()", env, "panic: native")
+}
+
+func TestGo(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]int, 3)
+	env := makeEnv()
+	env.Funcs["done"] = reflect.ValueOf(wg.Done)
+	env.Vars["results"] = reflect.ValueOf(&results)
+	declare(t, `func square(i int) {
+	defer done()
+	results[i] = i * i
+}`, env)
+
+	wg.Add(3)
+	run(t, "for i := 0; i < 3; i++ {\n\tgo square(i)\n}", env)
+	wg.Wait()
+	if !reflect.DeepEqual(results, []int{0, 1, 4}) {
+		t.Fatalf("Wrong results %v", results)
+	}
+}
+
+func TestGoErrors(t *testing.T) {
+	errs := make(chan error, 1)
+	env := makeEnv()
+	env.GoErrors = errs
+	run(t, `go func() { panic("worker") }()`, env)
+
+	if err := <-errs; err.Error() != "panic: worker" {
+		t.Fatalf("Wrong goroutine error %v", err)
+	}
+}
+
+func TestDeferErrors(t *testing.T) {
+	env := makeEnv()
+	expectStmtError(t, "defer int(1)", env,
+		"defer requires function call, not conversion")
+	expectStmtError(t, "go string(\"a\")", env,
+		"go requires function call, not conversion")
+}
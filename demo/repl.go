@@ -18,11 +18,14 @@ package main
 import (
 	"fmt"
 	"go/parser"
+	"go/scanner"
+	"go/token"
 	"os"
 	"reflect"
+	"strings"
 
-	"github.com/raff/eval"
 	"github.com/gobs/cmd"
+	"github.com/raff/eval"
 )
 
 func intro_text() {
@@ -32,6 +35,9 @@ Results of expression are stored in variable slice "results".
 The environment is stored in global variable "env".
 
 Enter expressions to be evaluated at the "go>" prompt.
+Constants and types may be declared with "const" and "type", for example:
+    const ( A = iota; B; C )
+    type Point struct{ X, Y int }
 
 To see all results, type: "results".
 
@@ -54,19 +60,17 @@ var (
 )
 
 func evalCmd(line string) {
-	ctx := &eval.Ctx{line}
+	if isDecl(line) {
+		declCmd(line)
+		return
+	}
+	ctx := &eval.Ctx{Input: line}
 	if expr, err := parser.ParseExpr(line); err != nil {
-		if pair := eval.FormatErrorPos(line, err.Error()); len(pair) == 2 {
-			fmt.Println(pair[0])
-			fmt.Println(pair[1])
-		}
-		fmt.Printf("parse error: %s\n", err)
+		printParseError(line, 0, err)
 	} else if cexpr, errs := eval.CheckExpr(ctx, expr, env); len(errs) != 0 {
-		for _, cerr := range errs {
-			fmt.Printf("%v\n", cerr)
-		}
+		printErrors(line, errs)
 	} else if vals, _, err := eval.EvalExpr(ctx, cexpr, env); err != nil {
-		fmt.Printf("eval error: %s\n", err)
+		printErrors(line, []error{err})
 	} else if vals == nil {
 		fmt.Printf("Kind=nil\nnil\n")
 	} else if len(*vals) == 0 {
@@ -85,7 +89,7 @@ func evalCmd(line string) {
 
 			n := len(results)
 			results = append(results, (*vals)[0].Interface())
-			fmt.Printf("results[%d] = %s\n", n, eval.Inspect(value))
+			fmt.Printf("results[%d] = %s\n", n, eval.InspectWith(value, inspectOptions))
 		} else {
 			fmt.Printf("%s\n", value)
 		}
@@ -93,7 +97,7 @@ func evalCmd(line string) {
 		fmt.Printf("Kind = Multi-Value\n")
 		size := len(*vals)
 		for i, v := range *vals {
-			fmt.Printf("%s", eval.Inspect(v))
+			fmt.Printf("%s", eval.InspectWith(v, inspectOptions))
 			if i < size-1 {
 				fmt.Printf(", ")
 			}
@@ -103,18 +107,102 @@ func evalCmd(line string) {
 	}
 }
 
+// isDecl reports whether line starts with a declaration keyword
+func isDecl(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, keyword := range []string{"const", "type", "var", "func"} {
+		if strings.HasPrefix(line, keyword+" ") || strings.HasPrefix(line, keyword+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// declCmd checks and evaluates the declarations in line, adding the
+// declared names to env
+func declCmd(line string) {
+	// Parse line as a source file. The package clause is on its own
+	// line so that columns in parse errors match the input, and
+	// Ctx.Base skips it so that check errors are positioned within line.
+	const header = "package main\n"
+	ctx := &eval.Ctx{Input: line, Base: len(header)}
+	ctx.Warn = func(d eval.Diagnostic) {
+		fmt.Print(eval.RenderDiagnostic(line, d, renderOptions()))
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", header+line, 0)
+	if err != nil {
+		printParseError(line, 1, err)
+		return
+	}
+	for _, decl := range f.Decls {
+		if cdecl, errs := eval.CheckDecl(ctx, decl, env); len(errs) != 0 {
+			printErrors(line, errs)
+			return
+		} else if err := eval.EvalDecl(ctx, cdecl, env); err != nil {
+			printErrors(line, []error{err})
+			return
+		}
+	}
+}
+
+// inspectOptions shows results with their types, eliding enough of
+// large or deep values to keep them readable
+var inspectOptions = eval.InspectOptions{
+	Types:        true,
+	MaxDepth:     8,
+	MaxElements:  64,
+	MaxStringLen: 256,
+}
+
+// renderOptions colors errors if stdout is a terminal, and adds notes
+// for the names declared by earlier lines
+func renderOptions() eval.RenderOptions {
+	color := false
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		color = os.Getenv("TERM") != "dumb"
+	}
+	return eval.RenderOptions{Color: color, Env: env}
+}
+
+// printErrors prints each of errs under the part of line it refers to
+func printErrors(line string, errs []error) {
+	opts := renderOptions()
+	for _, err := range errs {
+		fmt.Print(eval.RenderDiagnostic(line, eval.Diagnose(err), opts))
+	}
+}
+
+// printParseError prints the errors of go/parser, whose line numbers are
+// skip more than those of line
+func printParseError(line string, skip int, err error) {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		fmt.Printf("parse error: %s\n", err)
+		return
+	}
+	opts := renderOptions()
+	for _, e := range list {
+		d := eval.Diagnostic{
+			Start:   eval.Position{Line: e.Pos.Line - skip, Column: e.Pos.Column},
+			Message: e.Msg,
+		}
+		fmt.Print(eval.RenderDiagnostic(line, d, opts))
+	}
+}
+
 // Create an eval.Env environment to use in evaluation.
 // This is a bit ugly here, because we are rolling everything by hand, but
 // we want some sort of environment to show off in demo'ing.
 // The artifical environment we create here consists of
-//   fmt:
-//      fns: fmt.Println, fmt.Printf
-//   os:
-//      types: MyInt
-//      vars: Stdout, Args
-//   main:
-//      type Alice
-//      var  alice, aliceptr
+//
+//	fmt:
+//	   fns: fmt.Println, fmt.Printf
+//	os:
+//	   types: MyInt
+//	   vars: Stdout, Args
+//	main:
+//	   type Alice
+//	   var  alice, aliceptr
 //
 // (REPL also adds var results to main)
 //
@@ -0,0 +1,577 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Code identifies the kind of error a Diagnostic describes, as the error
+// codes of go/types do. The values of codes are stable, new codes are
+// only ever added at the end.
+type Code int
+
+const (
+	// UnknownError is the code of errors which are not of this
+	// package, such as those returned by native functions
+	UnknownError Code = iota
+
+	InvalidLiteral
+	InvalidOperand
+	InvalidIndirection
+	MismatchedTypes
+	UndefinedOp
+	InvalidArgument
+	WrongArgCount
+	MissingValue
+	TooManyValues
+	IndexOutOfRange
+	NonIndexableOperand
+	InvalidIndex
+	DivByZero
+	InvalidConversion
+	TruncatedFloat
+	NumericOverflow
+	UntypedNilUse
+	MissingInitExpr
+	ExtraInitExpr
+	NotConstant
+	IncompatibleAssign
+	InvalidShiftCount
+	InvalidShiftOperand
+	InvalidArrayLen
+	IncomparableMapKey
+	UnsupportedFeature
+	InvalidDeclCycle
+	InvalidMethodDecl
+	Panic
+	InvalidDefer
+	MissingFuncBody
+	WrongAssignCount
+	NoNewVar
+	NonNameDecl
+	UnassignableOperand
+	UnusedExpr
+	InvalidCond
+	MisplacedBranch
+	UndeclaredLabel
+	MissingReturn
+	WrongResultCount
+	ReturnOutsideFunc
+	InvalidChanOp
+	Timeout
+	Canceled
+	DeadlineExceeded
+	NotInstantiated
+	InvalidInstance
+	WrongTypeArgCount
+	UninstantiatedGeneric
+	NotAGenericFunc
+	CannotInferTypeArgs
+	LimitExceeded
+	MissingLitField
+	PromotedLitField
+	DuplicateLitField
+	InvalidLitField
+	MixedStructLit
+	InvalidStructLit
+	Denied
+	InvalidAssert
+	ImpossibleAssert
+	BadTypeKeyword
+	MissingFieldOrMethod
+	DuplicateLitKey
+	MissingLitKey
+	NonSliceableOperand
+	InvalidSliceExpr
+	UnexportedLitField
+	UnaddressableOperand
+	NotAnExpr
+	InvalidSyntaxTree
+	UncallableOperand
+	InvalidTypeSwitch
+	DefinedTypeCollapsed
+	UnexportedName
+)
+
+// Severity is the severity of a Diagnostic
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	}
+	return "error"
+}
+
+// Position is a line and column of the source, both counting from 1.
+// Columns count bytes, as in token.Position. The zero Position is
+// unknown.
+type Position struct {
+	Line, Column int
+}
+
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// Related is a secondary position of a Diagnostic, such as that of an
+// earlier declaration
+type Related struct {
+	Start, End Position
+	Message    string
+
+	// The source Start and End are positions of, if it is not that of
+	// the Diagnostic. The declaration of a name may be in an earlier
+	// input.
+	Source string
+}
+
+// Diagnostic describes an error with the span of source it occurred at,
+// from Start up to End. The positions are unknown for errors which are
+// not caused by a particular node, such as a timeout.
+type Diagnostic struct {
+	Severity   Severity
+	Code       Code
+	Start, End Position
+	Message    string
+	Related    []Related
+}
+
+func (d Diagnostic) Error() string {
+	if d.Start.IsValid() {
+		return d.Start.String() + ": " + d.Message
+	}
+	return d.Message
+}
+
+// Diagnoser is implemented by every error type of this package
+type Diagnoser interface {
+	error
+	Diagnostic() Diagnostic
+}
+
+// Diagnose returns the Diagnostic of any error. Errors which are not
+// Diagnosers have the code UnknownError.
+func Diagnose(err error) Diagnostic {
+	switch err {
+	case ErrCanceled:
+		return diagnostic(Canceled, err)
+	case ErrDeadlineExceeded:
+		return diagnostic(DeadlineExceeded, err)
+	case ErrArrayKey:
+		return diagnostic(InvalidIndex, err)
+	}
+	if d, ok := err.(Diagnoser); ok {
+		return d.Diagnostic()
+	}
+	return diagnostic(UnknownError, err)
+}
+
+// diagnostic describes an error which has no position
+func diagnostic(code Code, err error) Diagnostic {
+	return Diagnostic{Code: code, Message: err.Error()}
+}
+
+// diagnostic describes err, an error at the node of errCtx
+func (errCtx ErrorContext) diagnostic(code Code, err error) Diagnostic {
+	d := diagnostic(code, err)
+	if errCtx.Node != nil {
+		d.Start, d.End = errCtx.span()
+	}
+	return d
+}
+
+// span returns the positions of the node. They are those of the file
+// the node was parsed from if Fset is set, and those within Input
+// otherwise.
+func (errCtx ErrorContext) span() (start, end Position) {
+	if fset := errCtx.Fset; fset != nil {
+		pos, endPos := fset.Position(errCtx.Node.Pos()), fset.Position(errCtx.Node.End())
+		if pos.IsValid() && endPos.IsValid() {
+			return Position{pos.Line, pos.Column}, Position{endPos.Line, endPos.Column}
+		}
+		return Position{}, Position{}
+	}
+	return errCtx.inputSpan()
+}
+
+// inputSpan returns the positions of the node within Input, whether or
+// not Fset is set
+func (errCtx ErrorContext) inputSpan() (start, end Position) {
+	pos, ok := errCtx.offset(errCtx.Node.Pos())
+	endPos, endOk := errCtx.offset(errCtx.Node.End())
+	if !ok || !endOk || endPos < pos {
+		return Position{}, Position{}
+	}
+	return offsetPosition(errCtx.Input, pos), offsetPosition(errCtx.Input, endPos)
+}
+
+// offsetPosition returns the position of a byte offset of src
+func offsetPosition(src string, offset int) Position {
+	line := 1 + strings.Count(src[:offset], "\n")
+	column := offset - strings.LastIndex(src[:offset], "\n")
+	return Position{line, column}
+}
+
+func (err ErrBadBasicLit) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidLiteral, err)
+}
+
+func (err ErrInvalidOperand) Diagnostic() Diagnostic {
+	return diagnostic(InvalidOperand, err)
+}
+
+func (err ErrInvalidIndirect) Diagnostic() Diagnostic {
+	return diagnostic(InvalidIndirection, err)
+}
+
+func (err ErrMismatchedTypes) Diagnostic() Diagnostic {
+	return diagnostic(MismatchedTypes, err)
+}
+
+func (err ErrInvalidOperands) Diagnostic() Diagnostic {
+	return diagnostic(UndefinedOp, err)
+}
+
+func (err ErrBadFunArgument) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidArgument, err)
+}
+
+func (err ErrBadComplexArguments) Diagnostic() Diagnostic {
+	return diagnostic(InvalidArgument, err)
+}
+
+func (err ErrBadBuiltinArgument) Diagnostic() Diagnostic {
+	return diagnostic(InvalidArgument, err)
+}
+
+func (err ErrWrongNumberOfArgsOld) Diagnostic() Diagnostic {
+	return err.diagnostic(WrongArgCount, err)
+}
+
+func (err ErrWrongNumberOfArgs) Diagnostic() Diagnostic {
+	return err.diagnostic(WrongArgCount, err)
+}
+
+func (err ErrMissingValue) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingValue, err)
+}
+
+func (err ErrMultiInSingleContext) Diagnostic() Diagnostic {
+	return err.diagnostic(TooManyValues, err)
+}
+
+func (err ErrArrayIndexOutOfBounds) Diagnostic() Diagnostic {
+	return diagnostic(IndexOutOfRange, err)
+}
+
+func (err ErrInvalidIndexOperation) Diagnostic() Diagnostic {
+	return err.diagnostic(NonIndexableOperand, err)
+}
+
+func (err ErrInvalidIndex) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidIndex, err)
+}
+
+func (err ErrDivideByZero) Diagnostic() Diagnostic {
+	return err.diagnostic(DivByZero, err)
+}
+
+func (err ErrInvalidBinaryOperation) Diagnostic() Diagnostic {
+	return err.diagnostic(UndefinedOp, err)
+}
+
+func (err ErrInvalidUnaryOperation) Diagnostic() Diagnostic {
+	return err.diagnostic(UndefinedOp, err)
+}
+
+func (err ErrBadConversion) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidConversion, err)
+}
+
+func (err ErrBadConstConversion) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidConversion, err)
+}
+
+func (err ErrTruncatedConstant) Diagnostic() Diagnostic {
+	return err.diagnostic(TruncatedFloat, err)
+}
+
+func (err ErrOverflowedConstant) Diagnostic() Diagnostic {
+	return err.diagnostic(NumericOverflow, err)
+}
+
+func (err ErrUntypedNil) Diagnostic() Diagnostic {
+	return err.diagnostic(UntypedNilUse, err)
+}
+
+func (err ErrMissingConstValue) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingInitExpr, err)
+}
+
+func (err ErrExtraConstValue) Diagnostic() Diagnostic {
+	return err.diagnostic(ExtraInitExpr, err)
+}
+
+func (err ErrNotConstant) Diagnostic() Diagnostic {
+	return err.diagnostic(NotConstant, err)
+}
+
+func (err ErrBadAssignment) Diagnostic() Diagnostic {
+	return err.diagnostic(IncompatibleAssign, err)
+}
+
+func (err ErrInvalidShiftCount) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidShiftCount, err)
+}
+
+func (err ErrInvalidShiftOperand) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidShiftOperand, err)
+}
+
+func (err ErrInvalidArrayBound) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidArrayLen, err)
+}
+
+func (err ErrInvalidMapKey) Diagnostic() Diagnostic {
+	return err.diagnostic(IncomparableMapKey, err)
+}
+
+func (err ErrUnsupportedType) Diagnostic() Diagnostic {
+	return err.diagnostic(UnsupportedFeature, err)
+}
+
+func (err ErrRecursiveType) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidDeclCycle, err)
+}
+
+func (err ErrMethodDecl) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidMethodDecl, err)
+}
+
+func (err ErrPanic) Diagnostic() Diagnostic {
+	return diagnostic(Panic, err)
+}
+
+func (err ErrBadDeferCall) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidDefer, err)
+}
+
+func (err ErrMissingFuncBody) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingFuncBody, err)
+}
+
+func (err ErrAssignCount) Diagnostic() Diagnostic {
+	return err.diagnostic(WrongAssignCount, err)
+}
+
+func (err ErrNoNewVariables) Diagnostic() Diagnostic {
+	return err.diagnostic(NoNewVar, err)
+}
+
+func (err ErrNonName) Diagnostic() Diagnostic {
+	return err.diagnostic(NonNameDecl, err)
+}
+
+func (err ErrCannotAssign) Diagnostic() Diagnostic {
+	return err.diagnostic(UnassignableOperand, err)
+}
+
+func (err ErrUnusedExpr) Diagnostic() Diagnostic {
+	return err.diagnostic(UnusedExpr, err)
+}
+
+func (err ErrNonBoolCondition) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidCond, err)
+}
+
+func (err ErrMisplacedBranch) Diagnostic() Diagnostic {
+	return err.diagnostic(MisplacedBranch, err)
+}
+
+func (err ErrUndefinedLabel) Diagnostic() Diagnostic {
+	return err.diagnostic(UndeclaredLabel, err)
+}
+
+func (err ErrMissingReturn) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingReturn, err)
+}
+
+func (err ErrWrongNumberOfReturns) Diagnostic() Diagnostic {
+	return err.diagnostic(WrongResultCount, err)
+}
+
+func (err ErrReturnOutsideFunction) Diagnostic() Diagnostic {
+	return err.diagnostic(ReturnOutsideFunc, err)
+}
+
+func (err ErrInvalidChanOp) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidChanOp, err)
+}
+
+func (err ErrTimeout) Diagnostic() Diagnostic {
+	return err.diagnostic(Timeout, err)
+}
+
+func (err ErrNotInstantiated) Diagnostic() Diagnostic {
+	return err.diagnostic(NotInstantiated, err)
+}
+
+func (err ErrBadInstance) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidInstance, err)
+}
+
+func (err ErrTypeArgCount) Diagnostic() Diagnostic {
+	return err.diagnostic(WrongTypeArgCount, err)
+}
+
+func (err ErrUninstantiatedGeneric) Diagnostic() Diagnostic {
+	return err.diagnostic(UninstantiatedGeneric, err)
+}
+
+func (err ErrNotGeneric) Diagnostic() Diagnostic {
+	return err.diagnostic(NotAGenericFunc, err)
+}
+
+func (err ErrCannotInfer) Diagnostic() Diagnostic {
+	return err.diagnostic(CannotInferTypeArgs, err)
+}
+
+func (err ErrInferMismatch) Diagnostic() Diagnostic {
+	return err.diagnostic(CannotInferTypeArgs, err)
+}
+
+func (err ErrLimitExceeded) Diagnostic() Diagnostic {
+	return diagnostic(LimitExceeded, err)
+}
+
+func (err ErrUnknownField) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingLitField, err)
+}
+
+func (err ErrUnexportedField) Diagnostic() Diagnostic {
+	return err.diagnostic(UnexportedLitField, err)
+}
+
+func (err ErrUnexportedSelector) Diagnostic() Diagnostic {
+	return err.diagnostic(UnexportedName, err)
+}
+
+func (err ErrPromotedField) Diagnostic() Diagnostic {
+	return err.diagnostic(PromotedLitField, err)
+}
+
+func (err ErrInvalidFieldName) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidLitField, err)
+}
+
+func (err ErrMixedStructLit) Diagnostic() Diagnostic {
+	return err.diagnostic(MixedStructLit, err)
+}
+
+func (err ErrCannotSlice) Diagnostic() Diagnostic {
+	return err.diagnostic(NonSliceableOperand, err)
+}
+
+func (err ErrUnaddressableSlice) Diagnostic() Diagnostic {
+	return err.diagnostic(NonSliceableOperand, err)
+}
+
+func (err ErrUnaddressable) Diagnostic() Diagnostic {
+	return err.diagnostic(UnaddressableOperand, err)
+}
+
+func (err ErrNotExpression) Diagnostic() Diagnostic {
+	return err.diagnostic(NotAnExpr, err)
+}
+
+func (err ErrInvalidExpr) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidSyntaxTree, err)
+}
+
+func (err ErrThreeIndexString) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidSliceExpr, err)
+}
+
+func (err ErrUncallable) Diagnostic() Diagnostic {
+	return err.diagnostic(UncallableOperand, err)
+}
+
+func (err ErrInvertedSliceIndices) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidSliceExpr, err)
+}
+
+// The first occurrence of a duplicated key is related
+func (err ErrDuplicateKey) Diagnostic() Diagnostic {
+	d := err.diagnostic(DuplicateLitKey, err)
+	if err.first != nil {
+		start, end := err.at(err.first).span()
+		d.Related = append(d.Related, Related{Start: start, End: end, Message: "previous key"})
+	}
+	return d
+}
+
+func (err ErrMissingMapKey) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingLitKey, err)
+}
+
+func (err ErrStructLitCount) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidStructLit, err)
+}
+
+func (err ErrDenied) Diagnostic() Diagnostic {
+	return err.diagnostic(Denied, err)
+}
+
+func (err ErrInvalidTypeAssert) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidAssert, err)
+}
+
+func (err ErrImpossibleTypeAssert) Diagnostic() Diagnostic {
+	return err.diagnostic(ImpossibleAssert, err)
+}
+
+func (err ErrOutsideTypeSwitch) Diagnostic() Diagnostic {
+	return err.diagnostic(BadTypeKeyword, err)
+}
+
+func (err ErrInvalidTypeSwitch) Diagnostic() Diagnostic {
+	return err.diagnostic(InvalidTypeSwitch, err)
+}
+
+func (err WarnDefinedType) Diagnostic() Diagnostic {
+	d := err.diagnostic(DefinedTypeCollapsed, err)
+	d.Severity = SeverityWarning
+	return d
+}
+
+func (err ErrInvalidNilOperands) Diagnostic() Diagnostic {
+	return err.diagnostic(MismatchedTypes, err)
+}
+
+func (err ErrNoMethod) Diagnostic() Diagnostic {
+	return err.diagnostic(MissingFieldOrMethod, err)
+}
+
+// The first key of a duplicated field is related
+func (err ErrDuplicateField) Diagnostic() Diagnostic {
+	d := err.diagnostic(DuplicateLitField, err)
+	if err.first != nil {
+		start, end := err.at(err.first).span()
+		d.Related = append(d.Related, Related{Start: start, End: end, Message: "previous key " + err.name})
+	}
+	return d
+}
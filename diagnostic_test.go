@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go/parser"
+)
+
+func checkDiagnostics(t *testing.T, expr string, env *Env) []Diagnostic {
+	ctx := &Ctx{Input: expr}
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
+	}
+	_, errs := CheckExpr(ctx, e, env)
+	var diagnostics []Diagnostic
+	for _, err := range errs {
+		diagnostics = append(diagnostics, Diagnose(err))
+	}
+	return diagnostics
+}
+
+func TestDiagnosticSpan(t *testing.T) {
+	env := makeEnv()
+	d := checkDiagnostics(t, "1 + int8(1000)", env)
+	if len(d) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", d)
+	}
+	expected := Diagnostic{
+		Severity: SeverityError,
+		Code:     NumericOverflow,
+		Start:    Position{1, 10},
+		End:      Position{1, 14},
+		Message:  "constant 1000 overflows int8",
+	}
+	if !reflect.DeepEqual(d[0], expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, d[0])
+	}
+}
+
+func TestDiagnosticRelated(t *testing.T) {
+	type T struct{ A int }
+	env := makeEnv()
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	d := checkDiagnostics(t, "T{A: 1,\n A: 2}", env)
+	if len(d) != 1 || d[0].Code != DuplicateLitField {
+		t.Fatalf("Expected a duplicate field, got %v", d)
+	}
+	related := []Related{{Start: Position{1, 3}, End: Position{1, 4}, Message: "previous key A"}}
+	if d[0].Start != (Position{2, 2}) || !reflect.DeepEqual(d[0].Related, related) {
+		t.Fatalf("Unexpected positions %+v", d[0])
+	}
+}
+
+func TestDiagnosticSource(t *testing.T) {
+	env := makeEnv()
+	errs := EvalSource(&Ctx{}, "x := 1\ny := int8(1000)", env)
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error, got %v", errs)
+	}
+	d := Diagnose(errs[0])
+	if d.Start != (Position{2, 11}) || d.End != (Position{2, 15}) || d.Code != NumericOverflow {
+		t.Fatalf("Unexpected diagnostic %+v", d)
+	}
+	if d.Error() != errs[0].Error() {
+		t.Fatalf("Diagnostic printed as %s, expected %s", d.Error(), errs[0].Error())
+	}
+}
+
+func TestDiagnoseOtherErrors(t *testing.T) {
+	if d := Diagnose(ErrCanceled); d.Code != Canceled || d.Start.IsValid() {
+		t.Fatalf("Unexpected diagnostic %+v", d)
+	}
+	if d := Diagnose(errors.New("failed")); d.Code != UnknownError || d.Message != "failed" {
+		t.Fatalf("Unexpected diagnostic %+v", d)
+	}
+	if d := Diagnose(ErrNilDereference); d.Code != Panic {
+		t.Fatalf("Unexpected diagnostic %+v", d)
+	}
+}
+
+func TestFormatDiagnostic(t *testing.T) {
+	source := "a := 1\nb := a + c"
+	results := FormatDiagnostic(source, Diagnostic{Start: Position{2, 10}, Message: "undefined: c"})
+	expect := []string{"b := a + c", "---------^"}
+	if !errorPosEqual(expect, results) {
+		t.Fatalf("Expected %v, got %v", expect, results)
+	}
+}
@@ -0,0 +1,404 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+// Differential testing against go/types and the compiler. Expressions
+// are generated over the variables of differentialDecls, and both the
+// result of checking them and their value are compared with those of
+// the reference. Disagreements are shrunk to a minimal expression and
+// reported, to be added to differentialRegressions, which
+// TestDifferentialRegressions replays, once they are fixed.
+
+const differentialDecls = `
+var (
+	i  int        = 7
+	i8 int8       = -3
+	u8 uint8      = 200
+	f  float64    = 2.5
+	c  complex128 = 1 + 2i
+	s  string     = "go"
+	b  bool       = true
+	r  rune       = 'x'
+)
+`
+
+const differentialRegressions = "testdata/differential.txt"
+
+// knownDivergences match disagreements, as differ reports them, which
+// eval is known to have with the reference. The fuzzer skips them, so as
+// to find new ones.
+var knownDivergences = []*regexp.Regexp{
+	// The checker accepts mismatched operands and invalid operators of
+	// non-constant expressions, as in f % s or u8 >> f, and constants
+	// which the type of the other operand cannot represent, as in
+	// i - 2.5. Most fail when evaluated instead.
+	regexp.MustCompile(`^go/types: .*, eval: \[\]$`),
+	// Typed constants are not converted between numeric kinds, as in
+	// complex128(int8(7))
+	regexp.MustCompile(`^go/types: <nil>, eval: \[cannot convert `),
+	// Conversions of bool values, as in bool(b)
+	regexp.MustCompile(`^go/types: <nil>, eval: \[.* used as value\]$`),
+	// Conversions of typed constants to string are not constant
+	regexp.MustCompile(`^go/types: constant .*, eval: not constant$`),
+	// Constant shift counts of float type are rejected, which go/types
+	// accepts if they are integers, as in 'a' >> float64(0)
+	regexp.MustCompile(`^go/types: <nil>, eval: \[invalid shift count `),
+}
+
+func isKnownDivergence(d string) bool {
+	for _, re := range knownDivergences {
+		if re.MatchString(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// exprGen generates an expression from the bytes of a fuzz input, each
+// byte making one choice. Once the input runs out only leaves are chosen.
+type exprGen struct {
+	data []byte
+}
+
+func (g *exprGen) choose(n int) int {
+	if len(g.data) == 0 {
+		return 0
+	}
+	choice := int(g.data[0]) % n
+	g.data = g.data[1:]
+	return choice
+}
+
+var (
+	genLeaves = []string{"0", "4", "7", "2.5", "1i", "'a'", `"ab"`, "true", "false", "nil",
+		"i", "i8", "u8", "f", "c", "s", "b", "r"}
+	genUnary  = []string{"+", "-", "^", "!"}
+	genBinary = []string{"+", "-", "*", "/", "%", "&", "|", "^", "&^", "<<", ">>",
+		"==", "!=", "<", "<=", ">", ">=", "&&", "||"}
+	genConversions = []string{"int", "int8", "uint8", "float64", "complex128", "string", "bool", "rune"}
+)
+
+func (g *exprGen) expr(depth int) string {
+	choice := 0
+	if depth > 0 && len(g.data) > 0 {
+		choice = g.choose(5)
+	}
+	switch choice {
+	case 1:
+		op, x := genUnary[g.choose(len(genUnary))], g.expr(depth-1)
+		if (op == "+" || op == "-") && x[0] == op[0] {
+			// Not ++ or --
+			return op + "(" + x + ")"
+		}
+		return op + x
+	case 2:
+		x := g.expr(depth - 1)
+		op := genBinary[g.choose(len(genBinary))]
+		return "(" + x + " " + op + " " + g.expr(depth-1) + ")"
+	case 3:
+		return genConversions[g.choose(len(genConversions))] + "(" + g.expr(depth-1) + ")"
+	default:
+		return genLeaves[g.choose(len(genLeaves))]
+	}
+}
+
+// reference is the go/types package of differentialDecls
+var reference *types.Package
+
+func referencePackage() (*types.Package, error) {
+	if reference != nil {
+		return reference, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "decls.go", "package p\n"+differentialDecls, 0)
+	if err != nil {
+		return nil, err
+	}
+	reference, err = new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	return reference, err
+}
+
+// differ returns a description of the first disagreement between this
+// package and the reference about expr, or "" if they agree. Values
+// of non-constant expressions are only compared if run is set, as each
+// comparison compiles and runs a program.
+func differ(expr string, run bool) (d string) {
+	defer func() {
+		if r := recover(); r != nil {
+			d = fmt.Sprintf("eval panicked: %v", r)
+		}
+	}()
+
+	pkg, err := referencePackage()
+	if err != nil {
+		return err.Error()
+	}
+	tv, terr := types.Eval(token.NewFileSet(), pkg, token.NoPos, expr)
+
+	i, i8, u8 := 7, int8(-3), uint8(200)
+	f, c := 2.5, 1+2i
+	s, b, r := "go", true, 'x'
+	env := makeEnv()
+	env.Vars["i"] = reflect.ValueOf(&i)
+	env.Vars["i8"] = reflect.ValueOf(&i8)
+	env.Vars["u8"] = reflect.ValueOf(&u8)
+	env.Vars["f"] = reflect.ValueOf(&f)
+	env.Vars["c"] = reflect.ValueOf(&c)
+	env.Vars["s"] = reflect.ValueOf(&s)
+	env.Vars["b"] = reflect.ValueOf(&b)
+	env.Vars["r"] = reflect.ValueOf(&r)
+	ctx := &Ctx{Input: expr}
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		// Not an expression, such as a shrunk --x
+		return ""
+	}
+	aexpr, errs := CheckExpr(ctx, e, env)
+	if (terr != nil) != (errs != nil) {
+		return fmt.Sprintf("go/types: %v, eval: %v", terr, errs)
+	} else if terr != nil || tv.IsNil() {
+		return ""
+	}
+
+	if tv.Value != nil {
+		if !aexpr.IsConst() {
+			return fmt.Sprintf("go/types: constant %v, eval: not constant", tv.Value)
+		}
+		want, got := referenceConstString(tv.Value), constString(aexpr.Const())
+		if want != got {
+			return fmt.Sprintf("go/types: constant %s, eval: constant %s", want, got)
+		}
+		return ""
+	} else if aexpr.IsConst() {
+		return fmt.Sprintf("go/types: not constant, eval: constant %v", aexpr.Const())
+	} else if !run {
+		return ""
+	}
+
+	want, wantPanic, err := runReference(expr)
+	if err != nil {
+		return err.Error()
+	}
+	var got string
+	vals, _, err := EvalExpr(ctx, aexpr, env)
+	if err != nil {
+		got = err.Error()
+	} else if vals == nil {
+		return "eval: no values"
+	} else if len(*vals) != 1 {
+		return fmt.Sprintf("eval: %d values", len(*vals))
+	} else {
+		got = fmt.Sprint((*vals)[0].Interface())
+	}
+	if wantPanic != (err != nil) || !wantPanic && want != got {
+		return fmt.Sprintf("go run: %s, eval: %s", want, got)
+	}
+	return ""
+}
+
+// runReference compiles and runs a program printing expr
+func runReference(expr string) (out string, panicked bool, err error) {
+	dir, err := ioutil.TempDir("", "differential")
+	if err != nil {
+		return "", false, err
+	}
+	defer os.RemoveAll(dir)
+
+	src := "package main\n\nimport \"fmt\"\n" + differentialDecls +
+		"\nfunc main() {\n\tfmt.Print(" + expr + ")\n}\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0644); err != nil {
+		return "", false, err
+	}
+	cmd := exec.Command("go", "run", "main.go")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); ok && strings.Contains(string(output), "panic: ") {
+		return string(output), true, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("go run %s: %v\n%s", expr, err, output)
+	}
+	return string(output), false, nil
+}
+
+// constString formats a constant of this package as referenceConstString
+// formats one of go/types
+func constString(v reflect.Value) string {
+	if n, ok := v.Interface().(*ConstNumber); ok {
+		return n.Value.Re.RatString() + "," + n.Value.Im.RatString()
+	}
+	re, im := new(big.Rat), new(big.Rat)
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		re.SetInt64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		re.SetInt(new(big.Int).SetUint64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		re.SetFloat64(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		re.SetFloat64(real(v.Complex()))
+		im.SetFloat64(imag(v.Complex()))
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+	return re.RatString() + "," + im.RatString()
+}
+
+func referenceConstString(v constant.Value) string {
+	switch v.Kind() {
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(v))
+	case constant.String:
+		return strconv.Quote(constant.StringVal(v))
+	}
+	return ratOf(constant.Real(v)).RatString() + "," + ratOf(constant.Imag(v)).RatString()
+}
+
+func ratOf(v constant.Value) *big.Rat {
+	switch x := constant.Val(v).(type) {
+	case int64:
+		return big.NewRat(x, 1)
+	case *big.Int:
+		return new(big.Rat).SetInt(x)
+	case *big.Rat:
+		return x
+	case *big.Float:
+		r, _ := x.Rat(nil)
+		return r
+	}
+	return new(big.Rat)
+}
+
+// minimize shrinks expr for as long as a smaller expression still
+// disagrees with the reference
+func minimize(expr string, run bool) string {
+	for {
+		x, err := parser.ParseExpr(expr)
+		if err != nil {
+			return expr
+		}
+		smaller := ""
+		for _, candidate := range shrinks(x) {
+			if s := types.ExprString(candidate); differ(s, run) != "" {
+				smaller = s
+				break
+			}
+		}
+		if smaller == "" {
+			return expr
+		}
+		expr = smaller
+	}
+}
+
+// shrinks returns the expressions made by replacing one node of x by
+// one of its operands
+func shrinks(x ast.Expr) (out []ast.Expr) {
+	switch x := x.(type) {
+	case *ast.ParenExpr:
+		out = append(out, x.X)
+		for _, s := range shrinks(x.X) {
+			out = append(out, &ast.ParenExpr{X: s})
+		}
+	case *ast.UnaryExpr:
+		out = append(out, x.X)
+		for _, s := range shrinks(x.X) {
+			out = append(out, &ast.UnaryExpr{Op: x.Op, X: s})
+		}
+	case *ast.BinaryExpr:
+		out = append(out, x.X, x.Y)
+		for _, s := range shrinks(x.X) {
+			out = append(out, &ast.BinaryExpr{X: s, Op: x.Op, Y: x.Y})
+		}
+		for _, s := range shrinks(x.Y) {
+			out = append(out, &ast.BinaryExpr{X: x.X, Op: x.Op, Y: s})
+		}
+	case *ast.CallExpr:
+		if len(x.Args) == 1 {
+			out = append(out, x.Args[0])
+			for _, s := range shrinks(x.Args[0]) {
+				out = append(out, &ast.CallExpr{Fun: x.Fun, Args: []ast.Expr{s}})
+			}
+		}
+	}
+	return out
+}
+
+// runReferences reports whether values are compared with those of
+// compiled programs, which needs the go command
+func runReferences() bool {
+	if testing.Short() {
+		return false
+	}
+	_, err := exec.LookPath("go")
+	return err == nil
+}
+
+func readRegressions(t testing.TB) (exprs []string) {
+	f, err := os.Open(differentialRegressions)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			exprs = append(exprs, line)
+		}
+	}
+	return exprs
+}
+
+func FuzzDifferential(f *testing.F) {
+	f.Add([]byte{2, 10, 1, 12})
+	f.Add([]byte{7, 20, 10, 39, 7, 21, 38})
+	f.Add([]byte{11, 16, 2, 30, 12, 10, 23, 25, 11})
+	f.Add([]byte{37, 24, 30, 10, 35, 28, 29})
+	f.Add([]byte{12, 35, 14, 21, 2, 14, 22, 38, 24})
+	f.Add([]byte{7, 0, 37, 12, 34, 10, 39})
+	run := runReferences()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g := &exprGen{data}
+		expr := g.expr(4)
+		if d := differ(expr, run); d != "" {
+			min := minimize(expr, run)
+			if dmin := differ(min, run); isKnownDivergence(dmin) {
+				t.Skipf("%s: known divergence %s: %s", expr, min, dmin)
+			}
+			t.Fatalf("%s: %s\nminimized to %s", expr, d, min)
+		}
+	})
+}
+
+func TestDifferentialRegressions(t *testing.T) {
+	run := runReferences()
+	for _, expr := range readRegressions(t) {
+		if d := differ(expr, run); d != "" {
+			t.Errorf("%s: %s", expr, d)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldByIndex is reflect.Value.FieldByIndex, but fails with
+// ErrNilDereference, as compiled code would panic, rather than panicking
+// when a field is promoted through a nil embedded pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, ErrNilDereference
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// promotedPath returns the path by which the field at index of the
+// struct type t is selected, e.g. Inner.X
+func promotedPath(t reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, x := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		f := t.Field(x)
+		names[i], t = f.Name, f.Type
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,146 @@
+package eval
+
+import (
+	"testing"
+	"reflect"
+)
+
+type embeddedInner struct {
+	A int
+}
+
+func (i *embeddedInner) Double() int {
+	return 2 * i.A
+}
+
+func (i embeddedInner) Get() int {
+	return i.A
+}
+
+type embeddedOuter struct {
+	*embeddedInner
+	B int
+}
+
+func TestCompositeStructEmbeddedKeyValues(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Outer struct {
+		Inner
+		B int
+	}
+
+	env := makeEnv()
+	env.Types["Inner"] = reflect.TypeOf(Inner{})
+	env.Types["Outer"] = reflect.TypeOf(Outer{})
+
+	expected := Outer { Inner: Inner{1}, B: 2 }
+	expr := "Outer{ Inner: Inner{1}, B: 2 }"
+
+	expectResult(t, expr, env, expected)
+}
+
+func TestCompositeStructEmbeddedValues(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Outer struct {
+		Inner
+		B int
+	}
+
+	env := makeEnv()
+	env.Types["Inner"] = reflect.TypeOf(Inner{})
+	env.Types["Outer"] = reflect.TypeOf(Outer{})
+
+	expected := Outer { Inner{1}, 2 }
+	expr := "Outer{ Inner{1}, 2 }"
+
+	expectResult(t, expr, env, expected)
+}
+
+func TestCompositeStructEmbeddedPointerKeyValues(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Outer struct {
+		*Inner
+	}
+
+	inner := &Inner{1}
+	env := makeEnv()
+	env.Types["Outer"] = reflect.TypeOf(Outer{})
+	env.Vars["inner"] = reflect.ValueOf(&inner)
+
+	expected := Outer { Inner: inner }
+	expr := "Outer{ Inner: inner }"
+
+	expectResult(t, expr, env, expected)
+}
+
+func TestCompositeStructBadKeys(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Outer struct {
+		Inner
+		B int
+	}
+
+	env := makeEnv()
+	env.Types["Inner"] = reflect.TypeOf(Inner{})
+	env.Types["Outer"] = reflect.TypeOf(Outer{})
+
+	expectCheckError(t, "Outer{ A: 1 }", env,
+		"cannot use promoted field Inner.A in struct literal of type eval.Outer")
+	expectCheckError(t, "Outer{ C: 1 }", env, "unknown field C in struct literal of type eval.Outer")
+	expectCheckError(t, "Outer{ B: 1, B: 2 }", env, "duplicate field name B in struct literal")
+	expectCheckError(t, "Outer{ B: 1, Inner{} }", env,
+		"mixture of field:value and value elements in struct literal")
+	expectCheckError(t, "Outer{ Inner{} }", env, "too few values in struct literal of type eval.Outer")
+}
+
+func TestSelectorPromotedField(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Outer struct {
+		Inner
+		B int
+	}
+
+	o := Outer{Inner{1}, 2}
+	env := makeEnv()
+	env.Vars["o"] = reflect.ValueOf(&o)
+
+	expectResult(t, "o.A", env, int(1))
+	expectResult(t, "o.Inner.A", env, int(1))
+}
+
+func TestSelectorPromotedThroughPointer(t *testing.T) {
+	o := embeddedOuter{&embeddedInner{3}, 2}
+	env := makeEnv()
+	env.Vars["o"] = reflect.ValueOf(&o)
+	env.Funcs["f"] = reflect.ValueOf(func() embeddedOuter { return o })
+
+	expectResult(t, "o.A", env, int(3))
+	expectResult(t, "o.Double()", env, int(6))
+	expectResult(t, "o.Get()", env, int(3))
+
+	// Values which are not addressable have the methods of their type
+	expectResult(t, "f().Get()", env, int(3))
+}
+
+func TestSelectorNilEmbeddedPointer(t *testing.T) {
+	o := embeddedOuter{B: 2}
+	var p *embeddedOuter
+	env := makeEnv()
+	env.Vars["o"] = reflect.ValueOf(&o)
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectResult(t, "o.B", env, int(2))
+	expectError(t, "o.A", env, "panic: runtime error: invalid memory address or nil pointer dereference")
+	expectError(t, "o.Get()", env, "panic: runtime error: invalid memory address or nil pointer dereference")
+	expectError(t, "p.B", env, "panic: runtime error: invalid memory address or nil pointer dereference")
+}
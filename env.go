@@ -2,6 +2,7 @@ package eval
 
 import (
 	"reflect"
+	"sync"
 )
 
 type Pkg *Env
@@ -16,9 +17,73 @@ type Env struct {
 	Consts map[string] reflect.Value
 	Funcs map[string] reflect.Value
 
+	// Generic functions of the host, which are shared by nested scopes
+	Generics map[string] *Generic
+
 	// Types
 	Types map[string] reflect.Type
 
 	// Packages
 	Pkgs map[string] Pkg
+
+	// Where the names declared from source by EvalDecl were declared,
+	// for the "declared here" notes of RenderDiagnostic. Nil until the
+	// first such declaration.
+	Declared map[string] Declaration
+
+	// Errors returned by goroutines started with go statements are
+	// sent to GoErrors. They are discarded if GoErrors is nil. A
+	// goroutine blocks until its error is received, or until the
+	// Ctx.Context of the evaluation which started it is done, so
+	// GoErrors should be buffered or drained.
+	GoErrors chan<- error
+
+	// The panic which the builtin recover recovers, set while a function
+	// called directly by a deferred call runs
+	recovering *panicState
+
+	// The functions declared in the Env by EvalDecl, mapped to their
+	// *declaredFunc. Scopes share the map of the Env they are made from,
+	// so it is collected with them. Nil until the first declaration.
+	declaredFuncs *sync.Map
+}
+
+// Declaration is the source a name of an Env was declared in, and the
+// span of the name within it
+type Declaration struct {
+	Source     string
+	Start, End Position
+}
+
+// envLock guards the maps of every Env. Goroutines started by go
+// statements copy the Env of the function they call, while the
+// statements declaring top level names may still be running.
+var envLock sync.RWMutex
+
+// newScope returns an Env for a nested scope of env. Names declared in
+// the new scope shadow, but do not modify, those of env. Packages and
+// generic functions are shared.
+func newScope(env *Env) *Env {
+	envLock.RLock()
+	defer envLock.RUnlock()
+
+	scope := *env
+	scope.Declared = nil
+	scope.Vars = make(map[string] reflect.Value, len(env.Vars))
+	scope.Consts = make(map[string] reflect.Value, len(env.Consts))
+	scope.Funcs = make(map[string] reflect.Value, len(env.Funcs))
+	scope.Types = make(map[string] reflect.Type, len(env.Types))
+	for name, v := range env.Vars {
+		scope.Vars[name] = v
+	}
+	for name, v := range env.Consts {
+		scope.Consts[name] = v
+	}
+	for name, v := range env.Funcs {
+		scope.Funcs[name] = v
+	}
+	for name, t := range env.Types {
+		scope.Types[name] = t
+	}
+	return &scope
 }
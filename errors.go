@@ -4,13 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"go/ast"
 	"go/token"
+	"go/types"
 )
 
 var (
 	ErrArrayKey = errors.New("array index must be non-negative integer constant")
+
+	// Evaluation was interrupted by Ctx.Context being canceled, or by
+	// its deadline passing
+	ErrCanceled         = errors.New("evaluation canceled")
+	ErrDeadlineExceeded = errors.New("evaluation deadline exceeded")
+
+	// A nil pointer was dereferenced, as by selecting a field through a
+	// nil embedded pointer
+	ErrNilDereference = ErrPanic{errors.New("runtime error: invalid memory address or nil pointer dereference")}
+
+	// An element was assigned to a nil map
+	ErrNilMapAssignment = ErrPanic{errors.New("assignment to entry in nil map")}
 )
 
 type ErrBadBasicLit struct {
@@ -39,8 +54,9 @@ type ErrInvalidOperands struct {
 }
 
 type ErrBadFunArgument struct {
-	fun reflect.Value
-	index int
+	ErrorContext
+	fun   ast.Expr
+	to    reflect.Type
 	value reflect.Value
 }
 
@@ -55,7 +71,12 @@ type ErrBadBuiltinArgument struct {
 
 // TODO remove when checker complete
 type ErrWrongNumberOfArgsOld struct {
+	ErrorContext
 	fun reflect.Value
+
+	// numIn is the number of parameters of fun, not counting the Ctx
+	// and typing flags of a builtin
+	numIn   int
 	numArgs int
 }
 
@@ -85,6 +106,10 @@ type ErrInvalidIndex struct {
 	ErrorContext
 	indexValue reflect.Value
 	containerType reflect.Type
+
+	// bound describes what a constant index is out of the bounds of,
+	// as "3-element array", or is empty if the index is negative
+	bound string
 }
 
 type ErrDivideByZero struct {
@@ -130,9 +155,352 @@ type ErrUntypedNil struct {
 	ErrorContext
 }
 
+type ErrMissingConstValue struct {
+	ErrorContext
+}
+
+type ErrExtraConstValue struct {
+	ErrorContext
+}
+
+type ErrNotConstant struct {
+	ErrorContext
+}
+
+type ErrBadAssignment struct {
+	ErrorContext
+	from reflect.Type
+	to reflect.Type
+	context string
+}
+
+type ErrInvalidShiftCount struct {
+	ErrorContext
+	count *ConstNumber
+}
+
+type ErrInvalidShiftOperand struct {
+	ErrorContext
+	t reflect.Type
+}
+
+type ErrInvalidArrayBound struct {
+	ErrorContext
+}
+
+type ErrInvalidMapKey struct {
+	ErrorContext
+	t reflect.Type
+}
+
+// ErrUnsupportedType is returned for valid go types that reflect cannot
+// construct
+type ErrUnsupportedType struct {
+	ErrorContext
+	reason string
+}
+
+type ErrRecursiveType struct {
+	ErrorContext
+	name string
+}
+
+type ErrMethodDecl struct {
+	ErrorContext
+}
+
+// ErrPanic is a panic raised while evaluating, either by the builtin
+// panic or by native code. Value is the argument to panic.
+type ErrPanic struct {
+	Value interface{}
+}
+
+// ErrBadDeferCall is returned for a go or defer statement which does not
+// call a function
+type ErrBadDeferCall struct {
+	ErrorContext
+	keyword string
+}
+
+type ErrMissingFuncBody struct {
+	ErrorContext
+}
+
+type ErrAssignCount struct {
+	ErrorContext
+	vars, values int
+}
+
+type ErrNoNewVariables struct {
+	ErrorContext
+}
+
+type ErrNonName struct {
+	ErrorContext
+}
+
+type ErrCannotAssign struct {
+	ErrorContext
+}
+
+type ErrUnusedExpr struct {
+	ErrorContext
+}
+
+type ErrNonBoolCondition struct {
+	ErrorContext
+	context string
+}
+
+// ErrMisplacedBranch is returned for a break, continue or fallthrough
+// statement with no valid target
+type ErrMisplacedBranch struct {
+	ErrorContext
+}
+
+type ErrUndefinedLabel struct {
+	ErrorContext
+}
+
+type ErrMissingReturn struct {
+	ErrorContext
+}
+
+type ErrWrongNumberOfReturns struct {
+	ErrorContext
+	tooMany bool
+}
+
+type ErrReturnOutsideFunction struct {
+	ErrorContext
+}
+
+// ErrInvalidChanOp is returned for a send or receive on a value which
+// is not a channel, or a channel of the wrong direction
+type ErrInvalidChanOp struct {
+	ErrorContext
+	t    reflect.Type
+	send bool
+}
+
+// ErrTimeout is returned when a channel operation blocks past the
+// Ctx.Timeout of the evaluation
+type ErrTimeout struct {
+	ErrorContext
+	timeout time.Duration
+}
+
+// ErrNotInstantiated is returned for an instantiation of a generic
+// function which the host has neither registered nor built with the
+// Factory of the Generic
+type ErrNotInstantiated struct {
+	ErrorContext
+	generic *Generic
+	targs   []reflect.Type
+}
+
+// ErrBadInstance is returned when the Factory of a Generic fails, or
+// returns a function of the wrong type
+type ErrBadInstance struct {
+	ErrorContext
+	generic *Generic
+	targs   []reflect.Type
+	err     error
+}
+
+type ErrTypeArgCount struct {
+	ErrorContext
+	generic *Generic
+	n       int
+}
+
+type ErrUninstantiatedGeneric struct {
+	ErrorContext
+	generic *Generic
+}
+
+type ErrNotGeneric struct {
+	ErrorContext
+}
+
+type ErrCannotInfer struct {
+	ErrorContext
+	generic *Generic
+	param   string
+}
+
+// ErrInferMismatch is returned for an argument to a generic function
+// whose type does not match that of its parameter
+type ErrInferMismatch struct {
+	ErrorContext
+	generic *Generic
+	t       reflect.Type
+	param   ast.Expr
+}
+
+// ErrLimitExceeded is returned when evaluation exceeds one of the Limits
+// of its Ctx. Limit is the name of the field of Limits, and Max its
+// value.
+type ErrLimitExceeded struct {
+	Limit string
+	Max   int64
+}
+
+type ErrUnknownField struct {
+	ErrorContext
+	t    reflect.Type
+	name string
+}
+
+type ErrUnexportedField struct {
+	ErrorContext
+	t    reflect.Type
+	name string
+}
+
+type ErrUnexportedSelector struct {
+	ErrorContext
+	name string
+}
+
+type ErrPromotedField struct {
+	ErrorContext
+	t    reflect.Type
+	path string
+}
+
+type ErrDuplicateField struct {
+	ErrorContext
+	name  string
+	first ast.Node
+}
+
+type ErrInvalidFieldName struct {
+	ErrorContext
+}
+
+type ErrMixedStructLit struct {
+	ErrorContext
+}
+
+type ErrCannotSlice struct {
+	ErrorContext
+	t reflect.Type
+}
+
+type ErrUnaddressableSlice struct {
+	ErrorContext
+}
+
+type ErrUnaddressable struct {
+	ErrorContext
+}
+
+type ErrNotExpression struct {
+	ErrorContext
+}
+
+type ErrInvalidExpr struct {
+	ErrorContext
+}
+
+type ErrThreeIndexString struct {
+	ErrorContext
+}
+
+type ErrUncallable struct {
+	ErrorContext
+	t reflect.Type
+}
+
+type ErrInvertedSliceIndices struct {
+	ErrorContext
+	low, high ast.Expr
+}
+
+type ErrDuplicateKey struct {
+	ErrorContext
+	t     reflect.Type
+	key   string
+	first ast.Node
+}
+
+type ErrMissingMapKey struct {
+	ErrorContext
+}
+
+type ErrStructLitCount struct {
+	ErrorContext
+	t   reflect.Type
+	few bool
+}
+
+// ErrDenied is returned for a use of a package, function, method or
+// variable which the Policy of the Ctx does not allow, or a write through
+// an unexported field which Ctx.Unexported only allows to be read
+type ErrDenied struct {
+	ErrorContext
+	What string
+}
+
+type ErrInvalidTypeAssert struct {
+	ErrorContext
+	t reflect.Type
+}
+
+type ErrImpossibleTypeAssert struct {
+	ErrorContext
+	t      reflect.Type
+	iface  reflect.Type
+	method string
+}
+
+type ErrOutsideTypeSwitch struct {
+	ErrorContext
+}
+
+type ErrInvalidTypeSwitch struct {
+	ErrorContext
+	t reflect.Type
+}
+
+// WarnDefinedType is the warning that a defined type, type T U, is
+// represented by the underlying type of U, as reflect cannot create named
+// types. T has no methods, and is identical to the underlying type.
+type WarnDefinedType struct {
+	ErrorContext
+	name string
+	t    reflect.Type
+}
+
+type ErrInvalidNilOperands struct {
+	ErrorContext
+	t  reflect.Type
+	op token.Token
+}
+
+type ErrNoMethod struct {
+	ErrorContext
+	t   reflect.Type
+	sel string
+}
+
+// TypeAssertionError is the value of the panic of a failed type
+// assertion, as runtime.TypeAssertionError is in compiled code
+type TypeAssertionError struct {
+	Interface reflect.Type // static type of x
+	Concrete  reflect.Type // dynamic type of x, or nil
+	Asserted  reflect.Type
+	Missing   string // a method of Asserted which Concrete lacks
+}
+
+// ErrorContext is the node an error occurred at, and the source it was
+// parsed from. Fset and Base are those of the Ctx.
 type ErrorContext struct {
 	Input string
 	ast.Node
+	Fset *token.FileSet
+	Base int
 }
 
 func (err ErrBadBasicLit) Error() string {
@@ -152,7 +520,11 @@ func (err ErrMismatchedTypes) Error() string {
 }
 
 func (err ErrBadFunArgument) Error() string {
-	return fmt.Sprintf("invalid type (%v) for argument %d of %v", err.value.Type(), err.index, err.fun)
+	if !err.value.IsValid() {
+		return fmt.Sprintf("cannot use nil as type %v in argument to %v", err.to, err.fun.(Expr))
+	}
+	return fmt.Sprintf("cannot use %s (type %v) as type %v in argument to %v",
+		err.Source(), err.value.Type(), err.to, err.fun.(Expr))
 }
 
 func (err ErrBadComplexArguments) Error() string {
@@ -164,11 +536,14 @@ func (err ErrBadBuiltinArgument) Error() string {
 }
 
 func (err ErrWrongNumberOfArgsOld) Error() string {
-	expected := err.fun.Type().NumIn()
-	if err.numArgs < expected {
-		return fmt.Sprintf("not enough args (%d) to call %v (%d)", err.numArgs, err.fun, expected)
+	call := err.Node.(*CallExpr)
+	ftype := err.fun.Type()
+	if call.Ellipsis.IsValid() && !ftype.IsVariadic() {
+		return fmt.Sprintf("cannot use ... in call to non-variadic %v", call.Fun.(Expr))
+	} else if err.numArgs < err.numIn {
+		return fmt.Sprintf("not enough arguments in call to %v", call.Fun.(Expr))
 	} else {
-		return fmt.Sprintf("too many args (%d) to call %v (%d)", err.numArgs, err.fun, expected)
+		return fmt.Sprintf("too many arguments in call to %v", call.Fun.(Expr))
 	}
 }
 
@@ -191,22 +566,12 @@ func (err ErrInvalidIndex) Error() string {
 		panic("go-interactive error: ErrInvalidIndex requires indexable err.containerType")
 	}
 
-	switch err.indexValue.Type().Kind() {
-	case reflect.Int:
-		var reason string
-		i := int(err.indexValue.Int())
-		if err.containerType.Kind() == reflect.Array && i >= err.containerType.Len() {
-			reason = fmt.Sprintf("out of bounds for %d-element array", err.containerType.Len())
-
-		// TODO string errors for constant strings constant values are implemented
-		// out of bounds for 3-byte string
-		// } else if err.containerType.Kind == reflect.String && i > err.containerType.Len() {
-		} else {
-			reason = "index must be non-negative"
-		}
-		return fmt.Sprintf("invalid %s index %s (%s)", ct, err.Source(), reason)
-	default:
+	if !err.indexValue.IsValid() || err.indexValue.Kind() != reflect.Int {
 		return fmt.Sprintf("non-integer %s index %s", ct, err.Source())
+	} else if err.bound != "" {
+		return fmt.Sprintf("invalid %s index %s (out of bounds for %s)", ct, err.Source(), err.bound)
+	} else {
+		return fmt.Sprintf("invalid %s index %s (index must be non-negative)", ct, err.Source())
 	}
 }
 
@@ -243,6 +608,9 @@ func (err ErrInvalidUnaryOperation) Error() string {
 	unary := err.ErrorContext.Node.(*UnaryExpr)
 	x := unary.X.(Expr)
 	t := x.KnownType()[0]
+	if _, ok := t.(ConstType); !ok {
+		return fmt.Sprintf("invalid operation: %v (operator %v not defined on %v)", unary, unary.Op, t)
+	}
 	switch t {
 	case ConstNil:
 		return fmt.Sprintf("invalid operation: %v nil", unary.Op)
@@ -344,12 +712,363 @@ func (ErrUntypedNil) Error() string {
 	return "use of untyped nil"
 }
 
+func (ErrMissingConstValue) Error() string {
+	return "missing value in const declaration"
+}
+
+func (ErrExtraConstValue) Error() string {
+	return "extra expression in const declaration"
+}
+
+func (err ErrNotConstant) Error() string {
+	return fmt.Sprintf("const initializer %v is not a constant", err.Node.(Expr))
+}
+
+func (err ErrBadAssignment) Error() string {
+	return fmt.Sprintf("cannot use %v (type %v) as type %v in %s", err.Node.(Expr), err.from, err.to, err.context)
+}
+
+func (err ErrInvalidShiftCount) Error() string {
+	if err.count == nil || !err.count.Value.IsInteger() {
+		return fmt.Sprintf("invalid shift count %v", err.Node.(Expr))
+	} else if err.count.Value.Re.Sign() < 0 {
+		return fmt.Sprintf("invalid negative shift count: %v", err.count)
+	} else {
+		return fmt.Sprintf("shift count too large: %v", err.count)
+	}
+}
+
+func (err ErrInvalidShiftOperand) Error() string {
+	return fmt.Sprintf("invalid operation: %v (shift of type %v)", err.Node.(Expr), err.t)
+}
+
+func (err ErrInvalidArrayBound) Error() string {
+	return fmt.Sprintf("invalid array bound %s", err.Source())
+}
+
+func (err ErrInvalidMapKey) Error() string {
+	return fmt.Sprintf("invalid map key type %v", err.t)
+}
+
+func (err ErrUnsupportedType) Error() string {
+	return fmt.Sprintf("unsupported type %s: %s", err.Source(), err.reason)
+}
+
+func (err ErrRecursiveType) Error() string {
+	return fmt.Sprintf("invalid recursive type %s: reflect cannot create recursive types", err.name)
+}
+
+func (err ErrMethodDecl) Error() string {
+	decl := err.Node.(*ast.FuncDecl)
+	recv := err.at(decl.Recv.List[0].Type).Source()
+	if strings.HasPrefix(recv, "*") {
+		recv = "(" + recv + ")"
+	}
+	return fmt.Sprintf("cannot declare method %s.%s: reflect cannot create types with methods",
+		recv, decl.Name.Name)
+}
+
+func (err ErrPanic) Error() string {
+	if e, ok := err.Value.(error); ok {
+		return "panic: " + e.Error()
+	}
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+func (err ErrBadDeferCall) Error() string {
+	return err.keyword + " requires function call, not conversion"
+}
+
+func (err ErrMissingFuncBody) Error() string {
+	return fmt.Sprintf("missing function body for %s", err.Node.(*ast.FuncDecl).Name.Name)
+}
+
+func (err ErrAssignCount) Error() string {
+	return fmt.Sprintf("assignment mismatch: %d variable%s but %d value%s",
+		err.vars, plural(err.vars), err.values, plural(err.values))
+}
+
+func (ErrNoNewVariables) Error() string {
+	return "no new variables on left side of :="
+}
+
+func (err ErrNonName) Error() string {
+	return fmt.Sprintf("non-name %s on left side of :=", err.Source())
+}
+
+func (err ErrCannotAssign) Error() string {
+	return fmt.Sprintf("cannot assign to %s", err.Source())
+}
+
+func (err ErrUnusedExpr) Error() string {
+	return fmt.Sprintf("%s evaluated but not used", err.Source())
+}
+
+func (err ErrNonBoolCondition) Error() string {
+	return fmt.Sprintf("non-bool %s used as %s condition", err.Source(), err.context)
+}
+
+func (err ErrMisplacedBranch) Error() string {
+	branch := err.Node.(*ast.BranchStmt)
+	switch {
+	case branch.Label != nil:
+		return fmt.Sprintf("invalid %s label %s", branch.Tok, branch.Label.Name)
+	case branch.Tok == token.BREAK:
+		return "break is not in a loop, switch, or select"
+	case branch.Tok == token.CONTINUE:
+		return "continue is not in a loop"
+	default:
+		return "fallthrough statement out of place"
+	}
+}
+
+func (err ErrUndefinedLabel) Error() string {
+	return fmt.Sprintf("label %s not defined", err.Node.(*ast.BranchStmt).Label.Name)
+}
+
+func (ErrMissingReturn) Error() string {
+	return "missing return at end of function"
+}
+
+func (err ErrWrongNumberOfReturns) Error() string {
+	if err.tooMany {
+		return "too many arguments to return"
+	}
+	return "not enough arguments to return"
+}
+
+func (ErrReturnOutsideFunction) Error() string {
+	return "return statement outside function"
+}
+
+func (err ErrInvalidChanOp) Error() string {
+	op, to := "receive from", "send-only"
+	if err.send {
+		op, to = "send to", "receive-only"
+	}
+	if err.t.Kind() != reflect.Chan {
+		to = "non-channel"
+	} else {
+		to += " channel"
+	}
+	return fmt.Sprintf("invalid operation: cannot %s %s %s (type %v)", op, to, err.Source(), err.t)
+}
+
+func (err ErrTimeout) Error() string {
+	return fmt.Sprintf("timed out after %v waiting on channel operation", err.timeout)
+}
+
+func (err ErrNotInstantiated) Error() string {
+	return fmt.Sprintf("%s is not instantiated by the host", err.generic.instanceName(err.targs))
+}
+
+func (err ErrBadInstance) Error() string {
+	return fmt.Sprintf("cannot instantiate %s: %v", err.generic.instanceName(err.targs), err.err)
+}
+
+func (err ErrTypeArgCount) Error() string {
+	if want := len(err.generic.typeParams); err.n < want {
+		return fmt.Sprintf("not enough type arguments for %s: have %d, want %d", err.generic.Name, err.n, want)
+	} else {
+		return fmt.Sprintf("too many type arguments for %s: have %d, want %d", err.generic.Name, err.n, want)
+	}
+}
+
+func (err ErrUninstantiatedGeneric) Error() string {
+	return fmt.Sprintf("cannot use generic function %s without instantiation", err.generic.Name)
+}
+
+func (err ErrNotGeneric) Error() string {
+	return fmt.Sprintf("invalid operation: %s is not a generic function", err.Source())
+}
+
+func (err ErrCannotInfer) Error() string {
+	return fmt.Sprintf("in call to %s, cannot infer %s", err.generic.Name, err.param)
+}
+
+func (err ErrInferMismatch) Error() string {
+	return fmt.Sprintf("in call to %s, type %v of %s does not match %s",
+		err.generic.Name, err.t, err.Source(), types.ExprString(err.param))
+}
+
+func (err ErrLimitExceeded) Error() string {
+	return fmt.Sprintf("evaluation limit exceeded: %s (%d)", err.Limit, err.Max)
+}
+
+func (err ErrUnknownField) Error() string {
+	return fmt.Sprintf("unknown field %s in struct literal of type %v", err.name, err.t)
+}
+
+func (err ErrUnexportedField) Error() string {
+	return fmt.Sprintf("cannot refer to unexported field %s in struct literal of type %v", err.name, err.t)
+}
+
+func (err ErrUnexportedSelector) Error() string {
+	return fmt.Sprintf("%s undefined (cannot refer to unexported field %s)", err.Source(), err.name)
+}
+
+func (err ErrPromotedField) Error() string {
+	return fmt.Sprintf("cannot use promoted field %s in struct literal of type %v", err.path, err.t)
+}
+
+func (err ErrDuplicateField) Error() string {
+	return fmt.Sprintf("duplicate field name %s in struct literal", err.name)
+}
+
+func (err ErrInvalidFieldName) Error() string {
+	return fmt.Sprintf("invalid field name %s in struct literal", err.Source())
+}
+
+func (err ErrMixedStructLit) Error() string {
+	return "mixture of field:value and value elements in struct literal"
+}
+
+func (err ErrCannotSlice) Error() string {
+	return fmt.Sprintf("cannot slice %s (type %v)", err.Source(), err.t)
+}
+
+func (err ErrUnaddressableSlice) Error() string {
+	return fmt.Sprintf("invalid operation %s (slice of unaddressable value)", err.Source())
+}
+
+func (err ErrUnaddressable) Error() string {
+	return fmt.Sprintf("cannot take the address of %s", err.Source())
+}
+
+func (err ErrNotExpression) Error() string {
+	return fmt.Sprintf("type %s is not an expression", err.Source())
+}
+
+func (err ErrInvalidExpr) Error() string {
+	return fmt.Sprintf("invalid expression %s", err.Source())
+}
+
+func (err ErrThreeIndexString) Error() string {
+	return fmt.Sprintf("invalid operation %s (3-index slice of string)", err.Source())
+}
+
+func (err ErrUncallable) Error() string {
+	return fmt.Sprintf("cannot call non-function %s (type %v)", err.Source(), err.t)
+}
+
+func (err ErrInvertedSliceIndices) Error() string {
+	return fmt.Sprintf("invalid slice index: %v > %v", err.low.(Expr), err.high.(Expr))
+}
+
+func (err ErrDuplicateKey) Error() string {
+	if err.t.Kind() == reflect.Map {
+		return fmt.Sprintf("duplicate key %s in map literal", err.key)
+	}
+	return fmt.Sprintf("duplicate index in array literal: %s", err.key)
+}
+
+func (err ErrMissingMapKey) Error() string {
+	return "missing key in map literal"
+}
+
+func (err ErrStructLitCount) Error() string {
+	if err.few {
+		return fmt.Sprintf("too few values in struct literal of type %v", err.t)
+	}
+	return fmt.Sprintf("too many values in struct literal of type %v", err.t)
+}
+
+func (err ErrDenied) Error() string {
+	return fmt.Sprintf("%s denied by policy", err.What)
+}
+
+func (err ErrInvalidTypeAssert) Error() string {
+	return fmt.Sprintf("invalid type assertion: %s (non-interface type %v on left)", err.Source(), err.t)
+}
+
+func (err ErrImpossibleTypeAssert) Error() string {
+	return fmt.Sprintf("impossible type assertion: %v does not implement %v (missing %s method)",
+		err.t, err.iface, err.method)
+}
+
+func (err ErrOutsideTypeSwitch) Error() string {
+	return "use of .(type) outside type switch"
+}
+
+func (err ErrInvalidTypeSwitch) Error() string {
+	return fmt.Sprintf("%s (type %v) is not an interface", err.Source(), err.t)
+}
+
+func (err WarnDefinedType) Error() string {
+	return fmt.Sprintf("type %s is represented by its underlying type %v, without methods or type identity", err.name, err.t)
+}
+
+func (err ErrInvalidNilOperands) Error() string {
+	return fmt.Sprintf("invalid operation: %s (mismatched types %v and untyped nil)", err.Source(), err.t)
+}
+
+func (err ErrNoMethod) Error() string {
+	return fmt.Sprintf("%s undefined (type %v has no field or method %s)", err.Source(), err.t, err.sel)
+}
+
+func (err *TypeAssertionError) Error() string {
+	inter := "interface"
+	if err.Interface != nil {
+		inter = err.Interface.String()
+	}
+	if err.Concrete == nil {
+		return fmt.Sprintf("interface conversion: %s is nil, not %v", inter, err.Asserted)
+	} else if err.Missing != "" {
+		return fmt.Sprintf("interface conversion: %v is not %v: missing method %s",
+			err.Concrete, err.Asserted, err.Missing)
+	}
+	return fmt.Sprintf("interface conversion: %s is %v, not %v", inter, err.Concrete, err.Asserted)
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
 func at(ctx *Ctx, expr ast.Node) ErrorContext {
-	return ErrorContext{ctx.Input, expr}
+	return ErrorContext{ctx.Input, expr, ctx.Fset, ctx.Base}
+}
+
+// at returns the context of another node of the same source
+func (errCtx ErrorContext) at(node ast.Node) ErrorContext {
+	errCtx.Node = node
+	return errCtx
 }
 
+// Source returns the text of the node. If the node is not within Input,
+// as when Input is not the source the node was parsed from, the node is
+// printed instead.
 func (errCtx ErrorContext) Source() string {
-	return errCtx.Input[errCtx.Node.Pos()-1:errCtx.Node.End()-1]
+	if errCtx.Node == nil {
+		return ""
+	}
+	start, ok := errCtx.offset(errCtx.Node.Pos())
+	end, endOk := errCtx.offset(errCtx.Node.End())
+	if ok && endOk && start <= end {
+		return errCtx.Input[start:end]
+	} else if expr, ok := errCtx.Node.(ast.Expr); ok {
+		return types.ExprString(expr)
+	}
+	return ""
+}
+
+// offset returns the offset of pos within Input, if it is within it
+func (errCtx ErrorContext) offset(pos token.Pos) (int, bool) {
+	if !pos.IsValid() {
+		return 0, false
+	}
+	offset := int(pos) - 1
+	if errCtx.Fset != nil {
+		file := errCtx.Fset.File(pos)
+		if file == nil {
+			return 0, false
+		}
+		offset = file.Offset(pos)
+	}
+	offset -= errCtx.Base
+	return offset, offset >= 0 && offset <= len(errCtx.Input)
 }
 
 func drop0i(i interface{}) interface{} {
@@ -34,7 +34,7 @@ func makeEnv() *eval.Env {
 //   3. run eval.EvalExpr (0xfaded/eval)
 func ExpectResult(expr string, expected interface{}) {
 	env := makeEnv() // Create evaluation environment
-	ctx := &eval.Ctx{expr}
+	ctx := &eval.Ctx{Input: expr}
 	if e, err := parser.ParseExpr(expr); err != nil {
 		fmt.Printf("Failed to parse expression '%s' (%v)\n", expr, err)
 		return
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+
+	"go/ast"
 )
 
 // EvalExpr is the main function to call to evaluate an ast-parsed
@@ -13,6 +15,16 @@ import (
 // subverted somewhat by supplying callback hooks routines which
 // access variables and by supplying user-defined conversion routines.
 func EvalExpr(ctx *Ctx, expr Expr, env *Env) (*[]reflect.Value, bool, error) {
+	ctx = ctx.start()
+	if err := ctx.step(); err != nil {
+		return nil, false, err
+	}
+
+	// The checker has already folded constant expressions
+	if expr.IsConst() {
+		return evalConstExpr(ctx, expr)
+	}
+
 	switch node := expr.(type) {
 	case *Ident:
 		v, typed, err := evalIdentExprCallback(ctx, node, env)
@@ -26,6 +38,8 @@ func EvalExpr(ctx *Ctx, expr Expr, env *Env) (*[]reflect.Value, bool, error) {
 		v, typed, err := evalBasicLit(ctx, node)
 		return &[]reflect.Value{v}, typed, err
 	case *FuncLit:
+		// Closures see the variables of env, but not later declarations
+		return &[]reflect.Value{makeFunc(ctx, node.fn, newScope(env))}, true, nil
 	case *CompositeLit:
 		v, typed, err := evalCompositeLit(ctx, node, env)
 		if v == nil {
@@ -40,6 +54,8 @@ func EvalExpr(ctx *Ctx, expr Expr, env *Env) (*[]reflect.Value, bool, error) {
 			return nil, typed, err
 		}
 		return &[]reflect.Value{*v}, typed, err
+	case *IndexListExpr:
+		return evalIndexListExpr(ctx, node)
 	case *IndexExpr:
 		v, typed, err := evalIndexExpr(ctx, node, env)
 		if v == nil {
@@ -47,7 +63,14 @@ func EvalExpr(ctx *Ctx, expr Expr, env *Env) (*[]reflect.Value, bool, error) {
 		}
 		return &[]reflect.Value{*v}, typed, err
 	case *SliceExpr:
+		v, typed, err := evalSliceExpr(ctx, node, env)
+		if v == nil {
+			return nil, typed, err
+		}
+		return &[]reflect.Value{*v}, typed, err
 	case *TypeAssertExpr:
+		v, err := evalTypeAssertExpr(ctx, node, env)
+		return &[]reflect.Value{v}, true, err
 	case *CallExpr:
 		return evalCallExpr(ctx, node, env)
 	case *StarExpr:
@@ -64,12 +87,50 @@ func EvalExpr(ctx *Ctx, expr Expr, env *Env) (*[]reflect.Value, bool, error) {
 		return &[]reflect.Value{v}, typed, err
 	case *KeyValueExpr:
 	default:
-		panic(node)
-		return nil , false, errors.New("undefined type")
+		return nil, false, ErrInvalidExpr{at(ctx, node)}
 	}
 	return &[]reflect.Value{reflect.ValueOf("Alice")}, true, nil
 }
 
+// evalConstExpr returns the value of an expression the checker found to
+// be constant. Untyped constants are returned using the same
+// representation as evalBasicLit.
+func evalConstExpr(ctx *Ctx, expr Expr) (*[]reflect.Value, bool, error) {
+	c := expr.Const()
+	switch v := c.Interface().(type) {
+	case UntypedNil:
+		return nil, false, nil
+	case *ConstNumber:
+		r, err := evalConstNumber(ctx, v, expr)
+		return &[]reflect.Value{r}, false, err
+	}
+	_, untyped := expr.KnownType()[0].(ConstType)
+	return &[]reflect.Value{c}, !untyped, nil
+}
+
+func evalConstNumber(ctx *Ctx, n *ConstNumber, expr ast.Expr) (reflect.Value, error) {
+	switch n.Type.(type) {
+	case ConstIntType:
+		i, _, overflow := n.Value.Int(64)
+		if overflow {
+			return reflect.Value{}, ErrOverflowedConstant{at(ctx, expr), n.Type, intType, n}
+		}
+		return reflect.ValueOf(i), nil
+	case ConstRuneType:
+		r, _, overflow := n.Value.Int(32)
+		if overflow {
+			return reflect.Value{}, ErrOverflowedConstant{at(ctx, expr), n.Type, RuneType, n}
+		}
+		return reflect.ValueOf(rune(r)), nil
+	case ConstFloatType:
+		f, _, _ := n.Value.Float64()
+		return reflect.ValueOf(f), nil
+	default:
+		c, _ := n.Value.Complex128()
+		return reflect.ValueOf(c), nil
+	}
+}
+
 func evalType(ctx *Ctx, expr Expr, env *Env) (reflect.Type, error) {
 	switch node := expr.(type) {
 	case *Ident:
@@ -80,18 +141,34 @@ func evalType(ctx *Ctx, expr Expr, env *Env) (reflect.Type, error) {
 		} else {
 			return t, errors.New("undefined type: " + node.Name)
 		}
+	case *ParenExpr:
+		if x, ok := node.X.(Expr); ok {
+			return evalType(ctx, x, env)
+		}
+		return nil, errors.New("not a type")
+	case *SelectorExpr:
+		return evalSelectorType(ctx, node, env)
+	case *StarExpr:
+		if x, ok := node.X.(Expr); ok {
+			if elem, err := evalType(ctx, x, env); err != nil {
+				return nil, err
+			} else {
+				return reflect.PtrTo(unhackType(elem)), nil
+			}
+		}
+		return nil, errors.New("not a type")
 	case *ArrayType:
-		return nil, errors.New("array types not implemented")
+		return evalArrayType(ctx, node, env)
 	case *StructType:
-		return nil, errors.New("struct types not implemented")
+		return evalStructType(ctx, node, env)
 	case *FuncType:
-		return nil, errors.New("func types not implemented")
+		return evalFuncType(ctx, node, env)
 	case *InterfaceType:
-		return nil, errors.New("interface types not implemented")
+		return evalInterfaceType(ctx, node, env)
 	case *MapType:
-		return nil, errors.New("map types not implemented")
+		return evalMapType(ctx, node, env)
 	case *ChanType:
-		return nil, errors.New("chan types not implemented")
+		return evalChanType(ctx, node, env)
 	default:
 		return nil, errors.New(fmt.Sprintf("Type: Bad type (%+v)", node))
 	}
@@ -0,0 +1,306 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test T{1, "b"}, printed by go/types in
+// cannot convert T{…} (value of struct type eval.T) to type struct{noConversion int}
+func TestExprStringCompositeLit(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectString(t, `T{1, "b"}`, env, `T{…}`)
+}
+
+// Test T{}, printed by go/types in
+// cannot convert T{} (value of struct type eval.T) to type struct{noConversion int}
+func TestExprStringEmptyCompositeLit(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectString(t, `T{}`, env, `T{}`)
+}
+
+// Test []int{1}, printed by go/types in
+// cannot convert []int{…} (value of type []int) to type struct{noConversion int}
+func TestExprStringSliceLit(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `[]int{1}`, env, `[]int{…}`)
+}
+
+// Test [3]int{}, printed by go/types in
+// cannot convert [3]int{} (value of type [3]int) to type struct{noConversion int}
+func TestExprStringArrayLit(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `[3]int{}`, env, `[3]int{}`)
+}
+
+// Test map[string]int{}, printed by go/types in
+// cannot convert map[string]int{} (value of type map[string]int) to type struct{noConversion int}
+func TestExprStringMapLit(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `map[string]int{}`, env, `map[string]int{}`)
+}
+
+// Test []*T{}, printed by go/types in
+// cannot convert []*T{} (value of type []*eval.T) to type struct{noConversion int}
+func TestExprStringPtrSliceLit(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectString(t, `[]*T{}`, env, `[]*T{}`)
+}
+
+// Test &T{}, printed by go/types in
+// cannot convert &T{} (value of type *eval.T) to type struct{noConversion int}
+func TestExprStringAddressOfLit(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+
+	expectString(t, `&T{}`, env, `&T{}`)
+}
+
+// Test func(a int, b string) (int, error) { return 0, nil }, printed by go/types in
+// cannot convert (func(a int, b string) (int, error) literal) (value of type func(a int, b string) (int, error)) to type struct{noConversion int}
+func TestExprStringFuncLit(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `func(a int, b string) (int, error) { return 0, nil }`, env, `func(a int, b string) (int, error) {…}`)
+}
+
+// Test func() {}, printed by go/types in
+// cannot convert (func() literal) (value of type func()) to type struct{noConversion int}
+func TestExprStringEmptyFuncLit(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `func() {}`, env, `func() {…}`)
+}
+
+// Test s[1:2], printed by go/types in
+// cannot convert s[1:2] (value of type []int) to type struct{noConversion int}
+func TestExprStringSlice(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `s[1:2]`, env, `s[1:2]`)
+}
+
+// Test s[1:2:3], printed by go/types in
+// cannot convert s[1:2:3] (value of type []int) to type struct{noConversion int}
+func TestExprStringSlice3(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `s[1:2:3]`, env, `s[1:2:3]`)
+}
+
+// Test s[:], printed by go/types in
+// cannot convert s[:] (value of type []int) to type struct{noConversion int}
+func TestExprStringSliceAll(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `s[:]`, env, `s[:]`)
+}
+
+// Test s[1], printed by go/types in
+// cannot convert s[1] (variable of type int) to type struct{noConversion int}
+func TestExprStringIndex(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `s[1]`, env, `s[1]`)
+}
+
+// Test s[len(s)-1], printed by go/types in
+// cannot convert s[len(s) - 1] (variable of type int) to type struct{noConversion int}
+func TestExprStringIndexBinary(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `s[len(s)-1]`, env, `s[len(s) - 1]`)
+}
+
+// Test m["a"], printed by go/types in
+// cannot convert m["a"] (map index expression of type int) to type struct{noConversion int}
+func TestExprStringMapIndex(t *testing.T) {
+	env := makeEnv()
+	var m map[string]int = nil
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectString(t, `m["a"]`, env, `m["a"]`)
+}
+
+// Test x.(string), printed by go/types in
+// cannot convert x.(string) (comma, ok expression of type string) to type struct{noConversion int}
+func TestExprStringTypeAssert(t *testing.T) {
+	env := makeEnv()
+	var x interface{} = nil
+	env.Vars["x"] = reflect.ValueOf(&x)
+
+	expectString(t, `x.(string)`, env, `x.(string)`)
+}
+
+// Test p.A, printed by go/types in
+// cannot convert p.A (variable of type int) to type struct{noConversion int}
+func TestExprStringSelector(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var p *T = nil
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectString(t, `p.A`, env, `p.A`)
+}
+
+// Test *p, printed by go/types in
+// cannot convert *p (variable of struct type eval.T) to type struct{noConversion int}
+func TestExprStringStar(t *testing.T) {
+	env := makeEnv()
+	type T struct {
+		A int
+		B string
+	}
+	env.Types["T"] = reflect.TypeOf(T{})
+	var p *T = nil
+	env.Vars["p"] = reflect.ValueOf(&p)
+
+	expectString(t, `*p`, env, `*p`)
+}
+
+// Test <-ch, printed by go/types in
+// cannot convert <-ch (comma, ok expression of type int) to type struct{noConversion int}
+func TestExprStringReceive(t *testing.T) {
+	env := makeEnv()
+	var ch chan int = nil
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+
+	expectString(t, `<-ch`, env, `<-ch`)
+}
+
+// Test -len(s), printed by go/types in
+// cannot convert -len(s) (value of type int) to type struct{noConversion int}
+func TestExprStringNegate(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `-len(s)`, env, `-len(s)`)
+}
+
+// Test len(s), printed by go/types in
+// cannot convert len(s) (value of type int) to type struct{noConversion int}
+func TestExprStringBuiltin(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `len(s)`, env, `len(s)`)
+}
+
+// Test append(s, s...), printed by go/types in
+// cannot convert append(s, s...) (value of type []int) to type struct{noConversion int}
+func TestExprStringEllipsis(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `append(s, s...)`, env, `append(s, s...)`)
+}
+
+// Test (s), printed by go/types in
+// cannot convert (s) (variable of type []int) to type struct{noConversion int}
+func TestExprStringParen(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `(s)`, env, `s`)
+}
+
+// Test (1 + len(s)), printed by go/types in
+// cannot convert (1 + len(s)) (value of type int) to type struct{noConversion int}
+func TestExprStringParenBinary(t *testing.T) {
+	env := makeEnv()
+	var s []int = nil
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, `(1 + len(s))`, env, `(1 + len(s))`)
+}
+
+// Test (<-chan int)(nil), printed by go/types in
+// cannot convert (<-chan int)(nil) (value of type <-chan int) to type struct{noConversion int}
+func TestExprStringRecvChanConversion(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `(<-chan int)(nil)`, env, `(<-chan int)(nil)`)
+}
+
+// Test (chan<- int)(nil), printed by go/types in
+// cannot convert (chan<- int)(nil) (value of type chan<- int) to type struct{noConversion int}
+func TestExprStringSendChanConversion(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `(chan<- int)(nil)`, env, `(chan<- int)(nil)`)
+}
+
+// Test (chan int)(nil), printed by go/types in
+// cannot convert (chan int)(nil) (value of type chan int) to type struct{noConversion int}
+func TestExprStringChanConversion(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `(chan int)(nil)`, env, `(chan int)(nil)`)
+}
+
+// Test (func(int) string)(nil), printed by go/types in
+// cannot convert (func(int) string)(nil) (value of type func(int) string) to type struct{noConversion int}
+func TestExprStringFuncConversion(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `(func(int) string)(nil)`, env, `(func(int) string)(nil)`)
+}
+
+// Test (*struct{ A int; B, C string })(nil), printed by go/types in
+// cannot convert (*struct{A int; B, C string})(nil) (value of type *struct{A int; B string; C string}) to type struct{noConversion int}
+func TestExprStringStructPtrConversion(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `(*struct{ A int; B, C string })(nil)`, env, `(*struct{A int; B, C string})(nil)`)
+}
+
+// Test (interface{ M() })(nil), printed by go/types in
+// cannot convert (interface{M()})(nil) (value of type interface{M()}) to type struct{noConversion int}
+func TestExprStringInterfaceConversion(t *testing.T) {
+	env := makeEnv()
+
+	expectString(t, `(interface{ M() })(nil)`, env, `(interface{M()})(nil)`)
+}
@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+
+	"go/parser"
+)
+
+// The expected strings are printed by gc for the same expressions, in
+// errors such as "cannot convert s[1:2] (value of type []int) to type int"
+func expectString(t *testing.T, expr string, env *Env, expected string) {
+	ctx := &Ctx{Input: expr}
+	if e, err := parser.ParseExpr(expr); err != nil {
+		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
+	} else if aexpr, errs := CheckExpr(ctx, e, env); errs != nil {
+		t.Fatalf("Failed to check expression '%s' (%v)", expr, errs)
+	} else if s := aexpr.String(); s != expected {
+		t.Fatalf("Expression '%s' printed as `%s`, expected `%s`", expr, s, expected)
+	}
+}
+
+// go/types prints nested unary operators with their parentheses, as
+// -(-len(s)), so exprstring_gen_test.go cannot test them
+func TestExprStringNestedUnary(t *testing.T) {
+	var s []int
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&s)
+
+	expectString(t, "-(-len(s))", env, "- -len(s)")
+}
@@ -0,0 +1,249 @@
+package eval
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	"go/token"
+)
+
+// funcError carries an error out of an interpreted function. Functions
+// are created with reflect.MakeFunc, which leaves no way to return an
+// error other than panicking. The panic is recovered by
+// evalCallFunExpr, or by whichever interpreted call is nearest on the
+// stack when the function is called from native code.
+type funcError struct {
+	err error
+}
+
+// panicState is the error, if any, with which a function is exiting
+// while its deferred calls run
+type panicState struct {
+	err error
+}
+
+// deferred is a call made by a defer or go statement. The function and
+// arguments are evaluated by the statement, the call is made later.
+type deferred struct {
+	ctx    *Ctx
+	env    *Env
+	call   *CallExpr
+	fun    reflect.Value
+	typed  bool
+	args   []*[]reflect.Value
+	atyped []bool
+
+	// The panic being handled when the call is deferred. Only function
+	// literals and declared functions called directly may recover it.
+	panicking *panicState
+}
+
+// declaredFunc is what a function declaration, stored in Env.Funcs, was
+// declared from. Calls of a declared function made by interpreted code
+// of its Env are made directly, with the Ctx of the caller, so that its
+// Context and Limits apply even when the function was declared by an
+// earlier evaluation. Other calls use the Ctx it was declared with.
+type declaredFunc struct {
+	fn    *function
+	env   *Env
+	input string
+	fset  *token.FileSet
+	base  int
+
+	// Set by Env.MarkPure, atomically
+	pure int32
+}
+
+// declaredFunc returns what fun was declared from, if it was declared in
+// env or in the Env env is a scope of. env may be nil.
+func (env *Env) declaredFunc(fun reflect.Value) (*declaredFunc, bool) {
+	if env == nil {
+		return nil, false
+	}
+	envLock.RLock()
+	funcs := env.declaredFuncs
+	envLock.RUnlock()
+	if funcs == nil {
+		return nil, false
+	}
+	d, ok := funcs.Load(fun)
+	if !ok {
+		return nil, false
+	}
+	return d.(*declaredFunc), true
+}
+
+// calleeEnv returns the Env in which the function called by call may
+// have been declared: that of its package for a qualified name, and env
+// otherwise
+func calleeEnv(call *CallExpr, env *Env) *Env {
+	if sel, ok := skipSuperfluousParens(call.Fun.(Expr)).(*SelectorExpr); ok {
+		if pkg, ok := packageOf(sel, env); ok {
+			return (*Env)(pkg)
+		}
+	}
+	return env
+}
+
+func (d *declaredFunc) isPure() bool {
+	return atomic.LoadInt32(&d.pure) != 0
+}
+
+// makeFunc returns a function value which executes fn in a scope nested
+// in env. Free variables of fn are looked up in env when the function
+// is called, so env should be a copy for closures, and the live Env for
+// declared functions, which must see themselves and later declarations.
+func makeFunc(ctx *Ctx, fn *function, env *Env) reflect.Value {
+	return makeDeferredFunc(ctx, fn, env, nil)
+}
+
+// makeDeferredFunc is makeFunc for a function literal called by a defer
+// statement, which may recover the panic of the deferring function
+func makeDeferredFunc(ctx *Ctx, fn *function, env *Env, d *deferred) reflect.Value {
+	return reflect.MakeFunc(fn.t, func(in []reflect.Value) []reflect.Value {
+		var recovering *panicState
+		if d != nil {
+			recovering = d.panicking
+		}
+		out, err := callFunc(ctx, fn, env, in, recovering)
+		if err != nil {
+			panic(funcError{err})
+		}
+		return out
+	})
+}
+
+func callFunc(ctx *Ctx, fn *function, env *Env, in []reflect.Value, recovering *panicState) ([]reflect.Value, error) {
+	ctx = ctx.start()
+	if err := ctx.Limits.enterCall(); err != nil {
+		return nil, err
+	}
+	defer ctx.Limits.exitCall()
+
+	b := &block{env: newScope(env)}
+	b.env.recovering = recovering
+	for i, name := range fn.params {
+		ptr := reflect.New(fn.t.In(i))
+		ptr.Elem().Set(in[i])
+		b.declareVar(name, ptr)
+	}
+	results := make([]reflect.Value, fn.t.NumOut())
+	for i := range results {
+		results[i] = reflect.New(fn.t.Out(i))
+		b.declareVar(fn.results[i], results[i])
+	}
+
+	fr := &frame{results: results}
+	_, err := evalStmtList(ctx, fn.body.List, b, fr)
+	if err = fr.runDefers(err); err != nil {
+		return nil, err
+	}
+
+	out := make([]reflect.Value, len(results))
+	for i, result := range results {
+		out[i] = result.Elem()
+	}
+	return out, nil
+}
+
+// callValue calls fun, which may have been declared in env. If fun is
+// variadic, the last of in is a slice of the variadic arguments, unless
+// spread is false.
+func callValue(ctx *Ctx, env *Env, fun reflect.Value, in []reflect.Value, spread bool) ([]reflect.Value, error) {
+	if d, ok := env.declaredFunc(fun); ok {
+		if t := d.fn.t; t.IsVariadic() && !spread {
+			n := t.NumIn() - 1
+			rest := reflect.MakeSlice(t.In(n), len(in)-n, len(in)-n)
+			for i, v := range in[n:] {
+				rest.Index(i).Set(v)
+			}
+			in = append(in[:n:n], rest)
+		}
+		callCtx := *ctx
+		callCtx.Input, callCtx.Fset, callCtx.Base = d.input, d.fset, d.base
+		callCtx.recovering = nil
+		return callFunc(&callCtx, d.fn, d.env, in, ctx.recovering)
+	} else if spread && fun.Type().IsVariadic() {
+		return fun.CallSlice(in), nil
+	}
+	return fun.Call(in), nil
+}
+
+// runDefers makes the deferred calls of a frame, most recent first. err
+// is the error with which the function is exiting, which the deferred
+// calls may recover or replace.
+func (fr *frame) runDefers(err error) error {
+	state := &panicState{err}
+	for i := len(fr.defers) - 1; i >= 0; i -= 1 {
+		d := fr.defers[i]
+		d.panicking = state
+		deferCtx := *d.ctx
+		deferCtx.recovering = state
+		_, _, derr := callFunValues(&deferCtx, d.env, d.fun, d.typed, d.call, d.args, d.atyped)
+		if derr != nil && (state.err == nil || recoverable(state.err)) {
+			state.err = derr
+		}
+	}
+	fr.defers = nil
+	return state.err
+}
+
+// recoverable reports whether err is a run-time panic, which recover
+// may stop. Errors with which evaluation itself is stopped, by Limits,
+// Policy, Ctx.Context or Ctx.Timeout, are not.
+func recoverable(err error) bool {
+	switch err.(type) {
+	case ErrPanic:
+		return true
+	case ErrLimitExceeded, ErrDenied, ErrTimeout:
+		return false
+	}
+	return err != ErrCanceled && err != ErrDeadlineExceeded
+}
+
+// evalRecover implements the builtin recover. The panic is only
+// recovered when called by a function literal or declared function
+// which a deferred call calls directly, and only if it is recoverable.
+func evalRecover(env *Env) *[]reflect.Value {
+	var r interface{}
+	if p := env.recovering; p != nil && p.err != nil && recoverable(p.err) {
+		if e, ok := p.err.(ErrPanic); ok {
+			r = e.Value
+		} else {
+			r = p.err
+		}
+		p.err = nil
+	}
+	return &[]reflect.Value{reflect.ValueOf(&r).Elem()}
+}
+
+// goCall runs a call made by a go statement in a new goroutine. Errors
+// are sent to env.GoErrors, unless the Context of the call is done
+// first.
+func goCall(d *deferred, env *Env) {
+	errs := env.GoErrors
+	var done <-chan struct{}
+	if d.ctx.Context != nil {
+		done = d.ctx.Context.Done()
+	}
+	go func() {
+		if _, _, err := callFunValues(d.ctx, d.env, d.fun, d.typed, d.call, d.args, d.atyped); err != nil && errs != nil {
+			select {
+			case errs <- err:
+			case <-done:
+			}
+		}
+	}()
+}
+
+// recoverFuncError converts a panic, either raised by an interpreted
+// function or by native code, back into an error.
+func recoverFuncError(err *error) {
+	if r := recover(); r != nil {
+		if ferr, ok := r.(funcError); ok {
+			*err = ferr.err
+		} else {
+			*err = ErrPanic{r}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package eval
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestFuncDeclRecursive(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}`, env)
+
+	expectResult(t, "fib(10)", env, int(55))
+}
+
+func TestFuncDeclMutuallyRecursive(t *testing.T) {
+	env := makeEnv()
+	declare(t, `
+func even(n int) bool {
+	if n == 0 {
+		return true
+	}
+	return odd(n - 1)
+}
+func odd(n int) bool {
+	if n == 0 {
+		return false
+	}
+	return even(n - 1)
+}`, env)
+
+	expectResult(t, "even(10)", env, true)
+	expectResult(t, "odd(10)", env, false)
+}
+
+func TestFuncDeclMultipleResults(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func divmod(a, b int) (int, int) {
+	return a / b, a % b
+}`, env)
+
+	expectResults(t, "divmod(7, 2)", env, &[]interface{}{int(3), int(1)})
+}
+
+func TestFuncDeclNamedResults(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func swap(a, b string) (x, y string) {
+	x, y = b, a
+	return
+}`, env)
+
+	expectResults(t, "swap(\"a\", \"b\")", env, &[]interface{}{"b", "a"})
+}
+
+func TestFuncDeclVariadic(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func sum(xs ...int) (total int) {
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}`, env)
+
+	expectResult(t, "sum()", env, int(0))
+	expectResult(t, "sum(1, 2, 3)", env, int(6))
+}
+
+func TestFuncDeclIsNative(t *testing.T) {
+	env := makeEnv()
+	declare(t, "func double(n int) int { return n * 2 }", env)
+
+	// Declared functions are ordinary funcs, callable from go
+	double, ok := env.Funcs["double"].Interface().(func(int) int)
+	if !ok {
+		t.Fatalf("Expected func(int) int, got %v", env.Funcs["double"].Type())
+	} else if r := double(21); r != 42 {
+		t.Fatalf("double(21) = %d, expected 42", r)
+	}
+}
+
+func TestFuncDeclPerEnv(t *testing.T) {
+	env := makeEnv()
+	declare(t, "func double(n int) int { return n * 2 }", env)
+
+	// Declarations are kept by their Env, and collected with it
+	if _, ok := env.declaredFunc(env.Funcs["double"]); !ok {
+		t.Fatalf("Expected double to be declared in its Env")
+	}
+	other := makeEnv()
+	other.Funcs["double"] = env.Funcs["double"]
+	if _, ok := other.declaredFunc(env.Funcs["double"]); ok {
+		t.Fatalf("Expected double to be unknown to another Env")
+	}
+	expectResult(t, "double(3)", other, int(6))
+}
+
+func TestFuncDeclCallsNative(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["Itoa"] = reflect.ValueOf(strconv.Itoa)
+	declare(t, `func label(n int) string { return "#" + Itoa(n) }`, env)
+
+	expectResult(t, "label(7)", env, "#7")
+}
+
+func TestFuncDeclClosure(t *testing.T) {
+	env := makeEnv()
+	declare(t, `func counter() func() int {
+	n := 0
+	return func() int {
+		n++
+		return n
+	}
+}`, env)
+
+	run(t, "next := counter()\nnext()\nnext()", env)
+	expectResult(t, "next()", env, int(3))
+}
+
+func TestFuncDeclVar(t *testing.T) {
+	env := makeEnv()
+	declare(t, "var a, b = 1, \"s\"\nvar c float32\nvar (d = a + 1; e []int)", env)
+
+	expectResult(t, "a", env, int(1))
+	expectResult(t, "b", env, "s")
+	expectResult(t, "c", env, float32(0))
+	expectResult(t, "d", env, int(2))
+	expectResult(t, "e", env, []int(nil))
+}
+
+func TestFuncDeclRuntimeError(t *testing.T) {
+	env := makeEnv()
+	declare(t, "func div(a, b int) int { return a / b }", env)
+
+	expectError(t, "div(1, 0)", env, "runtime error: integer divide by zero")
+}
+
+func TestFuncDeclErrors(t *testing.T) {
+	env := makeEnv()
+	expectDeclError(t, "func f() int { }", env,
+		"missing return at end of function")
+	expectDeclError(t, "func f() int { return 1, 2 }", env,
+		"too many arguments to return")
+	expectDeclError(t, "func f() (int, int) { return 1 }", env,
+		"not enough arguments to return")
+	expectDeclError(t, "func f() { break }", env,
+		"break is not in a loop, switch, or select")
+	expectDeclError(t, "func f() { 1 + 2 }", env,
+		"1 + 2 evaluated but not used")
+	expectDeclError(t, "func f() { a := 1; a := 2 }", env,
+		"no new variables on left side of :=")
+	expectDeclError(t, "func f(a, b int) { a, b = 1 }", env,
+		"assignment mismatch: 2 variables but 1 value")
+}
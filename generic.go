@@ -0,0 +1,419 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// Generic is a generic function of the host, registered in
+// Env.Generics. reflect can only call the instantiations of a generic
+// function which were compiled into the host, so each instantiation
+// used must either be registered with Add, or built by Factory.
+type Generic struct {
+	Name string
+
+	// Factory, if set, returns the instantiation of the function for
+	// type arguments which have not been registered. It should return
+	// an error for type arguments it does not support.
+	Factory func(targs []reflect.Type) (interface{}, error)
+
+	typeParams []string
+	params     []ast.Expr
+	results    []ast.Expr
+	variadic   bool
+
+	mu        sync.Mutex
+	instances []instance
+}
+
+// instance is an instantiation of a generic function
+type instance struct {
+	targs []reflect.Type
+	fn    reflect.Value
+}
+
+// NewGeneric returns a generic function with the given signature,
+// written as a function declaration without a body, e.g.
+//
+//	eval.NewGeneric("func Map[T, U any](xs []T, f func(T) U) []U")
+//
+// Constraints are not checked, each instantiation is assumed to satisfy
+// them. The type arguments of a call are inferred by matching the types
+// of its arguments against the parameters of the signature.
+func NewGeneric(signature string) (*Generic, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p;"+signature, 0)
+	if err != nil {
+		return nil, err
+	}
+	decl, ok := f.Decls[0].(*ast.FuncDecl)
+	if len(f.Decls) != 1 || !ok || decl.Recv != nil || decl.Body != nil {
+		return nil, errors.New("generic signature must be a function declaration without a body")
+	} else if decl.Type.TypeParams == nil {
+		return nil, fmt.Errorf("%s has no type parameters", decl.Name.Name)
+	}
+
+	g := &Generic{Name: decl.Name.Name}
+	for _, field := range decl.Type.TypeParams.List {
+		for _, name := range field.Names {
+			g.typeParams = append(g.typeParams, name.Name)
+		}
+	}
+	g.params = fieldTypes(decl.Type.Params)
+	g.results = fieldTypes(decl.Type.Results)
+	if n := len(g.params); n != 0 {
+		if ellipsis, ok := g.params[n-1].(*ast.Ellipsis); ok {
+			g.params[n-1] = &ast.ArrayType{Lbrack: ellipsis.Pos(), Elt: ellipsis.Elt}
+			g.variadic = true
+		}
+	}
+	return g, nil
+}
+
+// fieldTypes returns the type of each parameter or result in fields
+func fieldTypes(fields *ast.FieldList) []ast.Expr {
+	if fields == nil {
+		return nil
+	}
+	var types []ast.Expr
+	for _, field := range fields.List {
+		for n := 0; n == 0 || n < len(field.Names); n += 1 {
+			types = append(types, field.Type)
+		}
+	}
+	return types
+}
+
+// Add registers instantiations of g. The type arguments of each are
+// found by matching its type against the signature of g.
+func (g *Generic) Add(fns ...interface{}) error {
+	for _, fn := range fns {
+		v := reflect.ValueOf(fn)
+		if v.Kind() != reflect.Func {
+			return fmt.Errorf("cannot use %v as an instantiation of %s", v.Type(), g.Name)
+		}
+		t := v.Type()
+		targs := make([]reflect.Type, len(g.typeParams))
+		ok := t.NumIn() == len(g.params) && t.NumOut() == len(g.results)
+		for i := 0; ok && i < t.NumIn(); i += 1 {
+			ok = g.unify(g.params[i], t.In(i), targs)
+		}
+		for i := 0; ok && i < t.NumOut(); i += 1 {
+			ok = g.unify(g.results[i], t.Out(i), targs)
+		}
+		if !ok {
+			return fmt.Errorf("cannot use %v as an instantiation of %s", t, g)
+		}
+		for i, targ := range targs {
+			if targ == nil {
+				return fmt.Errorf("cannot infer %s of %s from %v, use AddInstance", g.typeParams[i], g.Name, t)
+			}
+		}
+		if err := g.AddInstance(fn, targs...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddInstance registers fn as the instantiation of g for targs
+func (g *Generic) AddInstance(fn interface{}, targs ...reflect.Type) error {
+	v, err := g.checkInstance(reflect.ValueOf(fn), targs)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.instances = append(g.instances, instance{targs, v})
+	return nil
+}
+
+// Instantiate returns the instantiation of g for targs, calling Factory
+// if it has not been registered. If there is no Factory the error is an
+// ErrNotInstantiated.
+func (g *Generic) Instantiate(targs []reflect.Type) (reflect.Value, error) {
+	if len(targs) != len(g.typeParams) {
+		return reflect.Value{}, ErrTypeArgCount{ErrorContext{}, g, len(targs)}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, inst := range g.instances {
+		if typesIdentical(inst.targs, targs) {
+			return inst.fn, nil
+		}
+	}
+	if g.Factory == nil {
+		return reflect.Value{}, ErrNotInstantiated{ErrorContext{}, g, targs}
+	}
+	fn, err := g.Factory(targs)
+	if err != nil {
+		return reflect.Value{}, ErrBadInstance{ErrorContext{}, g, targs, err}
+	}
+	v, err := g.checkInstance(reflect.ValueOf(fn), targs)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	g.instances = append(g.instances, instance{append([]reflect.Type{}, targs...), v})
+	return v, nil
+}
+
+// checkInstance checks that fn has the type of g instantiated with
+// targs. Types which cannot be built from the signature, such as those
+// of other packages, are not checked.
+func (g *Generic) checkInstance(fn reflect.Value, targs []reflect.Type) (reflect.Value, error) {
+	if len(targs) != len(g.typeParams) {
+		return fn, ErrTypeArgCount{ErrorContext{}, g, len(targs)}
+	} else if fn.Kind() != reflect.Func {
+		return fn, ErrBadInstance{ErrorContext{}, g, targs, errors.New("not a function")}
+	}
+	in := make([]reflect.Type, len(g.params))
+	out := make([]reflect.Type, len(g.results))
+	ok := true
+	for i := range in {
+		in[i], ok = g.subst(g.params[i], targs)
+		if !ok {
+			return fn, nil
+		}
+	}
+	for i := range out {
+		out[i], ok = g.subst(g.results[i], targs)
+		if !ok {
+			return fn, nil
+		}
+	}
+	if want := reflect.FuncOf(in, out, g.variadic); fn.Type() != want {
+		err := fmt.Errorf("have type %v, want %v", fn.Type(), want)
+		return fn, ErrBadInstance{ErrorContext{}, g, targs, err}
+	}
+	return fn, nil
+}
+
+// unify matches the parameter type pattern against t, recording the
+// types of any type parameters in targs. Parts of the pattern other
+// than type parameters are not checked.
+func (g *Generic) unify(pattern ast.Expr, t reflect.Type, targs []reflect.Type) bool {
+	switch p := pattern.(type) {
+	case *ast.Ident:
+		if i := g.typeParam(p.Name); i < 0 {
+			return true
+		} else if targs[i] == nil {
+			targs[i] = t
+			return true
+		} else {
+			return targs[i] == t
+		}
+	case *ast.ParenExpr:
+		return g.unify(p.X, t, targs)
+	case *ast.StarExpr:
+		return t.Kind() == reflect.Ptr && g.unify(p.X, t.Elem(), targs)
+	case *ast.ArrayType:
+		if p.Len == nil {
+			return t.Kind() == reflect.Slice && g.unify(p.Elt, t.Elem(), targs)
+		}
+		return t.Kind() == reflect.Array && g.unify(p.Elt, t.Elem(), targs)
+	case *ast.MapType:
+		return t.Kind() == reflect.Map && g.unify(p.Key, t.Key(), targs) &&
+			g.unify(p.Value, t.Elem(), targs)
+	case *ast.ChanType:
+		return t.Kind() == reflect.Chan && g.unify(p.Value, t.Elem(), targs)
+	case *ast.FuncType:
+		params, results := fieldTypes(p.Params), fieldTypes(p.Results)
+		if t.Kind() != reflect.Func || t.NumIn() != len(params) || t.NumOut() != len(results) {
+			return false
+		}
+		for i, param := range params {
+			if ellipsis, ok := param.(*ast.Ellipsis); ok {
+				param = &ast.ArrayType{Elt: ellipsis.Elt}
+			}
+			if !g.unify(param, t.In(i), targs) {
+				return false
+			}
+		}
+		for i, result := range results {
+			if !g.unify(result, t.Out(i), targs) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// subst returns the type of the parameter type pattern, with targs
+// substituted for the type parameters. It fails for types which are not
+// built in or composed of type parameters.
+func (g *Generic) subst(pattern ast.Expr, targs []reflect.Type) (reflect.Type, bool) {
+	switch p := pattern.(type) {
+	case *ast.Ident:
+		if i := g.typeParam(p.Name); i >= 0 {
+			return unhackType(targs[i]), true
+		} else if p.Name == "any" {
+			return emptyInterfaceType, true
+		} else if t, ok := builtinTypes[p.Name]; ok {
+			return unhackType(t), true
+		}
+	case *ast.ParenExpr:
+		return g.subst(p.X, targs)
+	case *ast.StarExpr:
+		if elem, ok := g.subst(p.X, targs); ok {
+			return reflect.PtrTo(elem), true
+		}
+	case *ast.ArrayType:
+		if elem, ok := g.subst(p.Elt, targs); !ok {
+			return nil, false
+		} else if p.Len == nil {
+			return reflect.SliceOf(elem), true
+		}
+	case *ast.MapType:
+		key, ok := g.subst(p.Key, targs)
+		if elem, ok2 := g.subst(p.Value, targs); ok && ok2 {
+			return reflect.MapOf(key, elem), true
+		}
+	case *ast.ChanType:
+		if elem, ok := g.subst(p.Value, targs); ok {
+			dir := reflect.BothDir
+			if p.Dir == ast.SEND {
+				dir = reflect.SendDir
+			} else if p.Dir == ast.RECV {
+				dir = reflect.RecvDir
+			}
+			return reflect.ChanOf(dir, elem), true
+		}
+	case *ast.FuncType:
+		params, results := fieldTypes(p.Params), fieldTypes(p.Results)
+		in, out := make([]reflect.Type, len(params)), make([]reflect.Type, len(results))
+		variadic := false
+		for i, param := range params {
+			if ellipsis, ok := param.(*ast.Ellipsis); ok {
+				param, variadic = &ast.ArrayType{Elt: ellipsis.Elt}, true
+			}
+			var ok bool
+			if in[i], ok = g.subst(param, targs); !ok {
+				return nil, false
+			}
+		}
+		for i, result := range results {
+			var ok bool
+			if out[i], ok = g.subst(result, targs); !ok {
+				return nil, false
+			}
+		}
+		return reflect.FuncOf(in, out, variadic), true
+	}
+	return nil, false
+}
+
+func (g *Generic) typeParam(name string) int {
+	for i, param := range g.typeParams {
+		if param == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// instanceName formats an instantiation, e.g. Map[int, string]
+func (g *Generic) instanceName(targs []reflect.Type) string {
+	names := make([]string, len(targs))
+	for i, t := range targs {
+		names[i] = fmt.Sprint(t)
+	}
+	return g.Name + "[" + strings.Join(names, ", ") + "]"
+}
+
+func (g *Generic) String() string {
+	return g.Name + "[" + strings.Join(g.typeParams, ", ") + "]"
+}
+
+// defaultConstType returns the type an untyped constant takes when
+// assigned to an interface
+func defaultConstType(c ConstType) reflect.Type {
+	switch c.(type) {
+	case ConstIntType:
+		return intType
+	case ConstRuneType:
+		return reflect.TypeOf(rune(0))
+	case ConstFloatType:
+		return reflect.TypeOf(float64(0))
+	case ConstComplexType:
+		return reflect.TypeOf(complex128(0))
+	case ConstStringType:
+		return reflect.TypeOf("")
+	case ConstBoolType:
+		return reflect.TypeOf(false)
+	default:
+		return nil
+	}
+}
+
+// constKindRank orders the kinds of numeric constants, int < rune <
+// float < complex
+func constKindRank(c ConstType) int {
+	switch c.(type) {
+	case ConstIntType:
+		return 1
+	case ConstRuneType:
+		return 2
+	case ConstFloatType:
+		return 3
+	case ConstComplexType:
+		return 4
+	default:
+		return 0
+	}
+}
+
+func typesIdentical(a, b []reflect.Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if unhackType(a[i]) != unhackType(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func evalIndexListExpr(ctx *Ctx, index *IndexListExpr) (*[]reflect.Value, bool, error) {
+	if !index.fn.IsValid() {
+		return nil, false, ErrUninstantiatedGeneric{at(ctx, index), index.generic}
+	}
+	return &[]reflect.Value{index.fn}, true, nil
+}
+
+// instantiateCall returns the instantiation of a generic function whose
+// type arguments could not be inferred by the checker, inferring them
+// from the evaluated arguments of the call
+func instantiateCall(ctx *Ctx, fun *IndexListExpr, call *CallExpr, args []*[]reflect.Value, atyped []bool) (reflect.Value, error) {
+	var types []reflect.Type
+	if len(call.Args) == 1 && args[0] != nil && len(*args[0]) > 1 {
+		for _, v := range *args[0] {
+			types = append(types, v.Type())
+		}
+	} else {
+		for i, arg := range args {
+			var t reflect.Type
+			if arg == nil {
+				// untyped nil
+			} else if kt := call.Args[i].(Expr).KnownType(); !atyped[i] && len(kt) == 1 {
+				t = kt[0]
+			} else if v := (*arg)[0]; v.IsValid() {
+				t = v.Type()
+			}
+			types = append(types, t)
+		}
+	}
+	targs, err := inferTypeArgs(ctx, fun, call, types)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return instantiate(ctx, fun.generic, targs, call)
+}
@@ -0,0 +1,144 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func mapSlice[T, U any](xs []T, f func(T) U) []U {
+	ys := make([]U, len(xs))
+	for i, x := range xs {
+		ys[i] = f(x)
+	}
+	return ys
+}
+
+func maxOf[T int | float64](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func zero[T any]() T {
+	var z T
+	return z
+}
+
+// zeroFactory instantiates Zero for int and string
+func zeroFactory(targs []reflect.Type) (interface{}, error) {
+	switch targs[0] {
+	case reflect.TypeOf(0):
+		return zero[int], nil
+	case reflect.TypeOf(""):
+		return zero[string], nil
+	}
+	return nil, errors.New("unsupported type argument")
+}
+
+// mustGeneric declares a generic function instantiated by instances
+func mustGeneric(t *testing.T, decl string, instances ...interface{}) *Generic {
+	g, err := NewGeneric(decl)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := g.Add(instances...); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGenericExplicit(t *testing.T) {
+	xs := []int{1, 2}
+	env := makeEnv()
+	env.Funcs["itoa"] = reflect.ValueOf(strconv.Itoa)
+	env.Generics["Map"] = mustGeneric(t, "func Map[T, U any](xs []T, f func(T) U) []U", mapSlice[int, string], mapSlice[string, int])
+	env.Generics["Max"] = mustGeneric(t, "func Max[T int | float64](a, b T) T", maxOf[int], maxOf[float64])
+	env.Generics["Zero"] = mustGeneric(t, "func Zero[T any]() T")
+	env.Generics["Zero"].Factory = zeroFactory
+	env.Vars["xs"] = reflect.ValueOf(&xs)
+
+	expectResult(t, "Map[int, string](xs, itoa)", env, []string{"1", "2"})
+	expectResult(t, "Max[float64](1, 2.5)", env, float64(2.5))
+	expectResult(t, "Zero[string]()", env, "")
+}
+
+func TestGenericInferred(t *testing.T) {
+	xs := []int{1, 2}
+	env := makeEnv()
+	env.Funcs["itoa"] = reflect.ValueOf(strconv.Itoa)
+	env.Generics["Map"] = mustGeneric(t, "func Map[T, U any](xs []T, f func(T) U) []U", mapSlice[int, string], mapSlice[string, int])
+	env.Generics["Max"] = mustGeneric(t, "func Max[T int | float64](a, b T) T", maxOf[int], maxOf[float64])
+	env.Vars["xs"] = reflect.ValueOf(&xs)
+
+	expectResult(t, "Map(xs, itoa)", env, []string{"1", "2"})
+	expectResult(t, "Map(xs, func(x int) string { return itoa(x * 2) })", env, []string{"2", "4"})
+	expectResult(t, "Max(1, 2)", env, int(2))
+	expectResult(t, "Max(1, 2.5)", env, float64(2.5))
+}
+
+func TestGenericInferredAtRuntime(t *testing.T) {
+	env := makeEnv()
+	env.Generics["Map"] = mustGeneric(t, "func Map[T, U any](xs []T, f func(T) U) []U", mapSlice[int, string], mapSlice[string, int])
+	run(t, "ys := []string{\"3\", \"4\"}\nzs := Map(ys, func(s string) int { return len(s) + 1 })", env)
+
+	expectResult(t, "zs", env, []int{2, 2})
+}
+
+func TestGenericPackage(t *testing.T) {
+	xs := []int{1, 2}
+	env := makeEnv()
+	env.Funcs["itoa"] = reflect.ValueOf(strconv.Itoa)
+	env.Vars["xs"] = reflect.ValueOf(&xs)
+	pkg := makeEnv()
+	pkg.Generics["Map"] = mustGeneric(t, "func Map[T, U any](xs []T, f func(T) U) []U", mapSlice[int, string], mapSlice[string, int])
+	env.Pkgs["slices"] = pkg
+
+	expectResult(t, "slices.Map(xs, itoa)", env, []string{"1", "2"})
+	expectResult(t, "slices.Map[int, string](xs, itoa)", env, []string{"1", "2"})
+}
+
+func TestGenericErrors(t *testing.T) {
+	xs := []int{1, 2}
+	env := makeEnv()
+	env.Funcs["itoa"] = reflect.ValueOf(strconv.Itoa)
+	env.Generics["Map"] = mustGeneric(t, "func Map[T, U any](xs []T, f func(T) U) []U", mapSlice[int, string], mapSlice[string, int])
+	env.Generics["Max"] = mustGeneric(t, "func Max[T int | float64](a, b T) T", maxOf[int], maxOf[float64])
+	env.Generics["Zero"] = mustGeneric(t, "func Zero[T any]() T")
+	env.Generics["Zero"].Factory = zeroFactory
+	env.Vars["xs"] = reflect.ValueOf(&xs)
+	env.Funcs["f"] = reflect.ValueOf(strconv.Quote)
+
+	expectCheckError(t, "Map", env,
+		"cannot use generic function Map without instantiation")
+	expectCheckError(t, "Map[int]", env,
+		"not enough type arguments for Map: have 1, want 2")
+	expectCheckError(t, "Max[int, int](1, 2)", env,
+		"too many type arguments for Max: have 2, want 1")
+	expectCheckError(t, "Map[int, bool](xs, nil)", env,
+		"Map[int, bool] is not instantiated by the host")
+	expectCheckError(t, "Map(xs, f)", env,
+		"in call to Map, type func(string) string of f does not match func(T) U")
+	expectCheckError(t, "Zero()", env,
+		"in call to Zero, cannot infer T")
+	expectCheckError(t, "Zero[bool]()", env,
+		"cannot instantiate Zero[bool]: unsupported type argument")
+	expectCheckError(t, "itoa[int, string](1)", env,
+		"invalid operation: itoa is not a generic function")
+}
+
+func TestGenericAdd(t *testing.T) {
+	g, err := NewGeneric("func Map[T, U any](xs []T, f func(T) U) []U")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add(strconv.Itoa); err == nil {
+		t.Fatalf("Expected Add to reject strconv.Itoa")
+	}
+	err = g.AddInstance(mapSlice[int, string], reflect.TypeOf(""), reflect.TypeOf(0))
+	if err == nil || err.Error() != "cannot instantiate Map[string, int]: "+
+		"have type func([]int, func(int) string) []string, want func([]string, func(string) int) []int" {
+		t.Fatalf("Wrong error %v", err)
+	}
+}
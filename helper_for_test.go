@@ -8,11 +8,13 @@ import (
 	"testing"
 	"reflect"
 
+	"go/ast"
 	"go/parser"
+	"go/token"
 )
 
 func getResults(t *testing.T, expr string, env *Env) *[]reflect.Value {
-	ctx := &Ctx{expr}
+	ctx := &Ctx{Input: expr}
 	if e, err := parser.ParseExpr(expr); err != nil {
 		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
 	} else if aexpr, errs := CheckExpr(ctx, e, env); errs != nil {
@@ -58,7 +60,7 @@ func expectResults(t *testing.T, expr string, env *Env, expected *[]interface{})
 }
 
 func expectError(t *testing.T, expr string, env *Env, errorString string) {
-	ctx := &Ctx{expr}
+	ctx := &Ctx{Input: expr}
 	if e, err := parser.ParseExpr(expr); err != nil {
 		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
 	} else if aexpr, errs := CheckExpr(ctx, e, env); errs != nil {
@@ -74,7 +76,7 @@ func expectError(t *testing.T, expr string, env *Env, errorString string) {
 
 // deprecated, use expectError
 func expectFail(t *testing.T, expr string, env *Env) {
-	ctx := &Ctx{expr}
+	ctx := &Ctx{Input: expr}
 	if e, err := parser.ParseExpr(expr); err != nil {
 		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
 	} else if aexpr, errs := CheckExpr(ctx, e, env); errs != nil {
@@ -89,7 +91,7 @@ func expectFail(t *testing.T, expr string, env *Env) {
 }
 
 func expectConst(t *testing.T, expr string, env *Env, expected interface{}, expectedType reflect.Type) {
-	ctx := &Ctx{expr}
+	ctx := &Ctx{Input: expr}
 	if e, err := parser.ParseExpr(expr); err != nil {
 		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
 	} else if aexpr, errs := CheckExpr(ctx, e, env); errs != nil {
@@ -118,7 +120,7 @@ func expectConst(t *testing.T, expr string, env *Env, expected interface{}, expe
 }
 
 func expectCheckError(t *testing.T, expr string, env *Env, errorString ...string) {
-	ctx := &Ctx{expr}
+	ctx := &Ctx{Input: expr}
 	if e, err := parser.ParseExpr(expr); err != nil {
 		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
 	} else if _, errs := CheckExpr(ctx, e, env); errs != nil {
@@ -151,6 +153,115 @@ func expectCheckError(t *testing.T, expr string, env *Env, errorString ...string
 	}
 }
 
+// expectCompileError expects expr to be rejected as gc rejects it,
+// gc's diagnostics being errorString. Errors the checker leaves to
+// evaluation are accepted from EvalExpr. Expectations generated from
+// go/types name only the errors it reports before stopping, so
+// errorString need only be the leading errors of expr.
+func expectCompileError(t *testing.T, expr string, env *Env, errorString ...string) {
+	ctx := &Ctx{Input: expr}
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		t.Fatalf("Failed to parse expression '%s' (%v)", expr, err)
+	}
+	aexpr, errs := CheckExpr(ctx, e, env)
+	if errs == nil {
+		if _, _, err := EvalExpr(ctx, aexpr, env); err != nil {
+			errs = []error{err}
+		}
+	}
+	if errs == nil {
+		t.Fatalf("Expression '%s' is valid, expected %q", expr, errorString)
+	}
+	ok := len(errs) >= len(errorString)
+	for i := 0; ok && i < len(errorString); i += 1 {
+		ok = errs[i].Error() == errorString[i]
+	}
+	if !ok {
+		t.Fatalf("Expression '%s' produced errors %v, expected %q", expr, errs, errorString)
+	}
+}
+
+// checkDecls parses src as the declarations of a file and checks each
+// declaration in turn, evaluating it in env if there are no errors.
+func checkDecls(t *testing.T, src string, env *Env) []error {
+	// The package clause is on its own line, so columns match src
+	src = "package p\n" + src
+	ctx := &Ctx{Input: src}
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse declarations '%s' (%v)", src, err)
+	}
+	for _, decl := range f.Decls {
+		if cdecl, errs := CheckDecl(ctx, decl, env); errs != nil {
+			return errs
+		} else if err := EvalDecl(ctx, cdecl, env); err != nil {
+			return []error{err}
+		}
+	}
+	return nil
+}
+
+func declare(t *testing.T, src string, env *Env) {
+	if errs := checkDecls(t, src, env); errs != nil {
+		t.Fatalf("Failed to declare '%s' (%v)", src, errs)
+	}
+}
+
+func expectDeclError(t *testing.T, src string, env *Env, errorString ...string) {
+	errs := checkDecls(t, src, env)
+	if errs == nil {
+		t.Fatalf("Missing errors for declarations '%s'", src)
+	}
+	ok := len(errs) >= len(errorString)
+	for i := 0; ok && i < len(errorString); i += 1 {
+		ok = errs[i].Error() == errorString[i]
+	}
+	if !ok {
+		t.Fatalf("Declarations '%s' produced errors %v, expected %v", src, errs, errorString)
+	}
+}
+
+// checkStmts parses src as a sequence of statements and checks each
+// statement in turn, evaluating it in env if there are no errors.
+func checkStmts(t *testing.T, src string, env *Env) []error {
+	// The function header is on its own line, so columns match src
+	src = "package p\nfunc _() {\n" + src + "\n}"
+	ctx := &Ctx{Input: src}
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse statements '%s' (%v)", src, err)
+	}
+	for _, stmt := range f.Decls[0].(*ast.FuncDecl).Body.List {
+		if cstmt, errs := CheckStmt(ctx, stmt, env); errs != nil {
+			return errs
+		} else if err := EvalStmt(ctx, cstmt, env); err != nil {
+			return []error{err}
+		}
+	}
+	return nil
+}
+
+func run(t *testing.T, src string, env *Env) {
+	if errs := checkStmts(t, src, env); errs != nil {
+		t.Fatalf("Failed to run '%s' (%v)", src, errs)
+	}
+}
+
+func expectStmtError(t *testing.T, src string, env *Env, errorString ...string) {
+	errs := checkStmts(t, src, env)
+	if errs == nil {
+		t.Fatalf("Missing errors for statements '%s'", src)
+	}
+	ok := len(errs) >= len(errorString)
+	for i := 0; ok && i < len(errorString); i += 1 {
+		ok = errs[i].Error() == errorString[i]
+	}
+	if !ok {
+		t.Fatalf("Statements '%s' produced errors %v, expected %v", src, errs, errorString)
+	}
+}
+
 func typesEqual(expected, actual reflect.Type) bool {
 	var unwrapped reflect.Type
 	switch t := actual.(type) {
@@ -167,6 +278,7 @@ func makeEnv() *Env {
 		Vars: make(map[string] reflect.Value),
 		Consts: make(map[string] reflect.Value),
 		Funcs: make(map[string] reflect.Value),
+		Generics: make(map[string] *Generic),
 		Types: make(map[string] reflect.Type),
 		Pkgs: make(map[string] Pkg),
 	}
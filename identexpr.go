@@ -9,9 +9,14 @@ import (
 type EvalIdentExprFunc func(ctx *Ctx, ident *Ident, env *Env)  (
 	*reflect.Value, bool, error)
 
+// DerefValue returns the variable v points to, or v itself if it is
+// not a pointer. A variable of an interface type keeps that type, its
+// dynamic value is only reached by a type assertion, a type switch or a
+// method call. Earlier versions also unwrapped interfaces, which callers
+// relying on that must now do with v.Elem().
 func DerefValue(v reflect.Value) reflect.Value {
 	switch v.Kind() {
-	case reflect.Interface, reflect.Ptr:
+	case reflect.Ptr:
 		return v.Elem()
 	default:
 		return v
@@ -28,6 +33,10 @@ func EvalIdentExpr(ctx *Ctx, ident *Ident, env *Env) (*reflect.Value, bool, erro
 		v := DerefValue(v)
 		return &v, true, nil
 	} else if v, ok := env.Consts[name]; ok {
+		if n, ok := v.Interface().(*ConstNumber); ok {
+			r, err := evalConstNumber(ctx, n, ident)
+			return &r, false, err
+		}
 		return &v, false, nil
 	} else if v, ok := env.Funcs[name]; ok {
 		return &v, true, nil
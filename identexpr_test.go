@@ -15,3 +15,14 @@ func TestStringVar(t *testing.T) {
 	env.Vars["arg0"] = reflect.ValueOf("abc")
 	expectResult(t, "arg0", env, "abc")
 }
+
+func TestDerefValueKeepsInterface(t *testing.T) {
+	var any interface{} = 1
+	v := DerefValue(reflect.ValueOf(&any))
+	if v.Kind() != reflect.Interface || v.Type() != reflect.TypeOf(&any).Elem() {
+		t.Fatalf("Expected DerefValue to keep the interface type, got %v", v.Type())
+	}
+	if v := DerefValue(v); v.Kind() != reflect.Interface {
+		t.Fatalf("Expected DerefValue not to unwrap an interface, got %v", v.Type())
+	}
+}
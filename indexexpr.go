@@ -3,9 +3,9 @@ package eval
 import (
 	"errors"
 	"fmt"
+	"go/ast"
 	"reflect"
 	"strings"
-	"go/ast"
 )
 
 // CannotIndex returns error if i is a not valid index in v
@@ -35,8 +35,7 @@ func evalIndexExpr(ctx *Ctx, index *IndexExpr, env *Env) (*reflect.Value, bool,
 	if err != nil {
 		return nil, false, err
 	} else if xs == nil {
-		// XXX temporary error until typed evaluation of nil
-		return nil, false, errors.New("Cannot index nil type")
+		return nil, false, ErrUntypedNil{at(ctx, index.X)}
 	}
 
 	var x reflect.Value
@@ -51,19 +50,23 @@ func evalIndexExpr(ctx *Ctx, index *IndexExpr, env *Env) (*reflect.Value, bool,
 	}
 
 	switch x.Type().Kind() {
-	// case reflect.Map:
+	case reflect.Map:
+		return evalIndexExprMap(ctx, x, index.Index, env)
 	case reflect.Array, reflect.Slice, reflect.String:
-		return evalIndexExprInt(ctx, x, index.Index, env)
+		return evalIndexExprInt(ctx, x, index, env)
 	default:
 		return nil, true, ErrInvalidIndexOperation{at(ctx, index), x.Type()}
 	}
 }
 
 // For arrays, slices and strings
-func evalIndexExprInt(ctx *Ctx, x reflect.Value, intExpr ast.Expr, env *Env) (*reflect.Value, bool, error) {
-	if i, err := evalIntIndex(ctx, intExpr, env, x.Type()); err != nil {
+func evalIndexExprInt(ctx *Ctx, x reflect.Value, index *IndexExpr, env *Env) (*reflect.Value, bool, error) {
+	if i, err := evalIntIndex(ctx, index.Index, env, x.Type()); err != nil {
 		return nil, false, err
 	} else {
+		if n, bound := staticBound(x, index.X.(Expr)); n >= 0 && i >= n && index.Index.(Expr).IsConst() {
+			return nil, false, ErrInvalidIndex{at(ctx, index.Index), reflect.ValueOf(i), x.Type(), bound}
+		}
 		if err := CannotIndex(x, i); err != nil {
 			return nil, false, err
 		}
@@ -72,27 +75,98 @@ func evalIndexExprInt(ctx *Ctx, x reflect.Value, intExpr ast.Expr, env *Env) (*r
 	}
 }
 
+// Constants used as integers must be integral, a[2*0.5] is legal,
+// a[2*0.4] is not
+func checkIntegralConst(ctx *Ctx, intExpr ast.Expr) error {
+	if e := intExpr.(Expr); e.IsConst() {
+		if n, ok := e.Const().Interface().(*ConstNumber); ok {
+			if _, truncation, _ := n.Value.Int(64); truncation {
+				return ErrTruncatedConstant{at(ctx, intExpr), ConstInt, n}
+			}
+		}
+	}
+	return nil
+}
+
+// For maps, missing keys yield the zero value of the element type
+func evalIndexExprMap(ctx *Ctx, x reflect.Value, keyExpr ast.Expr, env *Env) (*reflect.Value, bool, error) {
+	key, err := evalMapKey(ctx, x.Type(), keyExpr, env)
+	if err != nil {
+		return nil, false, err
+	}
+	v := x.MapIndex(key)
+	if !v.IsValid() {
+		v = reflect.Zero(x.Type().Elem())
+	}
+	return &v, true, nil
+}
+
+// evalIndexExprOk evaluates v, ok := m[k], where ok reports whether k is
+// present in m. Any other index expression yields a single value.
+func evalIndexExprOk(ctx *Ctx, index *IndexExpr, env *Env) (reflect.Value, bool, error) {
+	xs, _, err := EvalExpr(ctx, index.X.(Expr), env)
+	if err != nil {
+		return reflect.Value{}, false, err
+	} else if xs == nil {
+		return reflect.Value{}, false, ErrUntypedNil{at(ctx, index.X)}
+	}
+	x, err := expectSingleValue(ctx, *xs, index.X)
+	if err != nil {
+		return reflect.Value{}, false, err
+	} else if x.Kind() != reflect.Map {
+		return reflect.Value{}, false, ErrAssignCount{at(ctx, index), 2, 1}
+	}
+	key, err := evalMapKey(ctx, x.Type(), index.Index, env)
+	if err != nil {
+		return reflect.Value{}, false, err
+	}
+	if v := x.MapIndex(key); v.IsValid() {
+		return v, true, nil
+	}
+	return reflect.Zero(x.Type().Elem()), false, nil
+}
+
+// evalMapKey evaluates keyExpr as a key of the map type t
+func evalMapKey(ctx *Ctx, t reflect.Type, keyExpr ast.Expr, env *Env) (reflect.Value, error) {
+	keyType := t.Key()
+	var key reflect.Value
+	if ks, typed, err := EvalExpr(ctx, keyExpr.(Expr), env); err != nil {
+		return key, err
+	} else if ks == nil {
+		switch keyType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			key = reflect.Zero(keyType)
+		default:
+			return key, ErrBadConstConversion{at(ctx, keyExpr), nil, keyType, reflect.Value{}}
+		}
+	} else if k, err := expectSingleValue(ctx, *ks, keyExpr); err != nil {
+		return key, err
+	} else if key, err = assignableValue(k, keyType, typed); err != nil {
+		// Untyped strings and bools are assignable to types of their kind
+		if typed || k.Kind() != keyType.Kind() {
+			return key, ErrBadConversion{at(ctx, keyExpr), k.Type(), keyType, k}
+		}
+		key = k.Convert(keyType)
+	}
+	return key, nil
+}
+
 func evalIntIndex(ctx *Ctx, intExpr ast.Expr, env *Env, containerType reflect.Type) (int, error) {
+	if err := checkIntegralConst(ctx, intExpr); err != nil {
+		return -1, err
+	}
 	if is, typed, err := EvalExpr(ctx, intExpr.(Expr), env); err != nil {
 		return -1, err
 	} else if is == nil {
-		// XXX temporary error until typed evaluation of nil
-		return -1, errors.New("Cannot index nil type")
+		return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.Value{}, containerType, ""}
 	} else if i, err := expectSingleValue(ctx, *is, intExpr); err != nil {
 		return -1, err
-
-	// XXX This untyped constant conversion is not correct, the index must evaluate exactly
-	// to an integer.
-	// a[2*0.5] is legal, a[2*0.4] is not.
-	//
-	// There is also the constraint that constant expressions such as "abc"[10] must be
-	// in range. Fix both when constant expressions are correctly handled
 	} else if !typed && i.Type().ConvertibleTo(reflect.TypeOf(int(0))) {
 		result := int(i.Convert(reflect.TypeOf(int(0))).Int())
-		if 0 <= result && (containerType.Kind() != reflect.Array || result < containerType.Len()) {
+		if 0 <= result {
 			return result, nil
 		}
-		return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.ValueOf(result), containerType}
+		return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.ValueOf(result), containerType, ""}
 	} else {
 		var result int
 		switch i.Type().Kind() {
@@ -101,15 +175,28 @@ func evalIntIndex(ctx *Ctx, intExpr ast.Expr, env *Env, containerType reflect.Ty
 			if result >= 0 {
 				return result, nil
 			}
-			return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.ValueOf(result), containerType}
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.ValueOf(result), containerType, ""}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 			result = int(i.Uint())
 			if result >= 0 {
 				return result, nil
 			}
-			return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.ValueOf(result), containerType}
+			return -1, ErrInvalidIndex{at(ctx, intExpr), reflect.ValueOf(result), containerType, ""}
 		default:
-			return -1, ErrInvalidIndex{at(ctx, intExpr), i, containerType}
+			return -1, ErrInvalidIndex{at(ctx, intExpr), i, containerType, ""}
 		}
 	}
 }
+
+// staticBound returns the length of x and its description for errors,
+// if it is known to the compiler, as that of an array or a constant
+// string is. Constant indices out of these bounds are compile errors.
+func staticBound(x reflect.Value, xExpr Expr) (int, string) {
+	switch {
+	case x.Kind() == reflect.Array:
+		return x.Len(), fmt.Sprintf("%d-element array", x.Len())
+	case x.Kind() == reflect.String && xExpr.IsConst():
+		return x.Len(), fmt.Sprintf("%d-byte string", x.Len())
+	}
+	return -1, ""
+}
@@ -194,3 +194,24 @@ func TestInvalidIndexArray(t *testing.T) {
 	expr := "a[2]"
 	expectError(t, expr, env, `slice index out of range`)
 }
+
+func TestIndexMapCommaOk(t *testing.T) {
+	m := map[string]int{"a": 1}
+	s := []int{1}
+
+	env := makeEnv()
+	env.Vars["m"] = reflect.ValueOf(&m)
+	env.Vars["s"] = reflect.ValueOf(&s)
+	run(t, "a, okA := m[\"a\"]\nb, okB := (m[\"b\"])\nvar c, okC = m[\"a\"]\nd, okD := 5, true\nd, okD = m[\"d\"]", env)
+
+	expectResult(t, "a", env, int(1))
+	expectResult(t, "okA", env, true)
+	expectResult(t, "b", env, int(0))
+	expectResult(t, "okB", env, false)
+	expectResult(t, "c", env, int(1))
+	expectResult(t, "okC", env, true)
+	expectResult(t, "d", env, int(0))
+	expectResult(t, "okD", env, false)
+
+	expectStmtError(t, "x, ok := s[0]", env, "assignment mismatch: 2 variables but 1 value")
+}
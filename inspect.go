@@ -1,74 +1,430 @@
 package eval
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
+// InspectOptions controls the output of InspectWith. The zero value
+// prints a value in full on one line, without its type.
+type InspectOptions struct {
+	// Types qualifies composite literals and values of named types with
+	// their types, as in main.Alice{Bob: 1} or time.Duration(5)
+	Types bool
+
+	// Multiline puts each element of a composite value on a line of its
+	// own, indented by Indent, or a tab if Indent is empty
+	Multiline bool
+	Indent    string
+
+	// Composite values nested MaxDepth deep, elements of an array, slice
+	// or map beyond the first MaxElements and runes of a string beyond
+	// the first MaxStringLen are elided as "...". Zero is no limit.
+	MaxDepth     int
+	MaxElements  int
+	MaxStringLen int
+
+	// GoSyntax writes values as Go expressions which evaluate back to
+	// equal values, implying Types. Elided values, cycles, channels,
+	// functions, NaNs and infinities have no such expression and are
+	// written as they are otherwise. Unexported fields of structs, which
+	// a composite literal cannot set, are omitted, so a struct with any
+	// which are not zero evaluates back to a different value.
+	GoSyntax bool
+
+	// Env, if set, names types as they are named in it or its packages.
+	// Otherwise they are named by reflect.
+	Env *Env
+}
+
+// InspectPtr prints a pointer as & followed by what it points to, and
+// a nil pointer as nil. Other values are printed as by Inspect.
 func InspectPtr(val reflect.Value) string {
-	// fall back to %v when we panic here.
-	defer func() string {
-		recover()
-		return fmt.Sprintf("%v", val)
-	}()
+	if !val.IsValid() || val.Kind() != reflect.Ptr {
+		return Inspect(val)
+	} else if val.IsNil() {
+		return "nil"
+	}
 	return "&" + Inspect(val.Elem())
 }
 
-
 // Inspect prints a reflect.Value the way you would enter it.
 // Some like this should really be part of the reflect package.
 func Inspect(val reflect.Value) string {
+	return InspectWith(val, InspectOptions{})
+}
 
-	if val.CanInterface() && val.Interface() == nil {
-		return "nil"
+// InspectWith is Inspect controlled by opts. Unexported fields are
+// shown, as fmt shows them. A pointer, map or slice which is reached
+// again from within itself is shown as <cycle> followed by its type.
+func InspectWith(val reflect.Value, opts InspectOptions) string {
+	if opts.Indent == "" {
+		opts.Indent = "\t"
+	}
+	if opts.GoSyntax {
+		opts.Types = true
 	}
+	p := &inspector{opts: opts, visiting: map[visit]bool{}}
+	p.value(val, 0, false)
+	return p.buf.String()
+}
+
+// visit identifies a pointer, map or slice being inspected
+type visit struct {
+	ptr uintptr
+	t   reflect.Type
+}
+
+type inspector struct {
+	opts     InspectOptions
+	buf      bytes.Buffer
+	visiting map[visit]bool
+}
+
+// value writes val. If implied, the type of val is that of the element
+// of a composite value, which basic literals need not repeat.
+func (p *inspector) value(val reflect.Value, depth int, implied bool) {
+	if !val.IsValid() {
+		p.buf.WriteString("nil")
+		return
+	}
+	t := val.Type()
+	// Basic values are converted to named types, and in Go syntax to
+	// any type other than that of the untyped constant they are written as
+	basic := func(s string, constKind reflect.Kind) {
+		if !implied && (p.opts.Types && t.PkgPath() != "" || p.opts.GoSyntax && t.Kind() != constKind) {
+			s = p.typeString(t) + "(" + s + ")"
+		}
+		p.buf.WriteString(s)
+	}
+
+	// The accessors of reflect.Value work for unexported fields, where
+	// Interface does not
 	switch val.Kind() {
+	case reflect.Bool:
+		basic(strconv.FormatBool(val.Bool()), reflect.Bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		basic(strconv.FormatInt(val.Int(), 10), reflect.Int)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		basic(strconv.FormatUint(val.Uint(), 10), reflect.Int)
+	case reflect.Float32:
+		basic(strconv.FormatFloat(val.Float(), 'g', -1, 32), reflect.Float64)
+	case reflect.Float64:
+		basic(p.float(strconv.FormatFloat(val.Float(), 'g', -1, 64)), reflect.Float64)
+	case reflect.Complex64:
+		basic(fmt.Sprint(complex64(val.Complex())), reflect.Complex128)
+	case reflect.Complex128:
+		basic(fmt.Sprint(val.Complex()), reflect.Complex128)
 	case reflect.String:
-		return strconv.QuoteToASCII(val.String())
-	case reflect.Bool, reflect.Int,	reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
-		if val.CanInterface() {
-			fmt.Sprintf("%v", val.Interface())
+		basic(p.quote(val.String()), reflect.String)
+
+	case reflect.Interface:
+		if val.IsNil() {
+			p.buf.WriteString("nil")
 		} else {
-			fmt.Sprintf("<%v>", val.Type())
+			p.value(val.Elem(), depth, false)
 		}
-	case reflect.Slice, reflect.Array:
-		sep := "{"
-		str := ""
-		for i:=0; i < val.Len(); i++ {
-			str += sep
-			sep = ", "
-			str += Inspect(val.Index(i))
+
+	case reflect.Ptr:
+		if val.IsNil() {
+			p.nilValue(t, implied)
+		} else {
+			p.visit(val, func() {
+				switch val.Elem().Kind() {
+				case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
+					p.buf.WriteString("&")
+					p.value(val.Elem(), depth, false)
+				default:
+					if !p.opts.GoSyntax {
+						p.buf.WriteString("&")
+						p.value(val.Elem(), depth, false)
+						return
+					}
+					// Only composite literals are addressable, so the
+					// value is taken from one
+					p.buf.WriteString("&[]" + p.typeString(t.Elem()) + "{")
+					p.value(val.Elem(), depth, true)
+					p.buf.WriteString("}[0]")
+				}
+			})
+		}
+
+	case reflect.Array:
+		p.composite(t, val.Len(), depth, func(i int) {
+			p.value(val.Index(i), depth+1, true)
+		})
+
+	case reflect.Slice:
+		if val.IsNil() {
+			p.nilValue(t, implied)
+		} else {
+			p.visit(val, func() {
+				p.composite(t, val.Len(), depth, func(i int) {
+					p.value(val.Index(i), depth+1, true)
+				})
+			})
+		}
+
+	case reflect.Map:
+		if val.IsNil() {
+			p.nilValue(t, implied)
+		} else {
+			p.visit(val, func() {
+				keys := val.MapKeys()
+				sortKeys(keys)
+				p.composite(t, len(keys), depth, func(i int) {
+					p.value(keys[i], depth+1, true)
+					p.buf.WriteString(": ")
+					p.value(val.MapIndex(keys[i]), depth+1, true)
+				})
+			})
 		}
-		str += "}"
-		return str
 
 	case reflect.Struct:
-		str := "{"
-		n  := val.NumField()
-		for i := 0; i < n; i++ {
-			if i > 0 { str += " " }
-			name  := val.Type().Field(i).Name
-			field := val.Field(i)
-			str += fmt.Sprintf("%s: %s,", name, Inspect(field))
+		// A composite literal cannot set unexported fields
+		fields := make([]int, 0, val.NumField())
+		for i := 0; i < val.NumField(); i += 1 {
+			if !p.opts.GoSyntax || t.Field(i).PkgPath == "" {
+				fields = append(fields, i)
+			}
 		}
-		str += "}"
-		return str
+		p.composite(t, len(fields), depth, func(i int) {
+			p.buf.WriteString(t.Field(fields[i]).Name + ": ")
+			p.value(val.Field(fields[i]), depth+1, true)
+		})
 
-	case reflect.Ptr:
+	case reflect.Chan:
 		if val.IsNil() {
-			return "nil"
+			p.buf.WriteString("nil")
 		} else {
-			return "&" + Inspect(reflect.Indirect(val))
+			p.buf.WriteString(fmt.Sprintf("make(%v, %d)", t, val.Cap()))
+		}
+
+	case reflect.Func:
+		if val.IsNil() {
+			p.buf.WriteString("nil")
+		} else {
+			p.buf.WriteString(t.String() + " {...}")
 		}
-	}
 
+	default:
+		p.buf.WriteString(fmt.Sprintf("%v(%#x)", t, val.Pointer()))
+	}
+}
 
-	// FIXME: add more Kinds as folks are annoyed with the output of
-	// the below:
-	if val.CanInterface() {
-		return fmt.Sprintf("%v", val.Interface())
+// nilValue writes a nil value of type t, converted to t in Go syntax
+// unless the type is implied
+func (p *inspector) nilValue(t reflect.Type, implied bool) {
+	if p.opts.GoSyntax && !implied {
+		p.buf.WriteString("(" + p.typeString(t) + ")(nil)")
 	} else {
-		return fmt.Sprintf("<%v>", val.Type())
+		p.buf.WriteString("nil")
+	}
+}
+
+// float makes the literal s of a float64 a floating-point constant in Go
+// syntax, 2 being an untyped integer constant
+func (p *inspector) float(s string) string {
+	if p.opts.GoSyntax && !strings.ContainsAny(s, ".eIN") {
+		return s + ".0"
 	}
+	return s
+}
+
+func (p *inspector) quote(s string) string {
+	if n := p.opts.MaxStringLen; n > 0 && utf8.RuneCountInString(s) > n {
+		cut := 0
+		for i := 0; i < n; i += 1 {
+			_, size := utf8.DecodeRuneInString(s[cut:])
+			cut += size
+		}
+		return strconv.QuoteToASCII(s[:cut]) + "..."
+	}
+	return strconv.QuoteToASCII(s)
+}
+
+// visit calls f unless val is already being inspected, in which case
+// the cycle is shown instead
+func (p *inspector) visit(val reflect.Value, f func()) {
+	v := visit{val.Pointer(), val.Type()}
+	if p.visiting[v] {
+		p.buf.WriteString(fmt.Sprintf("<cycle %v>", val.Type()))
+		return
+	}
+	p.visiting[v] = true
+	f()
+	delete(p.visiting, v)
+}
+
+// composite writes a composite value of type t with n elements, each
+// written by elt. Fields of a struct are not elided as elements are.
+func (p *inspector) composite(t reflect.Type, n int, depth int, elt func(i int)) {
+	limit := p.opts.MaxElements
+	if t.Kind() == reflect.Struct {
+		limit = 0
+	}
+	if p.opts.Types {
+		p.buf.WriteString(p.typeString(t))
+	}
+	p.buf.WriteString("{")
+	if n == 0 {
+		p.buf.WriteString("}")
+		return
+	} else if p.opts.MaxDepth > 0 && depth >= p.opts.MaxDepth {
+		p.buf.WriteString("...}")
+		return
+	}
+
+	for i := 0; i < n; i += 1 {
+		elided := limit > 0 && i >= limit
+		if p.opts.Multiline {
+			p.buf.WriteString("\n" + strings.Repeat(p.opts.Indent, depth+1))
+		} else if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		if elided {
+			p.buf.WriteString("...")
+		} else {
+			elt(i)
+		}
+		if p.opts.Multiline && !elided {
+			p.buf.WriteString(",")
+		}
+		if elided {
+			break
+		}
+	}
+	if p.opts.Multiline {
+		p.buf.WriteString("\n" + strings.Repeat(p.opts.Indent, depth))
+	}
+	p.buf.WriteString("}")
+}
+
+// typeString returns the name of t, as it is named in Env if it is set
+func (p *inspector) typeString(t reflect.Type) string {
+	if p.opts.Env == nil {
+		return t.String()
+	}
+	return goTypeString(t, p.opts.Env)
+}
+
+// goTypeString writes t as a Go type, named as it is in env or one of
+// its packages. Types not in env are named by reflect.
+func goTypeString(t reflect.Type, env *Env) string {
+	if env != nil {
+		if name, ok := envTypeName(t, env); ok {
+			return name
+		}
+	}
+	if t.Name() != "" {
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + goTypeString(t.Elem(), env)
+	case reflect.Slice:
+		return "[]" + goTypeString(t.Elem(), env)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), goTypeString(t.Elem(), env))
+	case reflect.Map:
+		return "map[" + goTypeString(t.Key(), env) + "]" + goTypeString(t.Elem(), env)
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + goTypeString(t.Elem(), env)
+		case reflect.SendDir:
+			return "chan<- " + goTypeString(t.Elem(), env)
+		}
+		return "chan " + goTypeString(t.Elem(), env)
+	case reflect.Func:
+		in := make([]string, t.NumIn())
+		for i := range in {
+			if t.IsVariadic() && i == len(in)-1 {
+				in[i] = "..." + goTypeString(t.In(i).Elem(), env)
+			} else {
+				in[i] = goTypeString(t.In(i), env)
+			}
+		}
+		out := make([]string, t.NumOut())
+		for i := range out {
+			out[i] = goTypeString(t.Out(i), env)
+		}
+		s := "func(" + strings.Join(in, ", ") + ")"
+		if len(out) == 1 {
+			s += " " + out[0]
+		} else if len(out) > 1 {
+			s += " (" + strings.Join(out, ", ") + ")"
+		}
+		return s
+	case reflect.Struct:
+		fields := make([]string, t.NumField())
+		for i := range fields {
+			f := t.Field(i)
+			if f.Anonymous {
+				fields[i] = goTypeString(f.Type, env)
+			} else {
+				fields[i] = f.Name + " " + goTypeString(f.Type, env)
+			}
+			if f.Tag != "" {
+				fields[i] += " " + strconv.Quote(string(f.Tag))
+			}
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+	return t.String()
+}
+
+// envTypeName returns the name of t in env or one of its packages, the
+// first in order if there are several
+func envTypeName(t reflect.Type, env *Env) (string, bool) {
+	envLock.RLock()
+	defer envLock.RUnlock()
+
+	var names []string
+	for name, u := range env.Types {
+		if unhackType(u) == t {
+			names = append(names, name)
+		}
+	}
+	for pkgName, pkg := range env.Pkgs {
+		for name, u := range (*Env)(pkg).Types {
+			if unhackType(u) == t {
+				names = append(names, pkgName+"."+name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
+
+// sortKeys sorts the keys of a map, numbers and strings by value and
+// others by their output
+func sortKeys(keys []reflect.Value) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		}
+		return InspectWith(a, InspectOptions{Types: true}) < InspectWith(b, InspectOptions{Types: true})
+	})
 }
@@ -0,0 +1,140 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+
+	"go/parser"
+)
+
+type roundTripPoint struct {
+	X, Y int
+}
+
+type roundTripShape struct {
+	Name   string
+	Points []roundTripPoint
+	Tags   map[string]bool
+	Origin *roundTripPoint
+	Scale  float32
+	Any    interface{}
+	RoundTripPoint
+}
+
+// Embedded fields must be exported to be set by a literal
+type RoundTripPoint roundTripPoint
+
+type roundTripCelsius float64
+
+type roundTripAccount struct {
+	Owner   string
+	balance int
+}
+
+type roundTripIDs []uint16
+
+// expectRoundTrip inspects v in Go syntax, with each layout, and expects
+// the output to parse, check and evaluate in env back to v
+func expectRoundTrip(t *testing.T, v interface{}, env *Env) {
+	for _, multiline := range []bool{false, true} {
+		opts := InspectOptions{GoSyntax: true, Env: env, Multiline: multiline}
+		expr := InspectWith(reflect.ValueOf(v), opts)
+		ctx := &Ctx{Input: expr}
+		if e, err := parser.ParseExpr(expr); err != nil {
+			t.Fatalf("Failed to parse inspected %#v '%s' (%v)", v, expr, err)
+		} else if aexpr, errs := CheckExpr(ctx, e, env); errs != nil {
+			t.Fatalf("Failed to check inspected %#v '%s' (%v)", v, expr, errs)
+		} else if results, typed, err := EvalExpr(ctx, aexpr, env); err != nil {
+			t.Fatalf("Failed to evaluate inspected %#v '%s' (%v)", v, expr, err)
+		} else if results == nil || len(*results) != 1 {
+			t.Fatalf("Inspected %#v '%s' yielded %v", v, expr, results)
+		} else if actual := defaultValue((*results)[0], typed).Interface(); !reflect.DeepEqual(actual, v) {
+			t.Fatalf("Inspected %#v '%s' yielded %#v", v, expr, actual)
+		}
+	}
+}
+
+func TestInspectRoundTripBasic(t *testing.T) {
+	env := makeEnv()
+	units := makeEnv()
+	units.Types["Celsius"] = reflect.TypeOf(roundTripCelsius(0))
+	env.Pkgs["units"] = units
+	for _, v := range []interface{}{
+		42, -7, int8(-128), int64(1) << 40, uint(3), uint8(255), uintptr(9),
+		2.5, 2.0, -0.125, float32(0.1), 1e100,
+		1 + 2i, complex64(3i),
+		true, false,
+		"", "go", "tab\there \"quoted\" é \x00",
+		'x',
+		roundTripCelsius(36.6),
+	} {
+		expectRoundTrip(t, v, env)
+	}
+}
+
+func TestInspectRoundTripComposite(t *testing.T) {
+	env := makeEnv()
+	env.Types["Point"] = reflect.TypeOf(roundTripPoint{})
+	env.Types["Shape"] = reflect.TypeOf(roundTripShape{})
+	env.Types["RoundTripPoint"] = reflect.TypeOf(RoundTripPoint{})
+	env.Types["IDs"] = reflect.TypeOf(roundTripIDs{})
+	units := makeEnv()
+	units.Types["Celsius"] = reflect.TypeOf(roundTripCelsius(0))
+	env.Pkgs["units"] = units
+	seven := 7
+	for _, v := range []interface{}{
+		[]int{1, 2, 3},
+		[]int{},
+		[]int(nil),
+		[3]string{"a", "b"},
+		[][]float64{{1, 2.5}, nil},
+		map[string]int{"b": 2, "a": 1},
+		map[int][]string{1: {"x"}},
+		map[string]int(nil),
+		roundTripPoint{1, 2},
+		&roundTripPoint{3, 4},
+		(*roundTripPoint)(nil),
+		&seven,
+		roundTripIDs{1, 2},
+		[]roundTripCelsius{1.5},
+		[]interface{}{1, "a", uint8(2), nil, roundTripPoint{}},
+		roundTripShape{
+			Name:           "square",
+			Points:         []roundTripPoint{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+			Tags:           map[string]bool{"closed": true},
+			Origin:         &roundTripPoint{5, 5},
+			Scale:          0.5,
+			Any:            roundTripCelsius(-40),
+			RoundTripPoint: RoundTripPoint{9, 9},
+		},
+	} {
+		expectRoundTrip(t, v, env)
+	}
+}
+
+func TestInspectGoSyntaxTypeNames(t *testing.T) {
+	env := makeEnv()
+	env.Types["Point"] = reflect.TypeOf(roundTripPoint{})
+	units := makeEnv()
+	units.Types["Celsius"] = reflect.TypeOf(roundTripCelsius(0))
+	env.Pkgs["units"] = units
+	opts := InspectOptions{GoSyntax: true, Env: env}
+	expectInspect(t, map[roundTripPoint][]*roundTripCelsius{}, opts, "map[Point][]*units.Celsius{}")
+	expectInspect(t, struct {
+		A func(int, ...string) (bool, error)
+	}{}, opts,
+		"struct{A func(int, ...string) (bool, error)}{A: nil}")
+	expectInspect(t, []<-chan int{nil}, opts, "[]<-chan int{nil}")
+	expectInspect(t, 2.0, opts, "2.0")
+}
+
+func TestInspectGoSyntaxUnexported(t *testing.T) {
+	env := makeEnv()
+	env.Types["Account"] = reflect.TypeOf(roundTripAccount{})
+	expectRoundTrip(t, roundTripAccount{Owner: "bob"}, env)
+
+	// Unexported fields cannot be set by the literal, and are omitted
+	opts := InspectOptions{GoSyntax: true, Env: env}
+	expectInspect(t, roundTripAccount{"bob", 10}, opts, `Account{Owner: "bob"}`)
+	expectInspect(t, roundTripAccount{"bob", 10}, InspectOptions{}, `{Owner: "bob", balance: 10}`)
+}
@@ -0,0 +1,111 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+type inspectAlice struct {
+	Bob   int
+	Carol []string
+	Dave  map[string]inspectCelsius
+}
+
+type inspectCelsius float64
+
+type inspectNode struct {
+	Value int
+	Next  *inspectNode
+}
+
+func expectInspect(t *testing.T, v interface{}, opts InspectOptions, expected string) {
+	if actual := InspectWith(reflect.ValueOf(v), opts); actual != expected {
+		t.Fatalf("Inspect %#v yielded\n%s\nexpected\n%s", v, actual, expected)
+	}
+}
+
+func TestInspectBasic(t *testing.T) {
+	expectInspect(t, 42, InspectOptions{}, "42")
+	expectInspect(t, uint8(200), InspectOptions{}, "200")
+	expectInspect(t, 2.5, InspectOptions{}, "2.5")
+	expectInspect(t, float32(0.1), InspectOptions{}, "0.1")
+	expectInspect(t, 1+2i, InspectOptions{}, "(1+2i)")
+	expectInspect(t, true, InspectOptions{}, "true")
+	expectInspect(t, "a\tb", InspectOptions{}, `"a\tb"`)
+	expectInspect(t, nil, InspectOptions{}, "nil")
+	expectInspect(t, inspectCelsius(36.6), InspectOptions{Types: true}, "eval.inspectCelsius(36.6)")
+}
+
+func TestInspectComposite(t *testing.T) {
+	alice := inspectAlice{Bob: 1, Carol: []string{"x"}, Dave: map[string]inspectCelsius{"b": 2, "a": 1}}
+	expectInspect(t, alice, InspectOptions{},
+		`{Bob: 1, Carol: {"x"}, Dave: {"a": 1, "b": 2}}`)
+	expectInspect(t, &alice, InspectOptions{Types: true},
+		`&eval.inspectAlice{Bob: 1, Carol: []string{"x"}, Dave: map[string]eval.inspectCelsius{"a": 1, "b": 2}}`)
+	expectInspect(t, []interface{}{inspectCelsius(1), nil}, InspectOptions{Types: true},
+		`[]interface {}{eval.inspectCelsius(1), nil}`)
+	expectInspect(t, map[int]bool{10: true, 9: false}, InspectOptions{}, "{9: false, 10: true}")
+	expectInspect(t, [0]int{}, InspectOptions{Types: true}, "[0]int{}")
+}
+
+func TestInspectOther(t *testing.T) {
+	expectInspect(t, make(chan int, 2), InspectOptions{}, "make(chan int, 2)")
+	expectInspect(t, func(int) string { return "" }, InspectOptions{}, "func(int) string {...}")
+	expectInspect(t, []int(nil), InspectOptions{}, "nil")
+	expectInspect(t, (*int)(nil), InspectOptions{}, "nil")
+}
+
+func TestInspectPtr(t *testing.T) {
+	n := 1
+	for _, c := range []struct {
+		v        reflect.Value
+		expected string
+	}{
+		{reflect.ValueOf(&n), "&1"},
+		{reflect.ValueOf((*int)(nil)), "nil"},
+		{reflect.Value{}, "nil"},
+		{reflect.ValueOf(n), "1"},
+	} {
+		if actual := InspectPtr(c.v); actual != c.expected {
+			t.Fatalf("InspectPtr %v yielded %s, expected %s", c.v, actual, c.expected)
+		}
+	}
+}
+
+func TestInspectCycle(t *testing.T) {
+	n := &inspectNode{Value: 1}
+	n.Next = &inspectNode{Value: 2, Next: n}
+	expectInspect(t, n, InspectOptions{},
+		"&{Value: 1, Next: &{Value: 2, Next: <cycle *eval.inspectNode>}}")
+
+	// Values shared without a cycle are shown each time
+	shared := &inspectNode{Value: 3}
+	expectInspect(t, []*inspectNode{shared, shared}, InspectOptions{},
+		"{&{Value: 3, Next: nil}, &{Value: 3, Next: nil}}")
+
+	m := map[string]interface{}{}
+	m["m"] = m
+	expectInspect(t, m, InspectOptions{}, `{"m": <cycle map[string]interface {}>}`)
+}
+
+func TestInspectLimits(t *testing.T) {
+	nested := [][]int{{1, 2, 3}, {4}}
+	expectInspect(t, nested, InspectOptions{MaxDepth: 1}, "{{...}, {...}}")
+	expectInspect(t, nested, InspectOptions{MaxElements: 2}, "{{1, 2, ...}, {4}}")
+	expectInspect(t, "héllo", InspectOptions{MaxStringLen: 2}, `"h\u00e9"...`)
+	expectInspect(t, "hi", InspectOptions{MaxStringLen: 2}, `"hi"`)
+}
+
+func TestInspectMultiline(t *testing.T) {
+	alice := inspectAlice{Bob: 1, Carol: []string{"x", "y", "z"}}
+	expectInspect(t, alice, InspectOptions{Types: true, Multiline: true, Indent: "  ", MaxElements: 2},
+		`eval.inspectAlice{
+  Bob: 1,
+  Carol: []string{
+    "x",
+    "y",
+    ...
+  },
+  Dave: nil,
+}`)
+}
@@ -0,0 +1,255 @@
+package eval
+
+import (
+	"go/parser"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// InspectNode is a value laid out as a tree for front ends which expand
+// values a level at a time. Its children are computed by Expand, and
+// are each addressed by Path, an expression evaluating to the child in
+// the Env the root was evaluated in. A front end holding only the JSON
+// encoding of a node passes Path to InspectPath to fetch its children.
+type InspectNode struct {
+	// Path evaluates to the value, or is empty if it has no expression,
+	// as for entries of a map keyed by pointers. Paths through
+	// unexported fields need Ctx.Unexported to be evaluated.
+	Path string `json:"path"`
+
+	// Name labels the value within its parent: a field name, an index
+	// or key as [1] or ["a"], * for the target of a pointer and the
+	// dynamic type for the value of an interface
+	Name string `json:"name,omitempty"`
+
+	Kind    string `json:"kind"`
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+
+	// Len is the number of children, which Expand may truncate
+	Len int `json:"len"`
+
+	// Children is nil until Expand is called
+	Children []*InspectNode `json:"children,omitempty"`
+
+	val  reflect.Value
+	opts InspectOptions
+}
+
+// InspectTree returns the root node of val, which path evaluates to. The
+// summary of each node is InspectWith on a single line and a level
+// deep, with opts otherwise as given. Types are named as opts.Env names
+// them, if it is set.
+func InspectTree(val reflect.Value, path string, opts InspectOptions) *InspectNode {
+	opts.Multiline = false
+	if opts.MaxDepth == 0 || opts.MaxDepth > 1 {
+		opts.MaxDepth = 1
+	}
+	return newInspectNode(val, path, "", opts)
+}
+
+// InspectPath evaluates path in env and returns its node, as InspectTree
+// does. ctx is copied, with Input set to path. opts.Env defaults to env.
+func InspectPath(ctx *Ctx, path string, env *Env, opts InspectOptions) (*InspectNode, []error) {
+	if opts.Env == nil {
+		opts.Env = env
+	}
+	cctx := *ctx
+	cctx.Input, cctx.Fset, cctx.Base = path, nil, 0
+	ctx = &cctx
+	expr, err := parser.ParseExpr(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+	aexpr, errs := CheckExpr(ctx, expr, env)
+	if errs != nil {
+		return nil, errs
+	}
+	values, typed, err := EvalExpr(ctx, aexpr, env)
+	if err != nil {
+		return nil, []error{err}
+	}
+	var value reflect.Value
+	if values != nil {
+		if value, err = expectSingleValue(ctx, *values, aexpr); err != nil {
+			return nil, []error{err}
+		}
+	}
+	return InspectTree(defaultValue(value, typed), path, opts), nil
+}
+
+func newInspectNode(val reflect.Value, path, name string, opts InspectOptions) *InspectNode {
+	n := &InspectNode{Path: path, Name: name, val: val, opts: opts}
+	n.Summary = InspectWith(val, opts)
+	if !val.IsValid() {
+		n.Kind, n.Type = "invalid", "nil"
+		return n
+	}
+	n.Kind, n.Type = val.Kind().String(), goTypeString(val.Type(), opts.Env)
+
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		n.Len = val.Len()
+	case reflect.Struct:
+		n.Len = val.NumField()
+	case reflect.Ptr, reflect.Interface:
+		if !val.IsNil() {
+			n.Len = 1
+		}
+	}
+	return n
+}
+
+// Expand computes the children of n, and returns them. Elements of an
+// array, slice or map beyond the first MaxElements of the options n was
+// inspected with are left out, but can be reached through InspectPath.
+func (n *InspectNode) Expand() []*InspectNode {
+	if n.Children != nil || n.Len == 0 {
+		return n.Children
+	}
+	val := n.val
+	count := n.Len
+	if limit := n.opts.MaxElements; limit > 0 && count > limit && val.Kind() != reflect.Struct {
+		count = limit
+	}
+	children := make([]*InspectNode, 0, count)
+	child := func(v reflect.Value, suffix, name string) {
+		path := ""
+		if n.Path != "" && suffix != "" {
+			path = n.Path + suffix
+		}
+		children = append(children, newInspectNode(v, path, name, n.opts))
+	}
+
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < count; i += 1 {
+			index := "[" + strconv.Itoa(i) + "]"
+			child(val.Index(i), index, index)
+		}
+	case reflect.Map:
+		keys := val.MapKeys()
+		sortKeys(keys)
+		for _, key := range keys[:count] {
+			index := ""
+			if hasLiteral(key) {
+				index = "[" + InspectWith(key, InspectOptions{GoSyntax: true, Env: n.opts.Env}) + "]"
+			}
+			child(val.MapIndex(key), index, "["+InspectWith(key, n.opts)+"]")
+		}
+	case reflect.Struct:
+		t := val.Type()
+		for i := 0; i < count; i += 1 {
+			name := t.Field(i).Name
+			child(val.Field(i), "."+name, name)
+		}
+	case reflect.Ptr:
+		children = append(children, newInspectNode(val.Elem(), derefPath(n.Path), "*", n.opts))
+	case reflect.Interface:
+		et := val.Elem().Type()
+		t := goTypeString(et, n.opts.Env)
+		assert := ""
+		if typeResolves(et, n.opts.Env) {
+			assert = ".(" + t + ")"
+		}
+		child(val.Elem(), assert, t)
+	}
+	n.Children = children
+	return children
+}
+
+// Value returns the value n was inspected from
+func (n *InspectNode) Value() reflect.Value {
+	return n.val
+}
+
+func derefPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return "(*" + path + ")"
+}
+
+// typeResolves reports whether goTypeString writes t with names which
+// resolve in env, so that it may be used in a type assertion in a path
+func typeResolves(t reflect.Type, env *Env) bool {
+	if env != nil {
+		if _, ok := envTypeName(t, env); ok {
+			return true
+		}
+	}
+	if t.Name() != "" {
+		// Predeclared
+		return t.PkgPath() == ""
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		return typeResolves(t.Elem(), env)
+	case reflect.Map:
+		return typeResolves(t.Key(), env) && typeResolves(t.Elem(), env)
+	case reflect.Func:
+		for i := 0; i < t.NumIn(); i += 1 {
+			if !typeResolves(t.In(i), env) {
+				return false
+			}
+		}
+		for i := 0; i < t.NumOut(); i += 1 {
+			if !typeResolves(t.Out(i), env) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i += 1 {
+			if f := t.Field(i); f.PkgPath != "" || !typeResolves(f.Type, env) {
+				return false
+			}
+		}
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	}
+	return false
+}
+
+// hasLiteral reports whether InspectWith in Go syntax writes v as an
+// expression which evaluates back to it, so it may be used as a map key
+// in a path
+func hasLiteral(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Float32, reflect.Float64:
+		return isFinite(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return isFinite(real(c)) && isFinite(imag(c))
+	case reflect.Interface:
+		return v.IsNil() || hasLiteral(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i += 1 {
+			if !hasLiteral(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i += 1 {
+			if t.Field(i).PkgPath != "" || !hasLiteral(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
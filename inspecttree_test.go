@@ -0,0 +1,153 @@
+package eval
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func inspectPath(t *testing.T, path string, env *Env, opts InspectOptions) *InspectNode {
+	node, errs := InspectPath(&Ctx{}, path, env, opts)
+	if errs != nil {
+		t.Fatalf("Failed to inspect path '%s' (%v)", path, errs)
+	}
+	return node
+}
+
+// expectTreePaths expands node depth levels deep, and expects the path
+// of each child to evaluate to that child
+func expectTreePaths(t *testing.T, node *InspectNode, env *Env, depth int) {
+	if depth == 0 {
+		return
+	}
+	for _, child := range node.Expand() {
+		if child.Path == "" {
+			continue
+		}
+		actual := inspectPath(t, child.Path, env, InspectOptions{})
+		if !reflect.DeepEqual(inspectValue(actual), inspectValue(child)) {
+			t.Fatalf("Path '%s' evaluated to %v, expected %v", child.Path, actual.Summary, child.Summary)
+		}
+		expectTreePaths(t, child, env, depth-1)
+	}
+}
+
+func inspectValue(node *InspectNode) interface{} {
+	if v := node.Value(); v.IsValid() {
+		return v.Interface()
+	}
+	return nil
+}
+
+func expectChild(t *testing.T, node *InspectNode, i int, path, name, summary string) *InspectNode {
+	children := node.Expand()
+	if i >= len(children) {
+		t.Fatalf("Node '%s' has %d children, expected child %d", node.Path, len(children), i)
+	}
+	child := children[i]
+	if child.Path != path || child.Name != name || child.Summary != summary {
+		t.Fatalf("Child %d of '%s' is %q %q %q, expected %q %q %q", i, node.Path,
+			child.Path, child.Name, child.Summary, path, name, summary)
+	}
+	return child
+}
+
+func TestInspectTreePaths(t *testing.T) {
+	shape := roundTripShape{
+		Name:           "square",
+		Points:         []roundTripPoint{{0, 0}, {0, 1}, {1, 1}, {1, 0}},
+		Tags:           map[string]bool{"closed": true, "filled": false},
+		Origin:         &roundTripPoint{5, 5},
+		Scale:          0.5,
+		Any:            roundTripCelsius(-40),
+		RoundTripPoint: RoundTripPoint{9, 9},
+	}
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&shape)
+	env.Types["Point"] = reflect.TypeOf(roundTripPoint{})
+	env.Types["Shape"] = reflect.TypeOf(roundTripShape{})
+	env.Types["RoundTripPoint"] = reflect.TypeOf(RoundTripPoint{})
+	units := makeEnv()
+	units.Types["Celsius"] = reflect.TypeOf(roundTripCelsius(0))
+	env.Pkgs["units"] = units
+	root := inspectPath(t, "s", env, InspectOptions{Types: true})
+
+	if root.Kind != "struct" || root.Type != "Shape" || root.Len != 7 {
+		t.Fatalf("Root is %s %s with %d children", root.Kind, root.Type, root.Len)
+	}
+	expectTreePaths(t, root, env, 3)
+
+	expectChild(t, root, 0, `s.Name`, "Name", `"square"`)
+	points := expectChild(t, root, 1, `s.Points`, "Points", `[]Point{Point{...}, Point{...}, Point{...}, Point{...}}`)
+	expectChild(t, points, 2, `s.Points[2]`, "[2]", `Point{X: 1, Y: 1}`)
+	tags := expectChild(t, root, 2, `s.Tags`, "Tags", `map[string]bool{"closed": true, "filled": false}`)
+	expectChild(t, tags, 1, `s.Tags["filled"]`, `["filled"]`, `false`)
+	origin := expectChild(t, root, 3, `s.Origin`, "Origin", `&Point{X: 5, Y: 5}`)
+	expectChild(t, origin, 0, `(*s.Origin)`, "*", `Point{X: 5, Y: 5}`)
+	any := expectChild(t, root, 5, `s.Any`, "Any", `units.Celsius(-40)`)
+	expectChild(t, any, 0, `s.Any.(units.Celsius)`, "units.Celsius", `units.Celsius(-40)`)
+}
+
+func TestInspectTreeUnaddressable(t *testing.T) {
+	byPtr := map[*roundTripPoint]int{&roundTripPoint{1, 2}: 3}
+	env := makeEnv()
+	env.Vars["byPtr"] = reflect.ValueOf(&byPtr)
+	root := inspectPath(t, "byPtr", env, InspectOptions{})
+	entry := expectChild(t, root, 0, "", "[&{X: 1, Y: 2}]", "3")
+	if entry.Len != 0 || entry.Expand() != nil {
+		t.Fatalf("Entry of map has children %v", entry.Children)
+	}
+
+	// Children have no paths if their parent has none
+	point := InspectTree(reflect.ValueOf(roundTripPoint{1, 2}), "", InspectOptions{})
+	expectChild(t, point, 1, "", "Y", "2")
+}
+
+type inspectTreeHidden int
+
+func TestInspectTreeTypeAssertPaths(t *testing.T) {
+	env := makeEnv()
+	var ints, hidden interface{} = []int{1}, inspectTreeHidden(3)
+	env.Vars["ints"] = reflect.ValueOf(&ints)
+	env.Vars["hidden"] = reflect.ValueOf(&hidden)
+
+	expectChild(t, inspectPath(t, "ints", env, InspectOptions{}), 0, "ints.([]int)", "[]int", "{1}")
+
+	// The type is not named in env, so cannot be asserted
+	expectChild(t, inspectPath(t, "hidden", env, InspectOptions{}), 0, "", "eval.inspectTreeHidden", "3")
+}
+
+func TestInspectTreeLimits(t *testing.T) {
+	ids := roundTripIDs{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	env := makeEnv()
+	env.Vars["ids"] = reflect.ValueOf(&ids)
+	root := inspectPath(t, "ids", env, InspectOptions{MaxElements: 3})
+	if root.Len != 10 || len(root.Expand()) != 3 {
+		t.Fatalf("Expanded %d of %d children, expected 3 of 10", len(root.Children), root.Len)
+	}
+	expectChild(t, root, 2, "ids[2]", "[2]", "3")
+
+	if _, errs := InspectPath(&Ctx{}, "ids[", env, InspectOptions{}); errs == nil {
+		t.Fatalf("Expected a parse error")
+	}
+	if _, errs := InspectPath(&Ctx{}, "nosuch", env, InspectOptions{}); errs == nil {
+		t.Fatalf("Expected a check error")
+	}
+}
+
+func TestInspectTreeJSON(t *testing.T) {
+	env := makeEnv()
+	env.Types["Point"] = reflect.TypeOf(roundTripPoint{})
+	env.Vars["p"] = reflect.ValueOf(&roundTripPoint{1, 2})
+	root := inspectPath(t, "p", env, InspectOptions{Types: true})
+	root.Expand()
+
+	expected := `{"path":"p","kind":"struct","type":"Point","summary":"Point{X: 1, Y: 2}","len":2,"children":[` +
+		`{"path":"p.X","name":"X","kind":"int","type":"int","summary":"1","len":0},` +
+		`{"path":"p.Y","name":"Y","kind":"int","type":"int","summary":"2","len":0}]}`
+	if b, err := json.Marshal(root); err != nil {
+		t.Fatalf("Failed to encode node (%v)", err)
+	} else if string(b) != expected {
+		t.Fatalf("Encoded %s, expected %s", b, expected)
+	}
+}
@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterfaceNil(t *testing.T) {
+	var r io.Reader = strings.NewReader("abc")
+	var none io.Reader
+	var typedNil io.Reader = (*strings.Reader)(nil)
+	var slice interface{} = []int{1}
+	env := makeEnv()
+	env.Vars["r"] = reflect.ValueOf(&r)
+	env.Vars["none"] = reflect.ValueOf(&none)
+	env.Vars["typedNil"] = reflect.ValueOf(&typedNil)
+	env.Vars["slice"] = reflect.ValueOf(&slice)
+
+	expectResult(t, "r == nil", env, false)
+	expectResult(t, "none == nil", env, true)
+	expectResult(t, "nil != none", env, false)
+	// An interface holding a nil pointer is not nil
+	expectResult(t, "typedNil == nil", env, false)
+	expectResult(t, "r == r", env, true)
+	expectResult(t, "r == none", env, false)
+	expectError(t, "slice == slice", env, "panic: runtime error: comparing uncomparable type []int")
+}
+
+func TestInterfaceMethods(t *testing.T) {
+	var r io.Reader = strings.NewReader("abc")
+	var none io.Reader
+	var err error = errors.New("failed")
+	env := makeEnv()
+	env.Vars["r"] = reflect.ValueOf(&r)
+	env.Vars["none"] = reflect.ValueOf(&none)
+	env.Vars["err"] = reflect.ValueOf(&err)
+
+	expectResult(t, "err.Error()", env, "failed")
+	expectError(t, "none.Read(nil)", env,
+		"panic: runtime error: invalid memory address or nil pointer dereference")
+	expectCheckError(t, "r.Len()", env, "r.Len undefined (type io.Reader has no field or method Len)")
+}
+
+func TestTypeAssert(t *testing.T) {
+	var r io.Reader = strings.NewReader("abc")
+	var none io.Reader
+	var err error = errors.New("failed")
+	var any interface{} = 1
+	n := 1
+	env := makeEnv()
+	env.Vars["r"] = reflect.ValueOf(&r)
+	env.Vars["none"] = reflect.ValueOf(&none)
+	env.Vars["err"] = reflect.ValueOf(&err)
+	env.Vars["any"] = reflect.ValueOf(&any)
+	env.Vars["n"] = reflect.ValueOf(&n)
+	ioPkg := makeEnv()
+	ioPkg.Name, ioPkg.Path = "io", "io"
+	ioPkg.Types["Reader"] = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	ioPkg.Types["Writer"] = reflect.TypeOf((*io.Writer)(nil)).Elem()
+	ioPkg.Types["ByteReader"] = reflect.TypeOf((*io.ByteReader)(nil)).Elem()
+	env.Pkgs["io"] = ioPkg
+	stringsPkg := makeEnv()
+	stringsPkg.Name, stringsPkg.Path = "strings", "strings"
+	stringsPkg.Types["Reader"] = reflect.TypeOf(strings.Reader{})
+	stringsPkg.Types["Builder"] = reflect.TypeOf(strings.Builder{})
+	env.Pkgs["strings"] = stringsPkg
+
+	expectResult(t, "any.(int)", env, 1)
+	expectResult(t, "r.(*strings.Reader).Len()", env, 3)
+	expectResult(t, "r.(io.ByteReader) != nil", env, true)
+	expectResult(t, "func() bool {\n\t_, ok := r.(io.Writer)\n\treturn ok\n}()", env, false)
+	expectResult(t, "func() int {\n\tn, ok := any.(int)\n\tif !ok {\n\t\treturn -1\n\t}\n\treturn n\n}()", env, 1)
+
+	expectError(t, "any.(string)", env, "panic: interface conversion: interface {} is int, not string")
+	expectError(t, "none.(*strings.Reader)", env,
+		"panic: interface conversion: io.Reader is nil, not *strings.Reader")
+	expectError(t, "r.(io.Writer)", env,
+		"panic: interface conversion: *strings.Reader is not io.Writer: missing method Write")
+
+	expectCheckError(t, "r.(strings.Reader)", env,
+		"impossible type assertion: strings.Reader does not implement io.Reader (missing Read method)")
+	expectCheckError(t, "n.(int)", env, "invalid type assertion: n (non-interface type int on left)")
+	expectError(t, "err.Error().(string)", env,
+		"invalid type assertion: err.Error() (non-interface type string on left)")
+	expectCheckError(t, "r.(type)", env, "use of .(type) outside type switch")
+}
+
+func TestTypeAssertionError(t *testing.T) {
+	var any interface{}
+	err := &TypeAssertionError{reflect.TypeOf(&any).Elem(), reflect.TypeOf(1), reflect.TypeOf(""), ""}
+	if s := fmt.Sprint(err); s != "interface conversion: interface {} is int, not string" {
+		t.Fatalf("Unexpected message %s", s)
+	}
+}
+
+func TestTypeSwitch(t *testing.T) {
+	var r io.Reader = strings.NewReader("abc")
+	var none io.Reader
+	var err error = errors.New("failed")
+	var any interface{} = 1
+	var slice interface{} = []int{1}
+	n := 1
+	env := makeEnv()
+	env.Vars["r"] = reflect.ValueOf(&r)
+	env.Vars["none"] = reflect.ValueOf(&none)
+	env.Vars["err"] = reflect.ValueOf(&err)
+	env.Vars["any"] = reflect.ValueOf(&any)
+	env.Vars["slice"] = reflect.ValueOf(&slice)
+	env.Vars["n"] = reflect.ValueOf(&n)
+	ioPkg := makeEnv()
+	ioPkg.Name, ioPkg.Path = "io", "io"
+	ioPkg.Types["Reader"] = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	ioPkg.Types["Writer"] = reflect.TypeOf((*io.Writer)(nil)).Elem()
+	ioPkg.Types["ByteReader"] = reflect.TypeOf((*io.ByteReader)(nil)).Elem()
+	env.Pkgs["io"] = ioPkg
+	stringsPkg := makeEnv()
+	stringsPkg.Name, stringsPkg.Path = "strings", "strings"
+	stringsPkg.Types["Reader"] = reflect.TypeOf(strings.Reader{})
+	stringsPkg.Types["Builder"] = reflect.TypeOf(strings.Builder{})
+	env.Pkgs["strings"] = stringsPkg
+	kind := "func(x interface{}) string {\n" +
+		"\tswitch v := x.(type) {\n" +
+		"\tcase nil:\n\t\treturn \"nil\"\n" +
+		"\tcase int:\n\t\tif v+1 == 2 {\n\t\t\treturn \"one\"\n\t\t}\n\t\treturn \"int\"\n" +
+		"\tcase *strings.Reader, []int:\n\t\treturn \"reader or slice\"\n" +
+		"\tcase io.Reader:\n\t\treturn \"reader\"\n" +
+		"\tdefault:\n\t\treturn \"other\"\n" +
+		"\t}\n}"
+
+	expectResult(t, kind+"(any)", env, "one")
+	expectResult(t, kind+"(2)", env, "int")
+	expectResult(t, kind+"(none)", env, "nil")
+	expectResult(t, kind+"(r)", env, "reader or slice")
+	expectResult(t, kind+"(slice)", env, "reader or slice")
+	expectResult(t, kind+"(err)", env, "other")
+	// The variable of a clause of a single type has that type, and
+	// otherwise the type of x
+	expectResult(t, "func() int {\n\tswitch v := r.(type) {\n\tcase *strings.Reader:\n\t\treturn v.Len()\n\t}\n\treturn 0\n}()", env, 3)
+	expectResult(t, "func() bool {\n\tswitch v := r.(type) {\n\tcase *strings.Reader, error:\n\t\treturn v == r\n\t}\n\treturn false\n}()", env, true)
+	expectResult(t, "func() int {\n\tswitch err.(type) {\n\tcase io.Reader:\n\t\treturn 1\n\tcase error:\n\t\treturn 2\n\t}\n\treturn 0\n}()", env, 2)
+
+	expectCheckError(t, "func() {\n\tswitch n.(type) {\n\t}\n}", env, "n (type int) is not an interface")
+	expectCheckError(t, "func() {\n\tswitch r.(type) {\n\tcase strings.Builder:\n\t}\n}", env,
+		"impossible type assertion: strings.Builder does not implement io.Reader (missing Read method)")
+	expectCheckError(t, "func() {\n\tswitch r.(type) {\n\tdefault:\n\tdefault:\n\t}\n}", env, "multiple defaults in switch")
+}
@@ -0,0 +1,103 @@
+package eval
+
+import (
+	"math"
+	"reflect"
+	"sync/atomic"
+)
+
+// Limits bounds the resources used by evaluation, for evaluating code
+// which is not trusted. A limit of zero is not enforced. Usage is
+// counted in the Limits and never reset, so Nodes and Bytes bound every
+// evaluation made with a Limits together. Use a new Limits for each
+// evaluation to bound them separately. It may be shared by goroutines.
+type Limits struct {
+	// Nodes bounds the number of expressions and statements evaluated
+	Nodes int64
+
+	// CallDepth bounds the number of calls of interpreted functions in
+	// progress at once, including those of goroutines
+	CallDepth int64
+
+	// Len bounds the length of each slice, map, channel buffer or string
+	// created by make, append, a composite literal or a string
+	// concatenation
+	Len int
+
+	// Bytes bounds the total size of the values allocated by make,
+	// append, new, composite literals, var declarations and string
+	// concatenation. The size of a map entry is taken to be that of its
+	// key and value. Len and Bytes also bound the length and size of
+	// array types, so that no value of one exceeds them.
+	Bytes int64
+
+	nodes, depth, bytes int64
+}
+
+// countNode is called as each expression or statement is evaluated
+func (limits *Limits) countNode() error {
+	if limits == nil || limits.Nodes == 0 {
+		return nil
+	} else if atomic.AddInt64(&limits.nodes, 1) > limits.Nodes {
+		return ErrLimitExceeded{"Nodes", limits.Nodes}
+	}
+	return nil
+}
+
+// enterCall is called as an interpreted function is called. If it
+// succeeds, exitCall must be called when the function returns.
+func (limits *Limits) enterCall() error {
+	if limits == nil || limits.CallDepth == 0 {
+		return nil
+	} else if atomic.AddInt64(&limits.depth, 1) > limits.CallDepth {
+		atomic.AddInt64(&limits.depth, -1)
+		return ErrLimitExceeded{"CallDepth", limits.CallDepth}
+	}
+	return nil
+}
+
+func (limits *Limits) exitCall() {
+	if limits != nil && limits.CallDepth != 0 {
+		atomic.AddInt64(&limits.depth, -1)
+	}
+}
+
+// allocate is called before a value of length n, occupying size bytes,
+// is allocated
+func (limits *Limits) allocate(n int, size int64) error {
+	if limits == nil {
+		return nil
+	} else if limits.Len != 0 && n > limits.Len {
+		return ErrLimitExceeded{"Len", int64(limits.Len)}
+	} else if limits.Bytes != 0 && (size > limits.Bytes || atomic.AddInt64(&limits.bytes, size) > limits.Bytes) {
+		return ErrLimitExceeded{"Bytes", limits.Bytes}
+	}
+	return nil
+}
+
+// allocateVar is called before a variable of type t is allocated
+func (limits *Limits) allocateVar(t reflect.Type) error {
+	return limits.allocate(0, int64(t.Size()))
+}
+
+// checkArrayType is called before the array type of n elements of type
+// elt is made. Allocations of its values are counted as they are made.
+func (limits *Limits) checkArrayType(n int, elt reflect.Type) error {
+	if limits == nil {
+		return nil
+	} else if limits.Len != 0 && n > limits.Len {
+		return ErrLimitExceeded{"Len", int64(limits.Len)}
+	} else if limits.Bytes != 0 && allocSize(n, elt.Size()) > limits.Bytes {
+		return ErrLimitExceeded{"Bytes", limits.Bytes}
+	}
+	return nil
+}
+
+// allocSize returns the size of n values of size bytes each, which is
+// math.MaxInt64 if it overflows
+func allocSize(n int, size uintptr) int64 {
+	if n > 0 && uint64(size) > uint64(math.MaxInt64)/uint64(n) {
+		return math.MaxInt64
+	}
+	return int64(n) * int64(size)
+}
@@ -0,0 +1,95 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func expectLimitExceeded(t *testing.T, src string, env *Env, limits *Limits, limit string) {
+	errs := EvalSource(&Ctx{Limits: limits}, src, env)
+	if len(errs) != 1 {
+		t.Fatalf("Expected '%s' to exceed %s, got %v", src, limit, errs)
+	} else if err, ok := errs[0].(*SourceError).Err.(ErrLimitExceeded); !ok || err.Limit != limit {
+		t.Fatalf("Expected '%s' to exceed %s, got %v", src, limit, errs[0])
+	}
+}
+
+func TestLimitNodes(t *testing.T) {
+	env := makeEnv()
+	expectLimitExceeded(t, "for {\n}", env, &Limits{Nodes: 1000}, "Nodes")
+
+	if errs := EvalSource(&Ctx{Limits: &Limits{Nodes: 1000}}, "x := 1 + 2", env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+}
+
+func TestLimitCallDepth(t *testing.T) {
+	env := makeEnv()
+	declare(t, "func f(n int) int {\n\tif n == 0 {\n\t\treturn 0\n\t}\n\treturn f(n-1) + 1\n}", env)
+
+	expectLimitExceeded(t, "f(100)", env, &Limits{CallDepth: 10}, "CallDepth")
+	run(t, "x := f(100)", env)
+	expectResult(t, "x", env, int(100))
+}
+
+func TestLimitLen(t *testing.T) {
+	s := "abcd"
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&s)
+	limits := &Limits{Len: 10}
+
+	expectLimitExceeded(t, "x := make([]int, 11)", env, limits, "Len")
+	expectLimitExceeded(t, "x := make([]int, 0, 11)", env, limits, "Len")
+	expectLimitExceeded(t, "x := make(map[int]int, 11)", env, limits, "Len")
+	expectLimitExceeded(t, "x := []int{10: 1}", env, limits, "Len")
+	expectLimitExceeded(t, "x := s + s + s", env, limits, "Len")
+	expectLimitExceeded(t, "x, y := []int{}, 1\nfor {\n\tx = append(x, y)\n}", env, limits, "Len")
+	expectLimitExceeded(t, "m := map[int]int{}\nfor i := 0; ; i++ {\n\tm[i] = i\n}", env, limits, "Len")
+}
+
+func TestLimitBytes(t *testing.T) {
+	env := makeEnv()
+	expectLimitExceeded(t, "x := make([]int64, 100)", env, &Limits{Bytes: 512}, "Bytes")
+	expectLimitExceeded(t, "type big [100]int64\nx := new(big)", env, &Limits{Bytes: 512}, "Bytes")
+
+	// The total allocated is counted
+	limits := &Limits{Bytes: 512}
+	expectLimitExceeded(t, "for {\n\tx := make([]int64, 10)\n\t_ = x\n}", env, limits, "Bytes")
+	expectLimitExceeded(t, "m := map[int]int{}\nfor i := 0; ; i++ {\n\tm[i] = i\n}", env, &Limits{Bytes: 1024}, "Bytes")
+
+	// Storing to an existing entry allocates nothing
+	if errs := EvalSource(&Ctx{Limits: &Limits{Bytes: 1024}}, "e := map[int]int{0: 0}\nfor i := 0; i < 1000; i++ {\n\te[0] = i\n}", env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+}
+
+func TestLimitBeforeAllocating(t *testing.T) {
+	env := makeEnv()
+	limits := &Limits{Len: 1 << 20, Bytes: 1 << 20}
+
+	// Sizes too large to allocate exceed the limits rather than panic
+	expectLimitExceeded(t, "x := make([]int, 1<<40)", env, limits, "Len")
+	expectLimitExceeded(t, "x := make([]int64, 1<<19)", env, limits, "Bytes")
+	expectLimitExceeded(t, "x := make(map[int]int, 1<<40)", env, limits, "Len")
+
+	// as do zero values and array types
+	expectLimitExceeded(t, "var x [1 << 18]int64", env, limits, "Bytes")
+	expectLimitExceeded(t, "var x [4][1 << 16]int64", env, limits, "Bytes")
+	expectLimitExceeded(t, "type big [1 << 24]int8", env, limits, "Len")
+	expectLimitExceeded(t, "x := new([1 << 24]int8)", env, limits, "Len")
+
+	run(t, "var y [1 << 10]int64", env)
+	expectResult(t, "len(y)", env, int(1<<10))
+}
+
+func TestMake(t *testing.T) {
+	env := makeEnv()
+	run(t, "s := make([]int, 2, 5)\nm := make(map[string]int, 4)\nc := make(chan int, 1)\nc <- 3", env)
+
+	expectResult(t, "len(s)", env, int(2))
+	expectResult(t, "cap(s)", env, int(5))
+	expectResult(t, "len(m)", env, int(0))
+	expectResult(t, "<-c", env, int(3))
+	expectStmtError(t, "x := make(int)", env,
+		"invalid argument: cannot make int; type must be slice, map, or channel")
+}
@@ -0,0 +1,230 @@
+package eval
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go/ast"
+)
+
+// Policy restricts the packages, functions and methods evaluated code
+// may use, and whether it may modify the variables of its Env. A use
+// which is known when the code is checked is reported then, others
+// when they are evaluated, as an ErrDenied.
+type Policy struct {
+	// DenyPackages lists the paths of packages which may not be used.
+	// A package of Env.Pkgs is identified by its Env.Path. Native
+	// functions and methods declared in these packages are also denied,
+	// however they are reached.
+	DenyPackages []string
+
+	// DenyFuncs lists the functions and methods which may not be
+	// called, as printed by Callee, e.g. "os.Exit", "(*os.File).Close"
+	// or "(time.Time).String"
+	DenyFuncs []string
+
+	// DenyReceivers lists the types whose methods may not be called,
+	// including the methods of pointers to them
+	DenyReceivers []reflect.Type
+
+	// Allow, if set, is consulted for each function and method which is
+	// not otherwise denied, and denies it by returning false
+	Allow func(callee Callee) bool
+
+	// ReadOnlyVars prevents assigning to, taking the address of or
+	// slicing the variables which are in Env.Vars when the code is
+	// checked, or anything reached through them, and appending to them.
+	// Variables declared by the code itself are not affected, nor are
+	// copies of the values of the read only variables, which may still
+	// share their memory.
+	ReadOnlyVars bool
+
+	// ReadOnly rejects code which may have side effects, such as a
+	// watch expression of a debugger. The code may only assign the
+	// local variables it declares, may not send, receive or close, may
+	// only append to a slice which is full, and may only call functions
+	// marked by MarkPure, builtins, its own function literals and
+	// methods with value receivers. It must be set when the code is
+	// checked as well as when it is evaluated.
+	ReadOnly bool
+
+	// The closures of the native functions marked by MarkPure
+	pure sync.Map
+}
+
+// Callee identifies a function or method for a Policy. A function of
+// Env.Funcs is identified both by the Env.Path and name it is known by,
+// and by the package and name it is declared with. A method is
+// identified by its receiver type and name.
+type Callee struct {
+	Pkg  string       // path of the package of a function
+	Recv reflect.Type // receiver type of a method
+	Name string
+}
+
+func (callee Callee) String() string {
+	if callee.Recv != nil {
+		return "(" + callee.Recv.String() + ")." + callee.Name
+	} else if callee.Pkg == "" {
+		return callee.Name
+	}
+	return callee.Pkg + "." + callee.Name
+}
+
+// nativeCallee identifies a native function by its runtime name, such
+// as github.com/0xfaded/eval.EvalExpr. Method values made by native code
+// are identified by their runtime name, e.g. os.(*File).Close. The
+// stubs of interpreted functions and of method values made by reflect
+// are not identified.
+func nativeCallee(fun reflect.Value) (Callee, bool) {
+	f := runtime.FuncForPC(fun.Pointer())
+	if f == nil {
+		return Callee{}, false
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return Callee{}, false
+	}
+	dot += slash + 1
+	callee := Callee{Pkg: name[:dot], Name: name[dot+1:]}
+	if callee.Pkg == "reflect" && (callee.Name == "makeFuncStub" || callee.Name == "methodValueCall") {
+		return Callee{}, false
+	}
+	return callee, true
+}
+
+func (policy *Policy) allowPackage(path string) bool {
+	if policy == nil {
+		return true
+	}
+	for _, p := range policy.DenyPackages {
+		if p == path {
+			return false
+		}
+	}
+	return true
+}
+
+func (policy *Policy) allowCallee(callee Callee) bool {
+	if policy == nil {
+		return true
+	}
+	pkg := callee.Pkg
+	if recv := callee.Recv; recv != nil {
+		if recv.Kind() == reflect.Ptr {
+			recv = recv.Elem()
+		}
+		pkg = recv.PkgPath()
+		for _, t := range policy.DenyReceivers {
+			if t == recv || t == callee.Recv {
+				return false
+			}
+		}
+	}
+	if !policy.allowPackage(pkg) {
+		return false
+	}
+	name := callee.String()
+	for _, f := range policy.DenyFuncs {
+		if f == name {
+			return false
+		}
+	}
+	return policy.Allow == nil || policy.Allow(callee)
+}
+
+// checkFunc checks the use of fun, a function known as callee, or an
+// unnamed function if callee is empty. verb describes the use.
+func (policy *Policy) checkFunc(ctx *Ctx, verb string, callee Callee, fun reflect.Value, node ast.Node) error {
+	if policy == nil {
+		return nil
+	}
+	if callee.Name != "" && !policy.allowCallee(callee) {
+		return ErrDenied{at(ctx, node), verb + " " + callee.String()}
+	}
+	if fun.IsValid() && fun.Kind() == reflect.Func && !fun.IsNil() {
+		if native, ok := nativeCallee(fun); ok && !policy.allowCallee(native) {
+			return ErrDenied{at(ctx, node), verb + " " + native.String()}
+		}
+	}
+	return nil
+}
+
+// checkMethod checks the use of the method name of recv. The method is
+// identified by the receiver type it is declared with, so the methods
+// of T are those of T even when called on a *T.
+func (policy *Policy) checkMethod(ctx *Ctx, recv reflect.Type, name string, node ast.Node) error {
+	if recv.Kind() == reflect.Ptr && recv.Elem().Kind() != reflect.Interface {
+		if _, ok := recv.Elem().MethodByName(name); ok {
+			recv = recv.Elem()
+		}
+	}
+	callee := Callee{Recv: recv, Name: name}
+	if !policy.allowCallee(callee) {
+		return ErrDenied{at(ctx, node), "use of " + callee.String()}
+	} else if policy.readOnly() && recv.Kind() == reflect.Ptr {
+		return ErrDenied{at(ctx, node), "use of pointer method " + callee.String()}
+	}
+	return nil
+}
+
+// checkReadOnlyVarsCall denies appending to a slice reached through a
+// read only variable, or an unexported field which may only be read.
+// append may write into the capacity of the slice,
+// so is denied whether or not it is full.
+func (policy *Policy) checkReadOnlyVarsCall(ctx *Ctx, call *CallExpr, env *Env) error {
+	if len(call.Args) == 0 || !isBuiltinCall(call, "append", env) {
+		return nil
+	} else if name, ok := readOnlyPath(ctx, call.Args[0].(Expr), env); ok {
+		return ErrDenied{at(ctx, call), "append to " + name}
+	}
+	return nil
+}
+
+// readOnlyRoot returns the read only variable through which x is
+// assigned, addressed or sliced, if any
+func (policy *Policy) readOnlyRoot(x Expr, env *Env) (string, bool) {
+	if policy == nil || !policy.ReadOnlyVars {
+		return "", false
+	}
+	for {
+		switch e := x.(type) {
+		case *Ident:
+			v, ok := env.Vars[e.Name]
+			return e.Name, ok && v.IsValid()
+		case *SelectorExpr:
+			if pkg, ok := packageOf(e, env); ok {
+				v, ok := (*Env)(pkg).Vars[e.Sel.Name]
+				return e.X.(*Ident).Name + "." + e.Sel.Name, ok && v.IsValid()
+			}
+			x = e.X.(Expr)
+		case *IndexExpr:
+			x = e.X.(Expr)
+		case *SliceExpr:
+			x = e.X.(Expr)
+		case *StarExpr:
+			x = e.X.(Expr)
+		case *ParenExpr:
+			x = e.X.(Expr)
+		default:
+			return "", false
+		}
+	}
+}
+
+// packageOf returns the package a selector selects from, if it is a
+// qualified identifier
+func packageOf(selector *SelectorExpr, env *Env) (Pkg, bool) {
+	ident, ok := selector.X.(*Ident)
+	if !ok {
+		return nil, false
+	} else if _, isVar := env.Vars[ident.Name]; isVar {
+		return nil, false
+	}
+	pkg, ok := env.Pkgs[ident.Name]
+	return pkg, ok
+}
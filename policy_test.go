@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type policyCounter struct {
+	N int
+}
+
+func (c *policyCounter) Inc() {
+	c.N++
+}
+
+func expectDenied(t *testing.T, src string, env *Env, policy *Policy, what string) {
+	errs := EvalSource(&Ctx{Policy: policy}, src, env)
+	if len(errs) != 1 {
+		t.Fatalf("Expected '%s' to be denied, got %v", src, errs)
+	} else if err, ok := errs[0].(*SourceError).Err.(ErrDenied); !ok || err.What != what {
+		t.Fatalf("Expected '%s' to deny %s, got %v", src, what, errs[0])
+	}
+}
+
+func expectAllowed(t *testing.T, src string, env *Env, policy *Policy) {
+	if errs := EvalSource(&Ctx{Policy: policy}, src, env); errs != nil {
+		t.Fatalf("Unexpected errors evaluating '%s': %v", src, errs)
+	}
+}
+
+func TestPolicyFuncs(t *testing.T) {
+	lower := strings.ToLower
+	env := makeEnv()
+	env.Funcs["upper"] = reflect.ValueOf(strings.ToUpper)
+	env.Vars["lower"] = reflect.ValueOf(&lower)
+	pkg := makeEnv()
+	pkg.Name, pkg.Path = "strings", "strings"
+	pkg.Funcs["ToUpper"] = reflect.ValueOf(strings.ToUpper)
+	pkg.Funcs["ToLower"] = reflect.ValueOf(strings.ToLower)
+	env.Pkgs["strings"] = pkg
+	policy := &Policy{DenyFuncs: []string{"strings.ToUpper"}}
+
+	// Known when checking, by either name
+	expectDenied(t, `_ = upper("a")`, env, policy, "use of strings.ToUpper")
+	expectDenied(t, `_ = strings.ToUpper("a")`, env, policy, "use of strings.ToUpper")
+	expectDenied(t, `_ = upper("a")`, env, &Policy{DenyFuncs: []string{"upper"}}, "use of upper")
+	expectAllowed(t, `_ = strings.ToLower("A")`, env, policy)
+
+	// Known when evaluating
+	expectDenied(t, `_ = lower("A")`, env, &Policy{DenyFuncs: []string{"strings.ToLower"}},
+		"call of strings.ToLower")
+	expectDenied(t, `_ = lower("A")`, env,
+		&Policy{Allow: func(c Callee) bool { return c.Name != "ToLower" }}, "call of strings.ToLower")
+}
+
+func TestPolicyPackages(t *testing.T) {
+	lower := strings.ToLower
+	env := makeEnv()
+	env.Funcs["upper"] = reflect.ValueOf(strings.ToUpper)
+	env.Vars["lower"] = reflect.ValueOf(&lower)
+	pkg := makeEnv()
+	pkg.Name, pkg.Path = "strings", "strings"
+	pkg.Funcs["ToUpper"] = reflect.ValueOf(strings.ToUpper)
+	pkg.Funcs["ToLower"] = reflect.ValueOf(strings.ToLower)
+	env.Pkgs["strings"] = pkg
+	policy := &Policy{DenyPackages: []string{"strings"}}
+
+	expectDenied(t, `_ = strings.ToLower("A")`, env, policy, "use of package strings")
+	expectDenied(t, `_ = upper("a")`, env, policy, "use of strings.ToUpper")
+	expectDenied(t, `_ = lower("A")`, env, policy, "call of strings.ToLower")
+	expectAllowed(t, "_ = 1 + 2", env, policy)
+}
+
+func TestPolicyMethods(t *testing.T) {
+	c := &policyCounter{}
+	var i interface{ Inc() } = c
+	env := makeEnv()
+	env.Vars["c"] = reflect.ValueOf(c)
+	env.Vars["i"] = reflect.ValueOf(&i)
+
+	deny := &Policy{DenyReceivers: []reflect.Type{reflect.TypeOf(policyCounter{})}}
+	expectDenied(t, "c.Inc()", env, deny, "use of (*eval.policyCounter).Inc")
+	// The dynamic type of i is only known when evaluating
+	expectDenied(t, "i.Inc()", env, deny, "use of (*eval.policyCounter).Inc")
+
+	named := &Policy{DenyFuncs: []string{"(*eval.policyCounter).Inc"}}
+	expectDenied(t, "i.Inc()", env, named, "use of (*eval.policyCounter).Inc")
+
+	expectAllowed(t, "c.Inc()\ni.Inc()", env, &Policy{})
+	if c.N != 2 {
+		t.Fatalf("Expected 2 calls of Inc, got %d", c.N)
+	}
+}
+
+func TestPolicyReadOnlyVars(t *testing.T) {
+	n := 1
+	s := make([]int, 2, 4)
+	s[0], s[1] = 1, 2
+	a := [2]int{1, 2}
+	c := policyCounter{}
+	env := makeEnv()
+	env.Vars["n"] = reflect.ValueOf(&n)
+	env.Vars["s"] = reflect.ValueOf(&s)
+	env.Vars["a"] = reflect.ValueOf(&a)
+	env.Vars["c"] = reflect.ValueOf(&c)
+	policy := &Policy{ReadOnlyVars: true}
+
+	expectDenied(t, "n = 2", env, policy, "assignment to n")
+	expectDenied(t, "n++", env, policy, "assignment to n")
+	expectDenied(t, "n += 2", env, policy, "assignment to n")
+	expectDenied(t, "s[0] = 2", env, policy, "assignment to s")
+	expectDenied(t, "c.N = 2", env, policy, "assignment to c")
+	expectDenied(t, "c.Inc()", env, policy, "taking the address of c")
+	expectDenied(t, "p := &n", env, policy, "taking the address of n")
+	expectDenied(t, "x := a[:]", env, policy, "slicing a")
+	expectDenied(t, "s[1:][0] = 2", env, policy, "assignment to s")
+	expectDenied(t, "x := append(s, 3)", env, policy, "append to s")
+	expectDenied(t, "x := append(s[:1], 3)", env, policy, "append to s")
+
+	// Variables declared by the code itself may be assigned
+	expectAllowed(t, "x := n\nx = 3\nx++\nx += n", env, policy)
+	expectAllowed(t, "u := s[:1]\nv := a\nw := v[:]\nw[0] = u[0] + 2", env, policy)
+	if n != 1 || s[0] != 1 || s[:3][2] != 0 || a[0] != 1 || c.N != 0 {
+		t.Fatalf("Read only variables were modified: %v %v %v %v", n, s, a, c)
+	}
+}
@@ -0,0 +1,151 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// MarkPure declares native functions free of side effects, so that code
+// evaluated with the ReadOnly policy may call them. Each of fns is a
+// native function, or a reflect.Value holding one. A closure is marked
+// alone, not the other closures of the same function literal. Builtins
+// other than close need not be marked. Functions declared by evaluated
+// code are marked with Env.MarkPure.
+func (policy *Policy) MarkPure(fns ...interface{}) error {
+	for _, fn := range fns {
+		v, err := pureFunc(fn)
+		if err != nil {
+			return err
+		} else if _, ok := nativeCallee(v); !ok {
+			return errors.New("eval: MarkPure of a function which cannot be identified")
+		}
+		policy.pure.Store(funcIdentity(v), true)
+	}
+	return nil
+}
+
+// MarkPure declares functions declared in env by evaluated code free of
+// side effects, as Policy.MarkPure does native functions
+func (env *Env) MarkPure(fns ...interface{}) error {
+	for _, fn := range fns {
+		v, err := pureFunc(fn)
+		if err != nil {
+			return err
+		}
+		d, ok := env.declaredFunc(v)
+		if !ok {
+			return errors.New("eval: Env.MarkPure of a function which was not declared in the Env")
+		}
+		atomic.StoreInt32(&d.pure, 1)
+	}
+	return nil
+}
+
+// pureFunc returns the function fn passed to MarkPure
+func pureFunc(fn interface{}) (reflect.Value, error) {
+	v, ok := fn.(reflect.Value)
+	if !ok {
+		v = reflect.ValueOf(fn)
+	}
+	if v.Kind() != reflect.Func || v.IsNil() || !v.CanInterface() {
+		return v, errors.New("eval: MarkPure of a value which is not a function")
+	}
+	return v, nil
+}
+
+// funcIdentity returns the closure of the function fun. Closures of the
+// same code share their code pointer, but not their closure unless they
+// also capture the same variables.
+func funcIdentity(fun reflect.Value) unsafe.Pointer {
+	// A func is a pointer to its closure, stored directly in the data
+	// word of an interface
+	i := fun.Interface()
+	return (*[2]unsafe.Pointer)(unsafe.Pointer(&i))[1]
+}
+
+// isPure reports whether fun, which may have been declared in env, has
+// been marked pure
+func (policy *Policy) isPure(env *Env, fun reflect.Value) bool {
+	if d, ok := env.declaredFunc(fun); ok {
+		return d.isPure()
+	} else if policy == nil || !fun.CanInterface() {
+		return false
+	}
+	_, pure := policy.pure.Load(funcIdentity(fun))
+	return pure
+}
+
+// isMethodValue reports whether fun is a method value made by reflect
+func isMethodValue(fun reflect.Value) bool {
+	f := runtime.FuncForPC(fun.Pointer())
+	return f != nil && f.Name() == "reflect.methodValueCall"
+}
+
+func (policy *Policy) readOnly() bool {
+	return policy != nil && policy.ReadOnly
+}
+
+// checkReadOnlyAssign checks an assignment to lhs. Read only code may
+// only assign the local variables it declares itself.
+func checkReadOnlyAssign(ctx *Ctx, lhs Expr, env *Env) error {
+	if !ctx.Policy.readOnly() {
+		return nil
+	} else if ident, ok := lhs.(*Ident); ok {
+		if v, ok := env.Vars[ident.Name]; ok && !v.IsValid() {
+			return nil
+		}
+	}
+	return ErrDenied{at(ctx, lhs), "assignment to " + at(ctx, lhs).Source()}
+}
+
+// checkReadOnlyCall checks that a call in read only code calls a pure
+// function, if the function is known when checking
+func checkReadOnlyCall(ctx *Ctx, call *CallExpr, env *Env) error {
+	if !ctx.Policy.readOnly() {
+		return nil
+	}
+	var fun reflect.Value
+	switch f := skipSuperfluousParens(call.Fun.(Expr)).(type) {
+	case *Ident:
+		_, isVar := env.Vars[f.Name]
+		_, isConst := env.Consts[f.Name]
+		v, isFunc := env.Funcs[f.Name]
+		if isVar || isConst {
+			return nil
+		} else if isFunc {
+			fun = v
+		} else if _, ok := builtinFuncs[f.Name]; ok && f.Name == "close" {
+			return ErrDenied{at(ctx, call), "call of close"}
+		}
+	case *SelectorExpr:
+		if pkg, ok := packageOf(f, env); ok {
+			fun = (*Env)(pkg).Funcs[f.Sel.Name]
+		}
+	}
+	if fun.IsValid() && !ctx.Policy.isPure(calleeEnv(call, env), fun) {
+		return ErrDenied{at(ctx, call), "call of impure " + at(ctx, call.Fun).Source()}
+	}
+	return nil
+}
+
+// checkPureCall checks that a call made by read only code calls a pure
+// function, which may have been declared in env. Function literals of
+// the code are checked as read only themselves, and methods are checked
+// as they are selected.
+func checkPureCall(ctx *Ctx, env *Env, fun reflect.Value, call *CallExpr) error {
+	if !ctx.Policy.readOnly() || ctx.Policy.isPure(env, fun) {
+		return nil
+	}
+	switch skipSuperfluousParens(call.Fun.(Expr)).(type) {
+	case *FuncLit:
+		return nil
+	case *SelectorExpr:
+		if isMethodValue(fun) {
+			return nil
+		}
+	}
+	return ErrDenied{at(ctx, call), "call of impure " + at(ctx, call.Fun).Source()}
+}
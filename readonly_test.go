@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type readOnlyCounter struct {
+	N int
+}
+
+func (c *readOnlyCounter) Inc() {
+	c.N++
+}
+
+func (c readOnlyCounter) Get() int {
+	return c.N
+}
+
+func TestReadOnlyDenied(t *testing.T) {
+	n := 1
+	s := make([]int, 2, 4)
+	c := readOnlyCounter{}
+	var i interface{ Inc() } = &c
+	ch := make(chan int, 1)
+	lower := strings.ToLower
+	env := makeEnv()
+	env.Vars["n"] = reflect.ValueOf(&n)
+	env.Vars["s"] = reflect.ValueOf(&s)
+	env.Vars["c"] = reflect.ValueOf(&c)
+	env.Vars["i"] = reflect.ValueOf(&i)
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	env.Vars["f"] = reflect.ValueOf(&lower)
+	env.Funcs["lower"] = reflect.ValueOf(strings.ToLower)
+	policy := &Policy{ReadOnly: true}
+
+	expectDenied(t, "n = 2", env, policy, "assignment to n")
+	expectDenied(t, "n++", env, policy, "assignment to n")
+	expectDenied(t, "s[0] = 2", env, policy, "assignment to s[0]")
+	expectDenied(t, "c.N = 2", env, policy, "assignment to c.N")
+	expectDenied(t, "ch <- 1", env, policy, "send")
+	expectDenied(t, "_ = <-ch", env, policy, "receive")
+	expectDenied(t, "close(ch)", env, policy, "call of close")
+	expectDenied(t, `_ = lower("A")`, env, policy, "call of impure lower")
+	expectDenied(t, "c.Inc()", env, policy, "use of pointer method (*eval.readOnlyCounter).Inc")
+
+	// Known when evaluating
+	expectDenied(t, `_ = f("A")`, env, policy, "call of impure f")
+	expectDenied(t, "i.Inc()", env, policy, "use of pointer method (*eval.readOnlyCounter).Inc")
+	expectDenied(t, "_ = append(s, n)", env, policy, "append into existing capacity")
+}
+
+func TestReadOnlyAllowed(t *testing.T) {
+	n := 1
+	s := make([]int, 2, 4)
+	full := []int{1, 2}
+	c := readOnlyCounter{}
+	ch := make(chan int, 1)
+	env := makeEnv()
+	env.Vars["n"] = reflect.ValueOf(&n)
+	env.Vars["s"] = reflect.ValueOf(&s)
+	env.Vars["full"] = reflect.ValueOf(&full)
+	env.Vars["c"] = reflect.ValueOf(&c)
+	env.Vars["ch"] = reflect.ValueOf(&ch)
+	env.Funcs["upper"] = reflect.ValueOf(strings.ToUpper)
+	policy := &Policy{ReadOnly: true}
+	if err := policy.MarkPure(strings.ToUpper); err != nil {
+		t.Fatal(err)
+	}
+
+	expectAllowed(t, `_ = upper("a")`, env, policy)
+	expectAllowed(t, "_ = len(s) + cap(s)", env, policy)
+	expectAllowed(t, "_ = c.Get()", env, policy)
+	expectAllowed(t, "_ = append(full, n)", env, policy)
+	expectAllowed(t, "_ = func() int {\n\tx := n\n\tx++\n\treturn x\n}()", env, policy)
+
+	declare(t, "func twice(x int) int {\n\treturn 2 * x\n}", env)
+	expectDenied(t, "_ = twice(2)", env, policy, "call of impure twice")
+	if err := policy.MarkPure(env.Funcs["twice"]); err == nil {
+		t.Fatalf("Expected an error marking a declared function pure without its Env")
+	}
+	if err := env.MarkPure(env.Funcs["twice"]); err != nil {
+		t.Fatal(err)
+	}
+	expectAllowed(t, "_ = twice(2)", env, policy)
+
+	expectResult(t, "n", env, int(1))
+	expectResult(t, "len(ch)", env, int(0))
+}
+
+func TestMarkPure(t *testing.T) {
+	policy := &Policy{ReadOnly: true}
+	if err := policy.MarkPure(1); err == nil {
+		t.Fatalf("Expected an error marking a non-function pure")
+	}
+	fun := reflect.MakeFunc(reflect.TypeOf(func() {}), func([]reflect.Value) []reflect.Value {
+		return nil
+	})
+	if err := policy.MarkPure(fun); err == nil {
+		t.Fatalf("Expected an error marking a function made by reflect pure")
+	}
+	if err := makeEnv().MarkPure(strings.ToUpper); err == nil {
+		t.Fatalf("Expected an error marking a native function pure in an Env")
+	}
+}
+
+func TestMarkPureClosure(t *testing.T) {
+	var counts [2]int
+	closures := make([]func() int, 2)
+	for i := range closures {
+		i := i
+		closures[i] = func() int {
+			counts[i]++
+			return counts[i]
+		}
+	}
+	env := makeEnv()
+	env.Funcs["get"] = reflect.ValueOf(closures[0])
+	env.Funcs["inc"] = reflect.ValueOf(closures[1])
+	if reflect.ValueOf(closures[0]).Pointer() != reflect.ValueOf(closures[1]).Pointer() {
+		t.Fatalf("Expected the closures to share their code")
+	}
+
+	policy := &Policy{ReadOnly: true}
+	if err := policy.MarkPure(closures[0]); err != nil {
+		t.Fatal(err)
+	}
+	expectAllowed(t, "_ = get()", env, policy)
+	expectDenied(t, "_ = inc()", env, policy, "call of impure inc")
+
+	// Marks are those of the Policy alone
+	expectDenied(t, "_ = get()", env, &Policy{ReadOnly: true}, "call of impure get")
+}
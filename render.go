@@ -0,0 +1,214 @@
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go/scanner"
+	"go/token"
+)
+
+// RenderOptions controls the output of RenderDiagnostic
+type RenderOptions struct {
+	// Color highlights the output with ANSI escape sequences, for
+	// printing to a terminal
+	Color bool
+
+	// If Env is set, each name within the span of a Diagnostic that was
+	// declared in Env from source is shown with a "declared here" note
+	Env *Env
+}
+
+// ANSI escape sequences used by RenderDiagnostic
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[1;31m"
+	ansiYellow = "\x1b[1;33m"
+	ansiBlue   = "\x1b[1;34m"
+	ansiCyan   = "\x1b[1;36m"
+)
+
+// RenderDiagnostic renders d as a header followed by the lines of source
+// it spans, the whole span underlined with carets. Related positions
+// and "declared here" notes are rendered in the same way below it. For
+// example
+//
+//	1:10: error: constant 1000 overflows int8
+//	 1 | x := 1 + int8(1000)
+//	   |          ^^^^^^^^^^
+//
+// Positions outside of source are rendered as the header alone.
+func RenderDiagnostic(source string, d Diagnostic, opts RenderOptions) string {
+	related := d.Related
+	if opts.Env != nil {
+		related = append(related[:len(related):len(related)], declaredNotes(source, d, opts.Env)...)
+	}
+
+	width := len(fmt.Sprint(d.Start.Line))
+	for _, r := range related {
+		if n := len(fmt.Sprint(r.Start.Line)); n > width {
+			width = n
+		}
+	}
+
+	r := renderer{opts: opts, width: width}
+	r.section(source, d.Severity, d.Start, d.End, d.Message)
+	for _, rel := range related {
+		src := rel.Source
+		if src == "" {
+			src = source
+		}
+		r.section(src, SeverityNote, rel.Start, rel.End, rel.Message)
+	}
+	return r.buf.String()
+}
+
+type renderer struct {
+	buf   bytes.Buffer
+	opts  RenderOptions
+	width int
+}
+
+func (r *renderer) color(code, s string) string {
+	if !r.opts.Color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func severityColor(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return ansiYellow
+	case SeverityNote:
+		return ansiCyan
+	default:
+		return ansiRed
+	}
+}
+
+// section renders one header and the lines of source from start up to
+// end
+func (r *renderer) section(source string, severity Severity, start, end Position, msg string) {
+	header := r.color(severityColor(severity), severity.String()+":") + " " + r.color(ansiBold, msg)
+	if start.IsValid() {
+		header = start.String() + ": " + header
+	}
+	r.buf.WriteString(header + "\n")
+
+	lines := strings.Split(source, "\n")
+	if !start.IsValid() || start.Line > len(lines) || start.Column < 1 || start.Column-1 > len(lines[start.Line-1]) {
+		return
+	}
+	if !end.IsValid() || end.Line < start.Line || end.Line == start.Line && end.Column <= start.Column {
+		end = Position{start.Line, start.Column + 1}
+	} else if end.Line > len(lines) {
+		end = Position{len(lines), len(lines[len(lines)-1]) + 1}
+	}
+
+	caret := severityColor(severity)
+	for n := start.Line; n <= end.Line; n += 1 {
+		line := lines[n-1]
+		from, to := 0, len(line)
+		if n == start.Line {
+			from = start.Column - 1
+		} else {
+			from = len(line) - len(strings.TrimLeft(line, " \t"))
+		}
+		if n == end.Line && end.Column-1 < to {
+			to = end.Column - 1
+		}
+		if from > to {
+			from = to
+		}
+
+		gutter := fmt.Sprintf("%*d |", r.width, n)
+		r.buf.WriteString(r.color(ansiBlue, gutter) + " " + line + "\n")
+
+		carets := utf8.RuneCountInString(line[from:to])
+		if carets == 0 {
+			if n != start.Line {
+				continue
+			}
+			carets = 1
+		}
+		r.buf.WriteString(r.color(ansiBlue, strings.Repeat(" ", r.width)+" |") + " ")
+		r.buf.WriteString(indentOf(line[:from]))
+		r.buf.WriteString(r.color(caret, strings.Repeat("^", carets)) + "\n")
+	}
+}
+
+// indentOf returns blanks as wide as prefix, keeping its tabs so that
+// what follows lines up with the text after prefix
+func indentOf(prefix string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, prefix)
+}
+
+// declaredNotes returns a "declared here" note for each name within the
+// span of d that env records the declaration of
+func declaredNotes(source string, d Diagnostic, env *Env) (notes []Related) {
+	from, ok := positionOffset(source, d.Start)
+	to, endOk := positionOffset(source, d.End)
+	if !ok || !endOk || to < from {
+		return nil
+	}
+
+	envLock.RLock()
+	defer envLock.RUnlock()
+	if env.Declared == nil {
+		return nil
+	}
+
+	var s scanner.Scanner
+	text := source[from:to]
+	file := token.NewFileSet().AddFile("", -1, len(text))
+	s.Init(file, []byte(text), nil, 0)
+	seen := map[string]bool{}
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		} else if tok != token.IDENT || seen[lit] {
+			continue
+		}
+		seen[lit] = true
+		if decl, ok := env.Declared[lit]; ok {
+			notes = append(notes, Related{
+				Start:   decl.Start,
+				End:     decl.End,
+				Message: lit + " declared here",
+				Source:  decl.Source,
+			})
+		}
+	}
+	return notes
+}
+
+// positionOffset returns the byte offset of a position of src, the
+// inverse of offsetPosition
+func positionOffset(src string, pos Position) (int, bool) {
+	if !pos.IsValid() {
+		return 0, false
+	}
+	offset := 0
+	for line := 1; line < pos.Line; line += 1 {
+		i := strings.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+		offset += i + 1
+	}
+	offset += pos.Column - 1
+	if offset > len(src) {
+		return 0, false
+	}
+	return offset, true
+}
@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func expectRender(t *testing.T, source string, d Diagnostic, opts RenderOptions, expected string) {
+	if s := RenderDiagnostic(source, d, opts); s != expected {
+		t.Fatalf("Rendered\n%s\nexpected\n%s", s, expected)
+	}
+}
+
+func TestRenderSpan(t *testing.T) {
+	env := makeEnv()
+	source := "x + int8(1000)"
+	d := checkDiagnostics(t, source, env)
+	if len(d) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", d)
+	}
+	expectRender(t, source, d[0], RenderOptions{},
+		"1:10: error: constant 1000 overflows int8\n"+
+			"1 | x + int8(1000)\n"+
+			"  |          ^^^^\n")
+}
+
+func TestRenderMultiLine(t *testing.T) {
+	source := "f(1,\n\tg(2,\n\t\t3))"
+	d := Diagnostic{Start: Position{1, 5}, End: Position{3, 5}, Message: "wrong"}
+	expectRender(t, source, d, RenderOptions{},
+		"1:5: error: wrong\n"+
+			"1 | f(1,\n"+
+			"  |     ^\n"+
+			"2 | \tg(2,\n"+
+			"  | \t^^^^\n"+
+			"3 | \t\t3))\n"+
+			"  | \t\t^^\n")
+}
+
+func TestRenderRelated(t *testing.T) {
+	source := "T{A: 1,\n A: 2}"
+	d := Diagnostic{
+		Start:   Position{2, 2},
+		End:     Position{2, 3},
+		Message: "duplicate field name A in struct literal",
+		Related: []Related{{Start: Position{1, 3}, End: Position{1, 4}, Message: "previous key A"}},
+	}
+	expectRender(t, source, d, RenderOptions{},
+		"2:2: error: duplicate field name A in struct literal\n"+
+			"2 |  A: 2}\n"+
+			"  |  ^\n"+
+			"1:3: note: previous key A\n"+
+			"1 | T{A: 1,\n"+
+			"  |   ^\n")
+}
+
+func TestRenderColor(t *testing.T) {
+	d := Diagnostic{Start: Position{1, 1}, End: Position{1, 2}, Message: "bad"}
+	expectRender(t, "x", d, RenderOptions{Color: true},
+		"1:1: \x1b[1;31merror:\x1b[0m \x1b[1mbad\x1b[0m\n"+
+			"\x1b[1;34m1 |\x1b[0m x\n"+
+			"\x1b[1;34m  |\x1b[0m \x1b[1;31m^\x1b[0m\n")
+}
+
+func TestRenderDeclaredHere(t *testing.T) {
+	env := makeEnv()
+	decl := "var n = 1"
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package main;"+decl, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &Ctx{Input: decl, Base: len("package main;")}
+	if cdecl, errs := CheckDecl(ctx, f.Decls[0], env); errs != nil {
+		t.Fatal(errs)
+	} else if err := EvalDecl(ctx, cdecl, env); err != nil {
+		t.Fatal(err)
+	}
+
+	source := "1 + n.(int)"
+	d := checkDiagnostics(t, source, env)
+	if len(d) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", d)
+	}
+	expectRender(t, source, d[0], RenderOptions{Env: env},
+		"1:5: error: invalid type assertion: n (non-interface type int on left)\n"+
+			"1 | 1 + n.(int)\n"+
+			"  |     ^\n"+
+			"1:5: note: n declared here\n"+
+			"1 | var n = 1\n"+
+			"  |     ^\n")
+}
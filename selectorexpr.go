@@ -17,38 +17,74 @@ func EvalSelectorExpr(ctx *Ctx, selector *SelectorExpr, env *Env) (*reflect.Valu
 	}
 	sel   := selector.Sel.Name
 	x0    := (*x)[0]
-	xname := x0.Type().Name()
+	xtype := x0.Type()
+	xname := xtype.Name()
 
 	if x0.Kind() == reflect.Ptr {
 		// Special case for handling packages
 		if x0.Type() == reflect.TypeOf(Pkg(nil)) {
+			pkg := x0.Interface().(Pkg)
+			if path := (*Env)(pkg).Path; !ctx.Policy.allowPackage(path) {
+				return nil, true, ErrDenied{at(ctx, selector), "use of package " + path}
+			}
 			sel := &Ident{ Ident: selector.Sel }
-			return evalIdentExprCallback(ctx, sel, x0.Interface().(Pkg))
-		} else if !x0.IsNil() && x0.Elem().Kind() == reflect.Struct {
-			x0 = x0.Elem()
+			return evalIdentExprCallback(ctx, sel, pkg)
+		} else if x0.Type().Elem().Kind() == reflect.Struct {
+			if !x0.IsNil() {
+				x0 = x0.Elem()
+			} else if v := x0.MethodByName(sel); v.IsValid() {
+				// Methods may be called on a nil pointer, fields not
+				if err := ctx.Policy.checkMethod(ctx, x0.Type(), sel, selector); err != nil {
+					return nil, true, err
+				}
+				return &v, true, nil
+			} else if _, ok := x0.Type().Elem().FieldByName(sel); ok {
+				return nil, true, ErrNilDereference
+			}
 		}
 	}
 
 	switch x0.Type().Kind() {
 	case reflect.Struct:
-		if v := x0.FieldByName(sel); v.IsValid() {
+		if v, err := selectField(ctx, x0, sel); err != nil {
+			if unexported, ok := err.(ErrUnexportedSelector); ok {
+				unexported.ErrorContext = at(ctx, selector)
+				return nil, true, unexported
+			}
+			return nil, true, err
+		} else if v.IsValid() {
 			return &v, true, nil
-		} else if x0.CanAddr() {
-			if v := x0.Addr().MethodByName(sel); v.IsValid() {
-				return &v, true, nil
+		}
+		// The method set of an addressable value includes the methods
+		// of its pointer
+		recv := x0
+		if x0.CanAddr() {
+			recv = x0.Addr()
+		}
+		if v := recv.MethodByName(sel); v.IsValid() {
+			if err := ctx.Policy.checkMethod(ctx, recv.Type(), sel, selector); err != nil {
+				return nil, true, err
 			}
+			return &v, true, nil
 		}
-		return nil, true, errors.New(fmt.Sprintf("%s has no field or method %s", xname, sel))
+		return nil, true, ErrNoMethod{at(ctx, selector), xtype, sel}
 	case reflect.Interface:
-		if v := x0.MethodByName(sel); !v.IsValid() {
-			return &v, true, errors.New(fmt.Sprintf("%s has no method %s", xname, sel))
+		if _, ok := x0.Type().MethodByName(sel); !ok {
+			return nil, true, errors.New(fmt.Sprintf("%s has no method %s", xname, sel))
+		} else if x0.IsNil() {
+			return nil, true, ErrNilDereference
 		} else {
+			v := x0.MethodByName(sel)
+			// Both the interface and its dynamic type may be denied
+			if err := ctx.Policy.checkMethod(ctx, x0.Type(), sel, selector); err != nil {
+				return nil, true, err
+			} else if err := ctx.Policy.checkMethod(ctx, x0.Elem().Type(), sel, selector); err != nil {
+				return nil, true, err
+			}
 			return &v, true, nil
 		}
 	default:
-		err = errors.New(fmt.Sprintf("%s.%s undefined (%s has no field or method %s)",
-			xname, sel, xname, sel))
-		return nil, true, err
+		return nil, true, ErrNoMethod{at(ctx, selector), xtype, sel}
 	}
 }
 
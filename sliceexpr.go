@@ -0,0 +1,94 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go/ast"
+)
+
+func evalSliceExpr(ctx *Ctx, slice *SliceExpr, env *Env) (*reflect.Value, bool, error) {
+	xs, _, err := EvalExpr(ctx, slice.X.(Expr), env)
+	if err != nil {
+		return nil, false, err
+	} else if xs == nil {
+		return nil, false, ErrUntypedNil{at(ctx, slice.X)}
+	}
+
+	var x reflect.Value
+	if x, err = expectSingleValue(ctx, *xs, slice.X); err != nil {
+		return nil, false, err
+	}
+
+	// Special short hand for array pointers
+	if t := x.Type(); t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Array {
+		x = x.Elem()
+	}
+
+	switch x.Kind() {
+	case reflect.Array:
+		if !x.CanAddr() {
+			return nil, false, ErrUnaddressableSlice{at(ctx, slice)}
+		}
+	case reflect.String:
+		if slice.Slice3 {
+			return nil, false, ErrThreeIndexString{at(ctx, slice)}
+		}
+	case reflect.Slice:
+	default:
+		return nil, false, ErrCannotSlice{at(ctx, slice.X), x.Type()}
+	}
+
+	// Omitted indices default to zero and the length or capacity of x
+	low, high, max := 0, x.Len(), x.Len()
+	if x.Kind() != reflect.String {
+		max = x.Cap()
+	}
+	// Indices are reported as slice indices whatever x is. Constant
+	// indices are checked as the compiler checks them, against the
+	// length of x if it is known and against each other.
+	sliceType := reflect.TypeOf([]int(nil))
+	n, bound := staticBound(x, slice.X.(Expr))
+	var prev ast.Expr
+	var prevIndex int
+	for _, index := range []struct {
+		expr ast.Expr
+		i    *int
+	}{{slice.Low, &low}, {slice.High, &high}, {slice.Max, &max}} {
+		if index.expr == nil {
+			continue
+		}
+		if *index.i, err = evalIntIndex(ctx, index.expr, env, sliceType); err != nil {
+			return nil, false, err
+		} else if !index.expr.(Expr).IsConst() {
+			continue
+		} else if n >= 0 && *index.i > n {
+			return nil, false, ErrInvalidIndex{at(ctx, index.expr), reflect.ValueOf(*index.i), sliceType, bound}
+		} else if prev != nil && prevIndex > *index.i {
+			return nil, false, ErrInvertedSliceIndices{at(ctx, slice), prev, index.expr}
+		}
+		prev, prevIndex = index.expr, *index.i
+	}
+
+	v, err := sliceValue(x, low, high, max, slice.Slice3)
+	if err != nil {
+		return nil, false, err
+	}
+	return &v, true, nil
+}
+
+// sliceValue slices x, returning the panic of the reflect package as an
+// error if the indices are out of range
+func sliceValue(x reflect.Value, low, high, max int, slice3 bool) (v reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(strings.TrimPrefix(fmt.Sprint(r), "reflect: "))
+		}
+	}()
+	if slice3 {
+		return x.Slice3(low, high, max), nil
+	}
+	return x.Slice(low, high), nil
+}
@@ -0,0 +1,339 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+)
+
+// SourceError is an error at a position in the source passed to
+// EvalSource. Error formats it as line:column: message, as go/parser
+// does, so the result may be passed to FormatErrorPos. The span of an
+// error caused by a node ends at EndLine and EndColumn, which are
+// otherwise zero.
+type SourceError struct {
+	Line, Column       int
+	Err                error
+	EndLine, EndColumn int
+}
+
+func (err *SourceError) Error() string {
+	return fmt.Sprintf("%d:%d: %v", err.Line, err.Column, err.Err)
+}
+
+func (err *SourceError) Unwrap() error {
+	return err.Err
+}
+
+// Diagnostic is the Diagnostic of Err, positioned within the source
+func (err *SourceError) Diagnostic() Diagnostic {
+	d := Diagnose(err.Err)
+	d.Start = Position{err.Line, err.Column}
+	d.End = Position{err.EndLine, err.EndColumn}
+	// Related positions are only known within the source of Err
+	d.Related = nil
+	return d
+}
+
+// The headers of the text handed to go/parser. Both are the same
+// length, so an offset in any text parsed is the offset in the original
+// source plus len(stmtHeader).
+const (
+	stmtHeader = "package main;func _(){"
+	declHeader = "package main;         "
+)
+
+// span is the text of a top level declaration within a source
+type span struct {
+	start, end int
+}
+
+// chunk is a parsed top level declaration or statement of a source
+type chunk struct {
+	ctx    *Ctx
+	offset int
+	node   ast.Node
+}
+
+// compiled is a checked chunk
+type compiled struct {
+	ctx    *Ctx
+	offset int
+	decl   Decl
+	stmt   Stmt
+}
+
+// EvalSource evaluates a sequence of top level declarations and
+// statements in env. Function declarations may appear anywhere, and are
+// declared before any statement runs. Other declarations and statements
+// are executed in order, as if in the body of a function, except that
+// the names they declare are added to env.
+//
+// Everything is checked before anything is evaluated. If there are
+// errors, all of them are returned and env is left untouched. Checking
+// is that of CheckStmt, which leaves some errors, such as those of the
+// arguments of calls, to evaluation. The first error raised while
+// evaluating is returned, the statements before it having run. Each
+// error is a *SourceError giving its position in src. ctx is copied,
+// with Input set to the text being evaluated.
+func EvalSource(ctx *Ctx, src string, env *Env) []error {
+	ctx = ctx.start()
+	decls, body, errs := splitSource(src)
+	if errs != nil {
+		return errs
+	}
+	chunks, errs := parseSource(warnInSource(ctx), src, decls, body)
+	if errs != nil {
+		return errs
+	}
+
+	scope := topLevelScope(env)
+	code := make([]compiled, len(chunks))
+	for i, c := range chunks {
+		var moreErrs []error
+		code[i] = compiled{ctx: c.ctx, offset: c.offset}
+		if decl, ok := c.node.(*ast.FuncDecl); ok {
+			code[i].decl, moreErrs = CheckDecl(c.ctx, decl, scope.Env)
+		} else {
+			code[i].stmt, moreErrs = checkStmt(c.ctx, c.node.(ast.Stmt), scope)
+		}
+		for _, err := range moreErrs {
+			errs = append(errs, sourceError(src, c.offset, err))
+		}
+	}
+	if errs != nil {
+		return errs
+	}
+
+	for _, unit := range code {
+		if unit.decl != nil {
+			if err := EvalDecl(unit.ctx, unit.decl, env); err != nil {
+				return []error{sourceError(src, unit.offset, err)}
+			}
+		}
+	}
+
+	// Calls deferred by the top level statements are made once they
+	// have all run, or one has failed
+	fr := &frame{}
+	for _, unit := range code {
+		if unit.stmt != nil {
+			if _, err := evalStmt(unit.ctx, unit.stmt, &block{env: env}, fr); err != nil {
+				if err = fr.runDefers(err); err != nil {
+					return []error{sourceError(src, unit.offset, err)}
+				}
+				return nil
+			}
+		}
+	}
+	if err := fr.runDefers(nil); err != nil {
+		return []error{sourceError(src, len(src), err)}
+	}
+	return nil
+}
+
+// splitSource cuts the top level function declarations and imports out
+// of src. It returns their spans, and the rest of src with them and any
+// package clause blanked out, which is the body of the statements.
+// Statements are not split, a semicolon at the top level may be within
+// the header of a for, if or switch statement.
+func splitSource(src string) ([]span, string, []error) {
+	var s scanner.Scanner
+	var errs []error
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(src))
+	s.Init(file, []byte(src), func(pos token.Position, msg string) {
+		errs = append(errs, &SourceError{Line: pos.Line, Column: pos.Column, Err: errors.New(msg)})
+	}, 0)
+
+	var decls []span
+	body := []byte(src)
+	var tokens []token.Token
+	start, depth := -1, 0
+	for {
+		pos, tok, lit := s.Scan()
+		offset := file.Offset(pos)
+		if start == -1 {
+			if tok == token.SEMICOLON && lit == "\n" {
+				continue
+			}
+			start, tokens = offset, nil
+		}
+		tokens = append(tokens, tok)
+
+		switch tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth += 1
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth -= 1
+		}
+		if tok == token.EOF || tok == token.SEMICOLON && depth <= 0 {
+			end := offset
+			if tok == token.EOF {
+				end = len(src)
+			}
+			isDecl := isDeclTokens(tokens)
+			if isDecl {
+				decls = append(decls, span{start, end})
+			}
+			if isDecl || tokens[0] == token.PACKAGE {
+				blank(body[start:end])
+			}
+			start = -1
+		}
+		if tok == token.EOF {
+			break
+		}
+	}
+	return decls, string(body), errs
+}
+
+// isDeclTokens reports whether the tokens of a top level unit of source
+// begin a function, method or import declaration. A method declaration
+// is told from a function literal by the name following its receiver.
+func isDeclTokens(tokens []token.Token) bool {
+	if len(tokens) < 2 {
+		return false
+	} else if tokens[0] == token.IMPORT || tokens[0] == token.FUNC && tokens[1] == token.IDENT {
+		return true
+	} else if tokens[0] != token.FUNC || tokens[1] != token.LPAREN {
+		return false
+	}
+	depth := 0
+	for i, tok := range tokens[1:] {
+		switch tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth += 1
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth -= 1
+		}
+		if depth == 0 {
+			rest := tokens[i+2:]
+			return len(rest) > 1 && rest[0] == token.IDENT && rest[1] == token.LPAREN
+		}
+	}
+	return false
+}
+
+// blank replaces text with spaces, keeping its lines
+func blank(text []byte) {
+	for i := range text {
+		if text[i] != '\n' {
+			text[i] = ' '
+		}
+	}
+}
+
+// parseSource parses the declarations and the body split from src, and
+// returns them in the order of src. The statements of the body are
+// parsed together, as the body of a function.
+func parseSource(ctx *Ctx, src string, decls []span, body string) ([]chunk, []error) {
+	var chunks []chunk
+	var errs []error
+	for _, d := range decls {
+		if c, err := parseDecl(ctx, src, d); err != nil {
+			errs = append(errs, err...)
+		} else {
+			chunks = append(chunks, c)
+		}
+	}
+
+	text := stmtHeader + body + "\n}"
+	bctx := *ctx
+	bctx.Input, bctx.Fset, bctx.Base = text, nil, 0
+	f, err := parser.ParseFile(token.NewFileSet(), "", text, 0)
+	if err != nil {
+		return nil, append(errs, parseErrors(src, 0, err)...)
+	}
+	for _, stmt := range f.Decls[0].(*ast.FuncDecl).Body.List {
+		chunks = append(chunks, chunk{&bctx, int(stmt.Pos()) - 1 - len(stmtHeader), stmt})
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].offset < chunks[j].offset
+	})
+	return chunks, nil
+}
+
+// parseDecl parses a declaration. The text given to go/parser is padded
+// so that offsets within it are those of src, shifted by the length of
+// the header.
+func parseDecl(ctx *Ctx, src string, d span) (chunk, []error) {
+	padding := []byte(src[:d.start])
+	blank(padding)
+	text := declHeader + string(padding) + src[d.start:d.end]
+
+	cctx := *ctx
+	cctx.Input, cctx.Fset, cctx.Base = text, nil, 0
+	f, err := parser.ParseFile(token.NewFileSet(), "", text, 0)
+	if err != nil {
+		return chunk{}, parseErrors(src, d.start, err)
+	} else if len(f.Imports) != 0 {
+		return chunk{}, []error{positionAt(src, d.start, errors.New("imports are not supported, packages are provided by Env.Pkgs"))}
+	}
+	return chunk{&cctx, d.start, f.Decls[0]}, nil
+}
+
+// parseErrors positions the errors of go/parser within src, or at offset
+// if they have no position
+func parseErrors(src string, offset int, err error) (errs []error) {
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			errs = append(errs, positionAt(src, e.Pos.Offset-len(stmtHeader), errors.New(e.Msg)))
+		}
+		return errs
+	}
+	return []error{positionAt(src, offset, err)}
+}
+
+// sourceError positions err within src. Errors which carry a node are
+// positioned at it, others at the offset of the statement which caused
+// them.
+func sourceError(src string, offset int, err error) error {
+	if e, ok := err.(ast.Node); ok && e.Pos().IsValid() {
+		offset = int(e.Pos()) - 1 - len(stmtHeader)
+		serr := positionAt(src, offset, err)
+		if end := int(e.End()) - 1 - len(stmtHeader); end >= offset && end <= len(src) {
+			pos := offsetPosition(src, end)
+			serr.EndLine, serr.EndColumn = pos.Line, pos.Column
+		}
+		return serr
+	}
+	return positionAt(src, offset, err)
+}
+
+// warnInSource returns ctx with the warnings of its Warn positioned
+// within the source, rather than the text handed to go/parser. That text
+// starts with a header on the first line.
+func warnInSource(ctx *Ctx) *Ctx {
+	warn := ctx.Warn
+	if warn == nil {
+		return ctx
+	}
+	wctx := *ctx
+	wctx.Warn = func(d Diagnostic) {
+		for _, pos := range []*Position{&d.Start, &d.End} {
+			if pos.Line == 1 {
+				pos.Column -= len(stmtHeader)
+			}
+		}
+		d.Related = nil
+		warn(d)
+	}
+	return &wctx
+}
+
+func positionAt(src string, offset int, err error) *SourceError {
+	if offset < 0 || offset > len(src) {
+		offset = 0
+	}
+	pos := offsetPosition(src, offset)
+	return &SourceError{Line: pos.Line, Column: pos.Column, Err: err}
+}
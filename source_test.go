@@ -0,0 +1,109 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func expectSourceErrors(t *testing.T, src string, env *Env, errorString ...string) {
+	errs := EvalSource(&Ctx{}, src, env)
+	ok := len(errs) == len(errorString)
+	for i := 0; ok && i < len(errs); i += 1 {
+		ok = errs[i].Error() == errorString[i]
+	}
+	if !ok {
+		t.Fatalf("Source '%s' produced errors %v, expected %v", src, errs, errorString)
+	}
+}
+
+func TestEvalSource(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["ToUpper"] = reflect.ValueOf(strings.ToUpper)
+	expectSourceErrors(t, `
+type pair struct{ A, B int }
+
+// Functions are declared before any statement runs
+greeting := greet("world")
+p := pair{3, 4}
+area := mul(p.A, p.B)
+
+func greet(name string) string { return ToUpper("hello ") + name }
+func mul(a, b int) int { return a * b }
+`, env)
+
+	expectResult(t, "greeting", env, "HELLO world")
+	expectResult(t, "area", env, int(12))
+	expectResult(t, "mul(2, 3)", env, int(6))
+}
+
+func TestEvalSourceFile(t *testing.T) {
+	env := makeEnv()
+	expectSourceErrors(t, "package main\n\nconst n = 3\nvar s = n * 2", env)
+
+	expectResult(t, "s", env, int(6))
+}
+
+func TestEvalSourceCheckErrors(t *testing.T) {
+	env := makeEnv()
+	expectSourceErrors(t, "x := 1\nx := 2\nif 1 {\n}\nreturn", env,
+		"2:1: no new variables on left side of :=",
+		"3:4: non-bool 1 used as if condition",
+		"5:1: return statement outside function")
+
+	// Nothing runs if there are check errors
+	if _, ok := env.Vars["x"]; ok {
+		t.Fatalf("Expected x to be undeclared")
+	}
+}
+
+func TestEvalSourceParseError(t *testing.T) {
+	env := makeEnv()
+	expectSourceErrors(t, "a := 1\nb := (a", env,
+		"2:8: expected ')', found newline")
+}
+
+func TestEvalSourceMethodDecl(t *testing.T) {
+	env := makeEnv()
+	expectSourceErrors(t, "type T int\nfunc (t T) Double() T { return t * 2 }\nfunc(t T) {}(1)", env,
+		"2:1: cannot declare method T.Double: reflect cannot create types with methods")
+}
+
+func TestEvalSourceRuntimeError(t *testing.T) {
+	env := makeEnv()
+	expectSourceErrors(t, "func div(a, b int) int {\n\treturn a / b\n}\nq := div(1, 0)", env,
+		"4:1: runtime error: integer divide by zero")
+}
+
+func TestEvalSourceStmtHeaders(t *testing.T) {
+	env := makeEnv()
+	expectSourceErrors(t, `
+sum := 0
+for i := 0; i < 4; i++ {
+	sum += i
+}
+if n := sum * 2; n > 10 {
+	sum = n
+}
+switch k := sum; k {
+case 12:
+	sum += 1
+}
+`, env)
+
+	expectResult(t, "sum", env, int(13))
+}
+
+func TestEvalSourcePartialRun(t *testing.T) {
+	env := makeEnv()
+	env.Funcs["half"] = reflect.ValueOf(func(n int) int { return n / 2 })
+
+	// Arguments are checked by evaluation, after the statements before
+	// them have run
+	expectSourceErrors(t, "x := half(4)\ny := half(\"a\")", env,
+		`2:11: cannot use "a" (type string) as type int in argument to half`)
+	expectResult(t, "x", env, int(2))
+	if _, ok := env.Vars["y"]; ok {
+		t.Fatalf("Expected y to be undeclared")
+	}
+}
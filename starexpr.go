@@ -3,21 +3,10 @@ package eval
 import (
 	"errors"
 	"reflect"
-	"fmt"
 )
 
 func evalStarExpr(ctx *Ctx, starExpr *StarExpr, env *Env) (*reflect.Value, bool, error) {
-	// return nil, false, errors.New("Star expressions not done yet")
-	var cexpr Expr
-	var errs []error
-	if cexpr, errs = CheckExpr(ctx, starExpr.X, env); len(errs) != 0 {
-		for _, cerr := range errs {
-			fmt.Printf("%v\n", cerr)
-		}
-		return nil, false, errors.New("Something wrong checking * expression")
-	}
-
-	xs, _, err := EvalExpr(ctx, cexpr, env)
+	xs, _, err := EvalExpr(ctx, starExpr.X.(Expr), env)
 	if err != nil {
 		return nil, false, err
 	} else if xs == nil {
@@ -0,0 +1,972 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go/ast"
+	"go/token"
+)
+
+// flow describes how control leaves a statement
+type flow int
+
+const (
+	flowNext flow = iota
+	flowBreak
+	flowContinue
+	flowFallthrough
+	flowReturn
+)
+
+// frame is the state of an executing function
+type frame struct {
+	// Pointers to the result variables
+	results []reflect.Value
+
+	// The target of a pending labeled break or continue
+	label string
+
+	// Calls made by defer statements, in the order deferred
+	defers []*deferred
+}
+
+// block is a lexical block being executed. Declarations update the Env
+// of the enclosing function in place, and the entries they shadow are
+// saved so that restore can undo them when the block exits. Blocks are
+// cheap, unlike the copies made by newScope.
+type block struct {
+	env   *Env
+	saved []shadowed
+}
+
+type shadowed struct {
+	name       string
+	v, c       reflect.Value
+	t          reflect.Type
+	hasV, hasC bool
+	hasT       bool
+}
+
+func (b *block) nested() *block {
+	return &block{env: b.env}
+}
+
+func (b *block) save(name string) {
+	s := shadowed{name: name}
+	s.v, s.hasV = b.env.Vars[name]
+	s.c, s.hasC = b.env.Consts[name]
+	s.t, s.hasT = b.env.Types[name]
+	b.saved = append(b.saved, s)
+}
+
+// declareVar declares a variable, given a pointer to its value
+func (b *block) declareVar(name string, ptr reflect.Value) {
+	if name == "_" {
+		return
+	}
+	envLock.Lock()
+	defer envLock.Unlock()
+
+	b.save(name)
+	delete(b.env.Consts, name)
+	delete(b.env.Types, name)
+	b.env.Vars[name] = ptr
+}
+
+// declare adds the names of a checked declaration to the block
+func (b *block) declare(ctx *Ctx, decl Decl) error {
+	if vdecl, ok := decl.(*VarDecl); ok {
+		return evalVarDecl(ctx, vdecl, b.env, b.declareVar)
+	}
+
+	envLock.Lock()
+	defer envLock.Unlock()
+	switch decl := decl.(type) {
+	case *ConstDecl:
+		for i, name := range decl.names {
+			if name != "_" {
+				b.save(name)
+				delete(b.env.Vars, name)
+				b.env.Consts[name] = decl.values[i]
+			}
+		}
+	case *TypeDecl:
+		for i, name := range decl.names {
+			if name != "_" {
+				b.save(name)
+				b.env.Types[name] = decl.types[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (b *block) restore() {
+	envLock.Lock()
+	defer envLock.Unlock()
+
+	for i := len(b.saved) - 1; i >= 0; i -= 1 {
+		s := b.saved[i]
+		if s.hasV {
+			b.env.Vars[s.name] = s.v
+		} else {
+			delete(b.env.Vars, s.name)
+		}
+		if s.hasC {
+			b.env.Consts[s.name] = s.c
+		} else {
+			delete(b.env.Consts, s.name)
+		}
+		if s.hasT {
+			b.env.Types[s.name] = s.t
+		} else {
+			delete(b.env.Types, s.name)
+		}
+	}
+	b.saved = nil
+}
+
+// EvalStmt evaluates a statement checked by CheckStmt. Names declared
+// by the statement are added to env.
+//
+// Calls deferred by the statement are made once it completes, as if it
+// were the body of a function.
+func EvalStmt(ctx *Ctx, stmt Stmt, env *Env) error {
+	ctx = ctx.start()
+	fr := &frame{}
+	_, err := evalStmt(ctx, stmt, &block{env: env}, fr)
+	return fr.runDefers(err)
+}
+
+func evalStmt(ctx *Ctx, stmt ast.Stmt, b *block, fr *frame) (flow, error) {
+	if err := ctx.step(); err != nil {
+		return flowNext, err
+	}
+	switch stmt := stmt.(type) {
+	case *EmptyStmt:
+		return flowNext, nil
+	case *DeclStmt:
+		return flowNext, b.declare(ctx, stmt.decl)
+	case *LabeledStmt:
+		return evalLabeledStmt(ctx, stmt.Stmt, stmt.Label.Name, b, fr)
+	case *ExprStmt:
+		_, _, err := EvalExpr(ctx, stmt.X.(Expr), b.env)
+		return flowNext, err
+	case *IncDecStmt:
+		return flowNext, evalIncDecStmt(ctx, stmt, b)
+	case *AssignStmt:
+		return flowNext, evalAssignStmt(ctx, stmt, b)
+	case *ReturnStmt:
+		return evalReturnStmt(ctx, stmt, b, fr)
+	case *BranchStmt:
+		return evalBranchStmt(stmt, fr)
+	case *DeferStmt:
+		d, err := evalDeferredCall(ctx, stmt.call, true, b)
+		if err == nil {
+			fr.defers = append(fr.defers, d)
+		}
+		return flowNext, err
+	case *GoStmt:
+		d, err := evalDeferredCall(ctx, stmt.call, false, b)
+		if err == nil {
+			goCall(d, b.env)
+		}
+		return flowNext, err
+	case *BlockStmt:
+		inner := b.nested()
+		defer inner.restore()
+		return evalStmtList(ctx, stmt.List, inner, fr)
+	case *IfStmt:
+		return evalIfStmt(ctx, stmt, b, fr)
+	case *SwitchStmt:
+		return evalSwitchStmt(ctx, stmt, "", b, fr)
+	case *TypeSwitchStmt:
+		return evalTypeSwitchStmt(ctx, stmt, "", b, fr)
+	case *ForStmt:
+		return evalForStmt(ctx, stmt, "", b, fr)
+	case *RangeStmt:
+		return evalRangeStmt(ctx, stmt, "", b, fr)
+	case *SendStmt:
+		return flowNext, evalSendStmt(ctx, stmt, b.env)
+	case *SelectStmt:
+		return evalSelectStmt(ctx, stmt, "", b, fr)
+	default:
+		return flowNext, errors.New(fmt.Sprintf("Stmt: Bad stmt (%+v)", stmt))
+	}
+}
+
+func evalStmtList(ctx *Ctx, list []ast.Stmt, b *block, fr *frame) (flow, error) {
+	for _, stmt := range list {
+		if f, err := evalStmt(ctx, stmt, b, fr); err != nil || f != flowNext {
+			return f, err
+		}
+	}
+	return flowNext, nil
+}
+
+func evalLabeledStmt(ctx *Ctx, stmt ast.Stmt, label string, b *block, fr *frame) (flow, error) {
+	switch stmt := stmt.(type) {
+	case *SwitchStmt:
+		return evalSwitchStmt(ctx, stmt, label, b, fr)
+	case *TypeSwitchStmt:
+		return evalTypeSwitchStmt(ctx, stmt, label, b, fr)
+	case *ForStmt:
+		return evalForStmt(ctx, stmt, label, b, fr)
+	case *RangeStmt:
+		return evalRangeStmt(ctx, stmt, label, b, fr)
+	case *SelectStmt:
+		return evalSelectStmt(ctx, stmt, label, b, fr)
+	default:
+		f, err := evalStmt(ctx, stmt, b, fr)
+		if f == flowBreak && fr.label == label {
+			fr.label = ""
+			return flowNext, err
+		}
+		return f, err
+	}
+}
+
+// breaks reports whether f, the flow out of the body of a loop or switch
+// labeled label, leaves that statement. Breaks targeting it are consumed.
+func breaks(f flow, label string, fr *frame) bool {
+	switch f {
+	case flowBreak:
+		if fr.label == "" || fr.label == label {
+			fr.label = ""
+			return false
+		}
+		return true
+	case flowContinue:
+		return fr.label != "" && fr.label != label
+	case flowReturn:
+		return true
+	default:
+		return false
+	}
+}
+
+func evalBranchStmt(stmt *BranchStmt, fr *frame) (flow, error) {
+	if stmt.Label != nil {
+		fr.label = stmt.Label.Name
+	}
+	switch stmt.Tok {
+	case token.BREAK:
+		return flowBreak, nil
+	case token.CONTINUE:
+		return flowContinue, nil
+	case token.FALLTHROUGH:
+		return flowFallthrough, nil
+	default:
+		return flowNext, errors.New(stmt.Tok.String() + " statements not implemented")
+	}
+}
+
+// evalDeferredCall evaluates the function and arguments of the call made
+// by a go or defer statement. A function literal called by a defer
+// statement may recover a panic.
+func evalDeferredCall(ctx *Ctx, call *CallExpr, isDefer bool, b *block) (*deferred, error) {
+	d := &deferred{ctx: ctx, env: b.env, call: call, typed: true}
+	generic, isGeneric := call.Fun.(*IndexListExpr)
+	if lit, ok := skipSuperfluousParens(call.Fun.(Expr)).(*FuncLit); ok && isDefer {
+		d.fun = makeDeferredFunc(ctx, lit.fn, newScope(b.env), d)
+	} else if isGeneric && !generic.fn.IsValid() {
+		// Instantiated once the arguments are known
+	} else if fun, typed, err := EvalExpr(ctx, call.Fun.(Expr), b.env); err != nil {
+		return nil, err
+	} else if fun == nil || (*fun)[0].Kind() != reflect.Func {
+		return nil, errors.New(fmt.Sprintf("cannot call non-function %s", at(ctx, call.Fun).Source()))
+	} else if (*fun)[0].IsNil() {
+		return nil, ErrNilDereference
+	} else {
+		d.fun, d.typed = (*fun)[0], typed
+	}
+
+	args, atyped, err := evalArgs(ctx, call, b.env)
+	if err != nil {
+		return nil, err
+	}
+	// The arguments are fixed when the statement runs
+	for _, arg := range args {
+		if arg != nil {
+			for i, v := range *arg {
+				(*arg)[i] = copyValue(v)
+			}
+		}
+	}
+	d.args, d.atyped = args, atyped
+	if !d.fun.IsValid() {
+		if d.fun, err = instantiateCall(ctx, generic, call, args, atyped); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// evalSingle evaluates an expression expected to have a single value.
+// Untyped nil is returned as an invalid Value.
+func evalSingle(ctx *Ctx, expr Expr, env *Env) (reflect.Value, bool, error) {
+	vs, typed, err := EvalExpr(ctx, expr, env)
+	if err != nil {
+		return reflect.Value{}, false, err
+	} else if vs == nil {
+		return reflect.Value{}, false, nil
+	}
+	v, err := expectSingleValue(ctx, *vs, expr)
+	return v, typed, err
+}
+
+// evalValues evaluates the right hand side of an assignment, which is
+// either a single multi-valued expression or one expression per value
+func evalValues(ctx *Ctx, exprs []ast.Expr, n int, env *Env) ([]reflect.Value, []bool, error) {
+	values := make([]reflect.Value, 0, n)
+	typed := make([]bool, 0, n)
+	if n == 2 && len(exprs) == 1 && isRecv(exprs[0]) {
+		// v, ok := <-ch
+		recv := skipSuperfluousParens(exprs[0].(Expr)).(*UnaryExpr)
+		v, ok, err := evalRecv(ctx, recv, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []reflect.Value{v, reflect.ValueOf(ok)}, []bool{true, false}, nil
+	} else if n == 2 && len(exprs) == 1 && isTypeAssert(exprs[0]) {
+		// v, ok := x.(T)
+		assert := skipSuperfluousParens(exprs[0].(Expr)).(*TypeAssertExpr)
+		v, ok, err := evalTypeAssertOk(ctx, assert, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []reflect.Value{v, reflect.ValueOf(ok)}, []bool{true, false}, nil
+	} else if n == 2 && len(exprs) == 1 && isIndex(exprs[0]) {
+		// v, ok := m[k]
+		index := skipSuperfluousParens(exprs[0].(Expr)).(*IndexExpr)
+		v, ok, err := evalIndexExprOk(ctx, index, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []reflect.Value{v, reflect.ValueOf(ok)}, []bool{true, false}, nil
+	} else if len(exprs) == 1 && n != 1 {
+		vs, _, err := EvalExpr(ctx, exprs[0].(Expr), env)
+		if err != nil {
+			return nil, nil, err
+		} else if vs == nil || len(*vs) != n {
+			m := 0
+			if vs != nil {
+				m = len(*vs)
+			}
+			return nil, nil, ErrAssignCount{at(ctx, exprs[0]), n, m}
+		}
+		for _, v := range *vs {
+			values = append(values, copyValue(v))
+			typed = append(typed, true)
+		}
+		return values, typed, nil
+	}
+	for _, expr := range exprs {
+		v, t, err := evalSingle(ctx, expr.(Expr), env)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, copyValue(v))
+		typed = append(typed, t)
+	}
+	return values, typed, nil
+}
+
+// copyValue copies the value of a variable, so that it is unaffected by
+// later assignments, as in a, b = b, a
+func copyValue(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// defaultValue converts an untyped value to the default type of its
+// constant kind, as in x := 1. The evaluator represents untyped integer
+// constants as int64, the default type of which is int.
+func defaultValue(v reflect.Value, typed bool) reflect.Value {
+	if !typed && v.Kind() == reflect.Int64 && v.Type() == reflect.TypeOf(int64(0)) {
+		return v.Convert(intType)
+	}
+	return v
+}
+
+// assignValue converts v to a value assignable to type t. expr is the
+// source of v, used for errors.
+func assignValue(ctx *Ctx, v reflect.Value, typed bool, t reflect.Type, expr ast.Expr) (reflect.Value, error) {
+	if !v.IsValid() {
+		switch t.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(t), nil
+		default:
+			return v, ErrBadAssignment{at(ctx, expr), ConstNil, t, "assignment"}
+		}
+	}
+	if !typed && t.Kind() == reflect.Interface {
+		v, typed = defaultValue(v, typed), true
+	}
+	if r, err := assignableValue(v, t, typed); err == nil {
+		return r, nil
+	}
+	return v, ErrBadAssignment{at(ctx, expr), defaultValue(v, typed).Type(), t, "assignment"}
+}
+
+// newVar allocates a variable initialised to v, returning a pointer to it
+func newVar(ctx *Ctx, v reflect.Value, typed bool, expr ast.Expr) (reflect.Value, error) {
+	if !v.IsValid() {
+		return v, ErrUntypedNil{at(ctx, expr)}
+	}
+	v = defaultValue(v, typed)
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr, nil
+}
+
+// evalLhs returns the settable Value on the left side of an assignment,
+// or an invalid Value for the blank identifier. Map elements cannot be
+// set in place, so for them a settable copy of the element is returned,
+// with a function storing the copy in the map. store is nil otherwise.
+func evalLhs(ctx *Ctx, lhs Expr, env *Env) (v reflect.Value, store func(), err error) {
+	if ident, ok := lhs.(*Ident); ok && ident.Name == "_" {
+		return reflect.Value{}, nil, nil
+	} else if index, ok := lhs.(*IndexExpr); ok {
+		x, _, err := evalSingle(ctx, index.X.(Expr), env)
+		if err != nil {
+			return x, nil, err
+		} else if x.Kind() == reflect.Map {
+			return evalMapLhs(ctx, x, index, env)
+		} else if x.Kind() == reflect.Ptr && x.Type().Elem().Kind() == reflect.Array {
+			x = x.Elem()
+		}
+		// The bytes of strings cannot be assigned
+		if x.Kind() != reflect.Array && x.Kind() != reflect.Slice {
+			return x, nil, ErrCannotAssign{at(ctx, lhs)}
+		}
+		elem, _, err := evalIndexExprInt(ctx, x, index, env)
+		if err != nil {
+			return x, nil, err
+		}
+		v = *elem
+	} else if v, _, err = evalSingle(ctx, lhs, env); err != nil {
+		return v, nil, err
+	}
+	if !v.CanSet() {
+		return v, nil, ErrCannotAssign{at(ctx, lhs)}
+	}
+	return v, nil, nil
+}
+
+// evalMapLhs returns a settable copy of the element of the map m indexed
+// by index, and the function which stores it in m. A new entry is
+// counted against ctx.Limits as it is made.
+func evalMapLhs(ctx *Ctx, m reflect.Value, index *IndexExpr, env *Env) (reflect.Value, func(), error) {
+	key, err := evalMapKey(ctx, m.Type(), index.Index, env)
+	if err != nil {
+		return key, nil, err
+	} else if m.IsNil() {
+		return m, nil, ErrNilMapAssignment
+	}
+	t := m.Type()
+	v := reflect.New(t.Elem()).Elem()
+	if elem := m.MapIndex(key); elem.IsValid() {
+		v.Set(elem)
+	} else if err := ctx.Limits.allocate(m.Len()+1, int64(t.Key().Size()+t.Elem().Size())); err != nil {
+		return v, nil, err
+	}
+	return v, func() { m.SetMapIndex(key, v) }, nil
+}
+
+func evalAssignStmt(ctx *Ctx, stmt *AssignStmt, b *block) error {
+	if stmt.Tok != token.DEFINE && stmt.Tok != token.ASSIGN {
+		return evalOpAssign(ctx, stmt.Lhs[0].(Expr), assignOps[stmt.Tok], stmt.Rhs[0].(Expr), b)
+	}
+
+	// Locations on the left are evaluated before values on the right
+	lhs := make([]reflect.Value, len(stmt.Lhs))
+	store := make([]func(), len(stmt.Lhs))
+	for i := range stmt.Lhs {
+		if stmt.Tok == token.DEFINE && stmt.newVars[i] {
+			continue
+		}
+		var err error
+		if lhs[i], store[i], err = evalLhs(ctx, stmt.Lhs[i].(Expr), b.env); err != nil {
+			return err
+		}
+	}
+
+	values, typed, err := evalValues(ctx, stmt.Rhs, len(stmt.Lhs), b.env)
+	if err != nil {
+		return err
+	}
+
+	for i := range stmt.Lhs {
+		src := stmt.Rhs[0]
+		if len(stmt.Rhs) == len(stmt.Lhs) {
+			src = stmt.Rhs[i]
+		}
+		if stmt.Tok == token.DEFINE && stmt.newVars[i] {
+			ptr, err := newVar(ctx, values[i], typed[i], src)
+			if err != nil {
+				return err
+			}
+			b.declareVar(stmt.Lhs[i].(*Ident).Name, ptr)
+		} else if lhs[i].IsValid() {
+			v, err := assignValue(ctx, values[i], typed[i], lhs[i].Type(), src)
+			if err != nil {
+				return err
+			}
+			lhs[i].Set(v)
+			if store[i] != nil {
+				store[i]()
+			}
+		}
+	}
+	return nil
+}
+
+var assignOps = map[token.Token]token.Token{
+	token.ADD_ASSIGN:     token.ADD,
+	token.SUB_ASSIGN:     token.SUB,
+	token.MUL_ASSIGN:     token.MUL,
+	token.QUO_ASSIGN:     token.QUO,
+	token.REM_ASSIGN:     token.REM,
+	token.AND_ASSIGN:     token.AND,
+	token.OR_ASSIGN:      token.OR,
+	token.XOR_ASSIGN:     token.XOR,
+	token.SHL_ASSIGN:     token.SHL,
+	token.SHR_ASSIGN:     token.SHR,
+	token.AND_NOT_ASSIGN: token.AND_NOT,
+}
+
+// evalOpAssign evaluates x op= y, or x++ and x-- when y is nil
+func evalOpAssign(ctx *Ctx, x Expr, op token.Token, y Expr, b *block) error {
+	lhs, store, err := evalLhs(ctx, x, b.env)
+	if err != nil || !lhs.IsValid() {
+		return err
+	}
+
+	rhs, rtyped := reflect.ValueOf(int64(1)), false
+	if y != nil {
+		if rhs, rtyped, err = evalSingle(ctx, y, b.env); err != nil {
+			return err
+		}
+	}
+
+	r, _, err := evalBinaryValues(ctx, lhs, true, op, rhs, rtyped)
+	if err != nil {
+		return err
+	}
+	lhs.Set(r.Convert(lhs.Type()))
+	if store != nil {
+		store()
+	}
+	return nil
+}
+
+func evalIncDecStmt(ctx *Ctx, stmt *IncDecStmt, b *block) error {
+	op := token.ADD
+	if stmt.Tok == token.DEC {
+		op = token.SUB
+	}
+	return evalOpAssign(ctx, stmt.X.(Expr), op, nil, b)
+}
+
+func evalReturnStmt(ctx *Ctx, stmt *ReturnStmt, b *block, fr *frame) (flow, error) {
+	if len(stmt.Results) == 0 {
+		return flowReturn, nil
+	}
+	values, typed, err := evalValues(ctx, stmt.Results, len(fr.results), b.env)
+	if err != nil {
+		return flowReturn, err
+	}
+	for i, result := range fr.results {
+		src := stmt.Results[0]
+		if len(stmt.Results) == len(fr.results) {
+			src = stmt.Results[i]
+		}
+		v, err := assignValue(ctx, values[i], typed[i], result.Type().Elem(), src)
+		if err != nil {
+			return flowReturn, err
+		}
+		result.Elem().Set(v)
+	}
+	return flowReturn, nil
+}
+
+// evalCondition evaluates the boolean condition of an if, for or switch
+func evalCondition(ctx *Ctx, cond Expr, context string, env *Env) (bool, error) {
+	v, _, err := evalSingle(ctx, cond, env)
+	if err != nil {
+		return false, err
+	} else if v.Kind() != reflect.Bool {
+		return false, ErrNonBoolCondition{at(ctx, cond), context}
+	}
+	return v.Bool(), nil
+}
+
+func evalIfStmt(ctx *Ctx, stmt *IfStmt, b *block, fr *frame) (flow, error) {
+	b = b.nested()
+	defer b.restore()
+
+	if stmt.Init != nil {
+		if _, err := evalStmt(ctx, stmt.Init, b, fr); err != nil {
+			return flowNext, err
+		}
+	}
+	if cond, err := evalCondition(ctx, stmt.Cond.(Expr), "if", b.env); err != nil {
+		return flowNext, err
+	} else if cond {
+		body := b.nested()
+		defer body.restore()
+		return evalStmtList(ctx, stmt.Body.List, body, fr)
+	} else if stmt.Else != nil {
+		return evalStmt(ctx, stmt.Else, b, fr)
+	}
+	return flowNext, nil
+}
+
+func evalSwitchStmt(ctx *Ctx, stmt *SwitchStmt, label string, b *block, fr *frame) (flow, error) {
+	b = b.nested()
+	defer b.restore()
+
+	if stmt.Init != nil {
+		if _, err := evalStmt(ctx, stmt.Init, b, fr); err != nil {
+			return flowNext, err
+		}
+	}
+
+	var tag reflect.Value
+	var tagTyped bool
+	if stmt.Tag != nil {
+		var err error
+		if tag, tagTyped, err = evalSingle(ctx, stmt.Tag.(Expr), b.env); err != nil {
+			return flowNext, err
+		}
+		tag = defaultValue(tag, tagTyped)
+	}
+
+	// Find the matching clause, or the default
+	match := -1
+	for i := 0; i < len(stmt.Body.List) && match == -1; i += 1 {
+		clause := stmt.Body.List[i].(*CaseClause)
+		if clause.List == nil {
+			continue
+		}
+		for _, expr := range clause.List {
+			var ok bool
+			var err error
+			if stmt.Tag == nil {
+				ok, err = evalCondition(ctx, expr.(Expr), "case", b.env)
+			} else {
+				ok, err = evalCaseMatch(ctx, tag, expr.(Expr), b.env)
+			}
+			if err != nil {
+				return flowNext, err
+			} else if ok {
+				match = i
+				break
+			}
+		}
+	}
+	if match == -1 {
+		for i, clause := range stmt.Body.List {
+			if clause.(*CaseClause).List == nil {
+				match = i
+			}
+		}
+	}
+	if match == -1 {
+		return flowNext, nil
+	}
+
+	for i := match; i < len(stmt.Body.List); i += 1 {
+		body := b.nested()
+		f, err := evalStmtList(ctx, stmt.Body.List[i].(*CaseClause).Body, body, fr)
+		body.restore()
+		if err != nil {
+			return f, err
+		} else if f != flowFallthrough {
+			if breaks(f, label, fr) || f == flowContinue {
+				return f, nil
+			}
+			return flowNext, nil
+		}
+	}
+	return flowNext, nil
+}
+
+func evalTypeSwitchStmt(ctx *Ctx, stmt *TypeSwitchStmt, label string, b *block, fr *frame) (flow, error) {
+	b = b.nested()
+	defer b.restore()
+
+	if stmt.Init != nil {
+		if _, err := evalStmt(ctx, stmt.Init, b, fr); err != nil {
+			return flowNext, err
+		}
+	}
+	x, _, err := evalSingle(ctx, stmt.x, b.env)
+	if err != nil {
+		return flowNext, err
+	} else if x.Kind() != reflect.Interface {
+		return flowNext, ErrInvalidTypeSwitch{at(ctx, stmt.x), x.Type()}
+	}
+
+	// Find the matching clause and type, or the default
+	var clause *TypeCaseClause
+	var match reflect.Type
+	for i := 0; i < len(stmt.Body.List) && clause == nil; i += 1 {
+		c := stmt.Body.List[i].(*TypeCaseClause)
+		for _, t := range c.types {
+			if t == nil && x.IsNil() || t != nil && !x.IsNil() && hasDynamicType(x, t) {
+				clause, match = c, t
+				break
+			}
+		}
+	}
+	if clause == nil {
+		for _, c := range stmt.Body.List {
+			if c := c.(*TypeCaseClause); c.List == nil {
+				clause = c
+			}
+		}
+	}
+	if clause == nil {
+		return flowNext, nil
+	}
+
+	body := b.nested()
+	defer body.restore()
+	if stmt.name != "" {
+		// The variable has the type of a clause of a single type, and
+		// otherwise that of x
+		v := x
+		if len(clause.types) == 1 && match != nil {
+			v = reflect.New(match).Elem()
+			v.Set(x.Elem())
+		}
+		if err := ctx.Limits.allocateVar(v.Type()); err != nil {
+			return flowNext, err
+		}
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		body.declareVar(stmt.name, ptr)
+	}
+
+	f, err := evalStmtList(ctx, clause.Body, body, fr)
+	if err != nil {
+		return f, err
+	} else if breaks(f, label, fr) || f == flowContinue {
+		return f, nil
+	}
+	return flowNext, nil
+}
+
+// hasDynamicType reports whether the interface value x, which is not
+// nil, holds a t, or implements t if it is an interface type
+func hasDynamicType(x reflect.Value, t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return x.Elem().Type().Implements(t)
+	}
+	return x.Elem().Type() == t
+}
+
+// evalCaseMatch reports whether the value of a case expression equals tag
+func evalCaseMatch(ctx *Ctx, tag reflect.Value, expr Expr, env *Env) (bool, error) {
+	v, typed, err := evalSingle(ctx, expr, env)
+	if err != nil {
+		return false, err
+	}
+	if !tag.IsValid() || !v.IsValid() {
+		// Comparison with nil
+		other := tag
+		if !tag.IsValid() {
+			other = v
+		}
+		return !other.IsValid() || isNil(other), nil
+	}
+	if v, err = assignValue(ctx, v, typed, tag.Type(), expr); err != nil {
+		return false, err
+	} else if !v.Type().Comparable() {
+		return false, errors.New(fmt.Sprintf("invalid case %s in switch (can only compare to nil)", at(ctx, expr).Source()))
+	}
+	return tag.Interface() == v.Interface(), nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+func evalForStmt(ctx *Ctx, stmt *ForStmt, label string, b *block, fr *frame) (flow, error) {
+	b = b.nested()
+	defer b.restore()
+
+	if stmt.Init != nil {
+		if _, err := evalStmt(ctx, stmt.Init, b, fr); err != nil {
+			return flowNext, err
+		}
+	}
+
+	// Each iteration has its own copy of the variables declared by init
+	var loopVars []string
+	if init, ok := stmt.Init.(*AssignStmt); ok && init.Tok == token.DEFINE {
+		for i, lhs := range init.Lhs {
+			if init.newVars[i] {
+				loopVars = append(loopVars, lhs.(*Ident).Name)
+			}
+		}
+	}
+
+	for {
+		if err := ctx.step(); err != nil {
+			return flowNext, err
+		}
+		if stmt.Cond != nil {
+			if cond, err := evalCondition(ctx, stmt.Cond.(Expr), "for", b.env); err != nil {
+				return flowNext, err
+			} else if !cond {
+				return flowNext, nil
+			}
+		}
+
+		body := b.nested()
+		f, err := evalStmtList(ctx, stmt.Body.List, body, fr)
+		body.restore()
+		if err != nil {
+			return f, err
+		} else if breaks(f, label, fr) {
+			return f, nil
+		} else if f == flowBreak {
+			return flowNext, nil
+		}
+		fr.label = ""
+
+		envLock.Lock()
+		for _, name := range loopVars {
+			old := b.env.Vars[name]
+			ptr := reflect.New(old.Type().Elem())
+			ptr.Elem().Set(old.Elem())
+			b.env.Vars[name] = ptr
+		}
+		envLock.Unlock()
+
+		if stmt.Post != nil {
+			if _, err := evalStmt(ctx, stmt.Post, b, fr); err != nil {
+				return flowNext, err
+			}
+		}
+	}
+}
+
+func evalRangeStmt(ctx *Ctx, stmt *RangeStmt, label string, b *block, fr *frame) (flow, error) {
+	x, typed, err := evalSingle(ctx, stmt.X.(Expr), b.env)
+	if err != nil {
+		return flowNext, err
+	} else if !x.IsValid() {
+		return flowNext, errors.New("cannot range over nil")
+	}
+	x = defaultValue(x, typed)
+	if x.Kind() == reflect.Ptr && x.Type().Elem().Kind() == reflect.Array {
+		x = x.Elem()
+	}
+
+	// iteration runs the body for one key and value
+	iteration := func(key, value reflect.Value) (flow, bool, error) {
+		if err := ctx.step(); err != nil {
+			return flowNext, false, err
+		}
+		body := b.nested()
+		defer body.restore()
+		if err := bindRangeVars(ctx, stmt, key, value, body); err != nil {
+			return flowNext, false, err
+		}
+		f, err := evalStmtList(ctx, stmt.Body.List, body, fr)
+		if err != nil {
+			return f, false, err
+		} else if breaks(f, label, fr) {
+			return f, false, nil
+		} else if f == flowBreak {
+			return flowNext, false, nil
+		}
+		fr.label = ""
+		return flowNext, true, nil
+	}
+
+	var f flow
+	more := true
+	switch x.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; more && i < x.Len(); i += 1 {
+			f, more, err = iteration(reflect.ValueOf(i), x.Index(i))
+		}
+	case reflect.String:
+		for i, r := range x.String() {
+			if f, more, err = iteration(reflect.ValueOf(i), reflect.ValueOf(r)); !more {
+				break
+			}
+		}
+	case reflect.Map:
+		iter := x.MapRange()
+		for more && iter.Next() {
+			f, more, err = iteration(iter.Key(), iter.Value())
+		}
+	case reflect.Chan:
+		for more {
+			v, ok, rerr := chanRecv(ctx, x, stmt.X.(Expr), stmt)
+			if rerr != nil {
+				return flowNext, rerr
+			} else if !ok {
+				break
+			}
+			f, more, err = iteration(v, reflect.Value{})
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for i := int64(0); more && i < x.Int(); i += 1 {
+			f, more, err = iteration(reflect.ValueOf(i).Convert(x.Type()), reflect.Value{})
+		}
+	default:
+		return flowNext, errors.New(fmt.Sprintf("cannot range over %s (type %v)", at(ctx, stmt.X).Source(), x.Type()))
+	}
+	if err != nil || f != flowNext {
+		return f, err
+	}
+	return flowNext, nil
+}
+
+func bindRangeVars(ctx *Ctx, stmt *RangeStmt, key, value reflect.Value, b *block) error {
+	vars := []struct {
+		expr ast.Expr
+		v    reflect.Value
+	}{{stmt.Key, key}, {stmt.Value, value}}
+
+	for _, rv := range vars {
+		if rv.expr == nil || !rv.v.IsValid() {
+			continue
+		}
+		if stmt.Tok == token.DEFINE {
+			ptr := reflect.New(rv.v.Type())
+			ptr.Elem().Set(rv.v)
+			b.declareVar(rv.expr.(*Ident).Name, ptr)
+		} else if lhs, store, err := evalLhs(ctx, rv.expr.(Expr), b.env); err != nil {
+			return err
+		} else if lhs.IsValid() {
+			if v, err := assignValue(ctx, rv.v, true, lhs.Type(), rv.expr); err != nil {
+				return err
+			} else {
+				lhs.Set(v)
+			}
+			if store != nil {
+				store()
+			}
+		}
+	}
+	return nil
+}
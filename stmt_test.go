@@ -0,0 +1,145 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStmtAssign(t *testing.T) {
+	env := makeEnv()
+	run(t, "a, b := 1, 2\na, b = b, a\nb += 10", env)
+
+	expectResult(t, "a", env, int(2))
+	expectResult(t, "b", env, int(11))
+}
+
+func TestStmtAssignMapIndex(t *testing.T) {
+	env := makeEnv()
+	m := map[string]int{"a": 1}
+	env.Vars["m"] = reflect.ValueOf(&m)
+	run(t, "m[\"a\"] = 5\nm[\"b\"] = 2\nm[\"a\"]++\nm[\"b\"] += 10\nm[\"c\"]--", env)
+
+	expected := map[string]int{"a": 6, "b": 12, "c": -1}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("Map is %v, expected %v", m, expected)
+	}
+}
+
+func TestStmtAssignNilMap(t *testing.T) {
+	env := makeEnv()
+	var m map[string]int
+	env.Vars["m"] = reflect.ValueOf(&m)
+
+	expectStmtError(t, "m[\"a\"] = 1", env, "panic: assignment to entry in nil map")
+}
+
+func TestStmtIfElse(t *testing.T) {
+	env := makeEnv()
+	run(t, `s := ""
+for i := 0; i < 4; i++ {
+	if r := i % 3; r == 0 {
+		s += "a"
+	} else if r == 1 {
+		s += "b"
+	} else {
+		s += "c"
+	}
+}`, env)
+
+	expectResult(t, "s", env, "abca")
+}
+
+func TestStmtBlockScope(t *testing.T) {
+	env := makeEnv()
+	run(t, "x := 1\n{\n\tx := 2\n\tx++\n}", env)
+
+	expectResult(t, "x", env, int(1))
+}
+
+func TestStmtSwitch(t *testing.T) {
+	env := makeEnv()
+	run(t, `n := 0
+for i := 0; i < 5; i++ {
+	switch i {
+	case 0, 1:
+		n += 1
+	case 2:
+		n += 10
+		fallthrough
+	case 3:
+		n += 100
+	default:
+		n += 1000
+	}
+}`, env)
+
+	expectResult(t, "n", env, int(1212))
+}
+
+func TestStmtRange(t *testing.T) {
+	env := makeEnv()
+	m := map[string]int{"a": 1}
+	env.Vars["m"] = reflect.ValueOf(&m)
+	run(t, `sum, keys := 0, ""
+for _, v := range []int{1, 2, 3} {
+	sum += v
+}
+for i := range "héllo" {
+	sum += i
+}
+for k, v := range m {
+	keys += k
+	sum += v
+}`, env)
+
+	// "héllo" has rune offsets 0, 1, 3, 4, 5
+	expectResult(t, "sum", env, int(20))
+	expectResult(t, "keys", env, "a")
+}
+
+func TestStmtLabeledBreakContinue(t *testing.T) {
+	env := makeEnv()
+	run(t, `n := 0
+outer:
+for i := 0; i < 3; i++ {
+	for j := 0; j < 3; j++ {
+		if j == 1 {
+			continue outer
+		}
+		if i == 2 {
+			break outer
+		}
+		n++
+	}
+}`, env)
+
+	expectResult(t, "n", env, int(2))
+}
+
+func TestStmtLoopVariables(t *testing.T) {
+	env := makeEnv()
+	run(t, `var fs []func() int
+for i := 0; i < 3; i++ {
+	fs = append(fs, func() int { return i })
+}
+sum := fs[0]() + fs[1]() + fs[2]()`, env)
+
+	// Each iteration has its own i
+	expectResult(t, "sum", env, int(3))
+}
+
+func TestStmtErrors(t *testing.T) {
+	env := makeEnv()
+	expectStmtError(t, "return", env,
+		"return statement outside function")
+	expectStmtError(t, "continue", env,
+		"continue is not in a loop")
+	expectStmtError(t, "for { break L }", env,
+		"label L not defined")
+	expectStmtError(t, "if 1 {}", env,
+		"non-bool 1 used as if condition")
+	expectStmtError(t, "1 = 2", env,
+		"cannot assign to 1")
+	expectStmtError(t, "switch { case true: fallthrough }", env,
+		"fallthrough statement out of place")
+}
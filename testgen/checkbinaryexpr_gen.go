@@ -2,30 +2,29 @@ package main
 
 import (
 	"fmt"
+	"go/token"
 	"io"
 	"text/template"
-	"go/token"
-	"github.com/0xfaded/go-testgen"
 )
 
 type Test struct{}
 
 var comment = template.Must(template.New("Comment").Parse(
-`// Test {{ .Lhs.Name }} {{ .Op.Value }} {{ .Rhs.Name }}
+	`// Test {{ .Lhs.Name }} {{ .Op.Value }} {{ .Rhs.Name }}
 `))
 
 var body = template.Must(template.New("Body").Parse(
-`	env := makeEnv()
+	`	env := makeEnv()
 {{ if .Errors }}
-	expectCheckError(t, `+"`{{ .Expr }}`"+`, env,{{ range .Errors }}
-		`+"`{{ . }}`"+`,{{ end }}
+	expectCheckError(t, ` + "`{{ .Expr }}`" + `, env,{{ range .Errors }}
+		` + "`{{ . }}`" + `,{{ end }}
 	)
 {{ else }}
-	expectConst(t, `+"`{{ .Expr }}`"+`, env, {{ .NewConstType }}({{ .Expr }}), {{ .ResultType }}){{ end }}
+	expectConst(t, ` + "`{{ .Expr }}`" + `, env, {{ .NewConstType }}({{ .Expr }}), {{ .ResultType }}){{ end }}
 `))
 
 func (*Test) Package() string {
-	return "interactive"
+	return "eval"
 }
 
 func (*Test) Prefix() string {
@@ -36,9 +35,9 @@ func (*Test) Imports() map[string]string {
 	return nil
 }
 
-func (*Test) Dimensions() []testgen.Dimension {
+func (*Test) Dimensions() []Dimension {
 	// All numeric values have been chosen to be a power of two, for good reason :)
-	types := []testgen.Element{
+	types := []Element{
 		{"Int", "4"},
 		{"Rune", "'@'"},
 		{"Float", "2.0"},
@@ -47,7 +46,7 @@ func (*Test) Dimensions() []testgen.Dimension {
 		{"String", `"abc"`},
 		{"Nil", "nil"},
 	}
-	ops := []testgen.Element{
+	ops := []Element{
 		{"Add", token.ADD},
 		{"Sub", token.SUB},
 		{"Mul", token.MUL},
@@ -56,7 +55,7 @@ func (*Test) Dimensions() []testgen.Dimension {
 		{"And", token.AND},
 		{"Or", token.OR},
 		{"Xor", token.XOR},
-		{"AndNot",  token.AND_NOT},
+		{"AndNot", token.AND_NOT},
 		{"Eql", token.EQL},
 		{"Neq", token.NEQ},
 		{"Leq", token.LEQ},
@@ -64,102 +63,43 @@ func (*Test) Dimensions() []testgen.Dimension {
 		{"Lss", token.LSS},
 		{"Gtr", token.GTR},
 	}
-	return []testgen.Dimension{
+	return []Dimension{
 		types,
 		ops,
 		types,
 	}
 }
 
-func (*Test) Comment(w io.Writer, elts ...testgen.Element) error {
-	vars := map[string] interface{} {
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	vars := map[string]interface{}{
 		"Lhs": elts[0],
-		"Op": elts[1],
+		"Op":  elts[1],
 		"Rhs": elts[2],
 	}
 
 	return comment.Execute(w, vars)
 }
 
-func (*Test) Body(w io.Writer, elts ...testgen.Element) error {
-	lhs := elts[0].Name
-	op  := elts[1].Value.(token.Token)
-	rhs := elts[2].Name
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	op := elts[1].Value.(token.Token)
 
 	expr := fmt.Sprintf("%v %v %v", elts[0].Value, op, elts[2].Value)
-	compileErrs, err := compileExpr(expr)
+	compileErrs, t, err := checkExpr(expr)
 	if err != nil {
 		return err
 	}
 
-	var newConstType string
-	var resultType string
-
-	switch lhs {
-	case "Int":
-		switch rhs {
-		case "Int":
-			newConstType = "NewConstInt64"
-			resultType = "ConstInt"
-		case "Rune":
-			newConstType = "NewConstRune"
-			resultType = "ConstRune"
-		case "Float":
-			newConstType = "NewConstFloat64"
-			resultType = "ConstFloat"
-		case "Complex":
-			newConstType = "NewConstComplex128"
-			resultType = "ConstComplex"
-		}
-	case "Rune":
-		switch rhs {
-		case "Int", "Rune":
-			newConstType = "NewConstRune"
-			resultType = "ConstRune"
-		case "Float":
-			newConstType = "NewConstFloat64"
-			resultType = "ConstFloat"
-		case "Complex":
-			newConstType = "NewConstComplex128"
-			resultType = "ConstComplex"
-		}
-	case "Float":
-		switch rhs {
-		case "Int", "Rune", "Float":
-			newConstType = "NewConstFloat64"
-			resultType = "ConstFloat"
-		case "Complex":
-			newConstType = "NewConstComplex128"
-			resultType = "ConstComplex"
-		}
-	case "Complex":
-		switch rhs {
-		case "Int", "Rune", "Float", "Complex":
-			newConstType = "NewConstComplex128"
-			resultType = "ConstComplex"
-		}
-	case "String":
-		switch rhs {
-		case "String":
-			resultType = "ConstString"
-		}
-	}
+	// The oracle gives the untyped type of the result, which is bool
+	// for comparisons
+	newConstType, resultType := constType(t)
 
-	// If its bool op, the result is a bool. Doesn't require a constructor
-	switch op {
-	case token.EQL, token.NEQ, token.LEQ, token.GEQ, token.LSS, token.GTR, token.LAND, token.LOR:
-		newConstType = ""
-		resultType = "ConstBool"
-	}
-
-	vars := map[string] interface{} {
-		"Expr": expr,
-		"Errors": compileErrs,
-		"Op": elts[1],
+	vars := map[string]interface{}{
+		"Expr":         expr,
+		"Errors":       compileErrs,
+		"Op":           elts[1],
 		"NewConstType": newConstType,
-		"ResultType": resultType,
+		"ResultType":   resultType,
 	}
 
 	return body.Execute(w, &vars)
 }
-
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type Test struct{}
+
+var comment = template.Must(template.New("Comment").Parse(
+	`// Test {{ .Fun.Name }}({{ .Args.Expr }})
+`))
+
+func (*Test) Package() string {
+	return "eval"
+}
+
+func (*Test) Prefix() string {
+	return "CheckCallExprArity"
+}
+
+func (*Test) Imports() map[string]string {
+	return map[string]string{"reflect": ""}
+}
+
+func (*Test) Dimensions() []Dimension {
+	funs := []Element{
+		{"Nullary", Operand{"f0", []Var{{"f0", "func()", "func() {}"}}}},
+		{"Unary", Operand{"f1", []Var{{"f1", "func(int) int", "func(x int) int { return x }"}}}},
+		{"Binary", Operand{"f2", []Var{{"f2", "func(int, string) int", "func(x int, y string) int { return x + len(y) }"}}}},
+		{"Variadic", Operand{"fv", []Var{{"fv", "func(...int) int", "func(xs ...int) int { return len(xs) }"}}}},
+		{"Int", Operand{"n", []Var{{"n", "int", "1"}}}},
+	}
+	args := []Element{
+		{"None", Operand{"", nil}},
+		{"Int", Operand{"1", nil}},
+		{"String", Operand{`"b"`, nil}},
+		{"Float", Operand{"1.5", nil}},
+		{"Nil", Operand{"nil", nil}},
+		{"IntInt", Operand{"1, 2", nil}},
+		{"IntString", Operand{`1, "b"`, nil}},
+		{"IntStringInt", Operand{`1, "b", 3`, nil}},
+		{"Spread", Operand{"s...", []Var{{"s", "[]int", "[]int{1, 2}"}}}},
+	}
+	return []Dimension{
+		funs,
+		args,
+	}
+}
+
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	return comment.Execute(w, map[string]interface{}{
+		"Fun":  elts[0],
+		"Args": elts[1].Value,
+	})
+}
+
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	fun, args := elts[0].Value.(Operand), elts[1].Value.(Operand)
+	return writeExprBody(w, fun.Expr+"("+args.Expr+")", fun, args)
+}
@@ -4,27 +4,26 @@ import (
 	"fmt"
 	"io"
 	"text/template"
-	"github.com/0xfaded/go-testgen"
 )
 
 type Test struct{}
 
 var comment = template.Must(template.New("Comment").Parse(
-`// Test {{ .Type.Value }}({{ .Value.Value }})
+	`// Test {{ .Type.Value }}({{ .Value.Value }})
 `))
 
 var body = template.Must(template.New("Body").Parse(
-`	env := makeEnv()
+	`	env := makeEnv()
 {{ if .Errors }}{{ if .TestErrs }}
-	expectCheckError(t, `+"`{{ .Expr }}`"+`, env,{{ range .Errors }}
-		`+"`{{ . }}`"+`,{{ end }}
+	expectCheckError(t, ` + "`{{ .Expr }}`" + `, env,{{ range .Errors }}
+		` + "`{{ . }}`" + `,{{ end }}
 	){{ else }}	_ = env{{ end }}
 {{ else }}
-	expectConst(t, `+"`{{ .Expr }}`"+`, env, {{ .Expr }}, reflect.TypeOf({{ .Expr }})){{ end }}
+	expectConst(t, ` + "`{{ .Expr }}`" + `, env, {{ .Expr }}, reflect.TypeOf({{ .Expr }})){{ end }}
 `))
 
 func (*Test) Package() string {
-	return "interactive"
+	return "eval"
 }
 
 func (*Test) Prefix() string {
@@ -32,11 +31,11 @@ func (*Test) Prefix() string {
 }
 
 func (*Test) Imports() map[string]string {
-	return map[string]string { "reflect": "" }
+	return map[string]string{"reflect": ""}
 }
 
-func (*Test) Dimensions() []testgen.Dimension {
-	types := []testgen.Element{
+func (*Test) Dimensions() []Dimension {
+	types := []Element{
 		{"Int8", "int8"},
 		{"Int16", "int16"},
 		{"Int32", "int32"},
@@ -54,7 +53,7 @@ func (*Test) Dimensions() []testgen.Dimension {
 		{"String", "string"},
 		{"Nil", "nil"},
 	}
-	values := []testgen.Element{
+	values := []Element{
 		{"From7bits", "0x7f"},
 		{"From8bits", "0xff"},
 		{"From15bits", "0x7fff"},
@@ -74,25 +73,25 @@ func (*Test) Dimensions() []testgen.Dimension {
 		{"FromString", `"abc"`},
 		{"FromNil", `nil`},
 	}
-	return []testgen.Dimension{
+	return []Dimension{
 		types,
 		values,
 	}
 }
 
-func (*Test) Comment(w io.Writer, elts ...testgen.Element) error {
-	vars := map[string] interface{} {
-		"Type": elts[0],
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	vars := map[string]interface{}{
+		"Type":  elts[0],
 		"Value": elts[1],
 	}
 
 	return comment.Execute(w, vars)
 }
 
-func (*Test) Body(w io.Writer, elts ...testgen.Element) error {
+func (*Test) Body(w io.Writer, elts ...Element) error {
 	expr := fmt.Sprintf("%v(%v)", elts[0].Value, elts[1].Value)
 
-	compileErrs, err := compileExpr(expr)
+	compileErrs, _, err := checkExpr(expr)
 	if err != nil {
 		return err
 	}
@@ -103,12 +102,11 @@ func (*Test) Body(w io.Writer, elts ...testgen.Element) error {
 		testErrs = false
 	}
 
-	vars := map[string] interface{} {
-		"Expr": expr,
-		"Errors": compileErrs,
+	vars := map[string]interface{}{
+		"Expr":     expr,
+		"Errors":   compileErrs,
 		"TestErrs": testErrs,
 	}
 
 	return body.Execute(w, &vars)
 }
-
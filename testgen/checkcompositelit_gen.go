@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type Test struct{}
+
+var comment = template.Must(template.New("Comment").Parse(
+	`// Test {{ .Type.Name }}{ {{- .Elts.Name -}} }
+`))
+
+func (*Test) Package() string {
+	return "eval"
+}
+
+func (*Test) Prefix() string {
+	return "CheckCompositeLit"
+}
+
+func (*Test) Imports() map[string]string {
+	return map[string]string{"reflect": ""}
+}
+
+func (*Test) Dimensions() []Dimension {
+	// [...] arrays are not implemented
+	types := []Element{
+		{"Slice", "[]int"},
+		{"Array", "[2]int"},
+		{"Map", "map[int]int"},
+		{"Struct", "T"},
+	}
+	elts := []Element{
+		{"Empty", ""},
+		{"One", "1"},
+		{"Two", "1, 2"},
+		{"Three", "1, 2, 3"},
+		{"Key", "1: 2"},
+		{"NegativeKey", "-1: 2"},
+		{"DuplicateKey", "1: 2, 1: 3"},
+		{"FloatKey", "1.5: 2"},
+		{"Mixed", "1, 0: 2"},
+		{"String", `"a"`},
+		{"Field", "A: 1"},
+		{"Fields", `A: 1, B: "b"`},
+		{"MissingField", "C: 1"},
+		{"Values", `1, "b"`},
+	}
+	return []Dimension{
+		types,
+		elts,
+	}
+}
+
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	return comment.Execute(w, map[string]interface{}{
+		"Type": elts[0],
+		"Elts": elts[1],
+	})
+}
+
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	expr := elts[0].Value.(string) + "{" + elts[1].Value.(string) + "}"
+	return writeExprBody(w, expr)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type Test struct{}
+
+var comment = template.Must(template.New("Comment").Parse(
+	`// Test {{ .X.Name }}[{{ .Index.Name }}]
+`))
+
+func (*Test) Package() string {
+	return "eval"
+}
+
+func (*Test) Prefix() string {
+	return "CheckIndexExpr"
+}
+
+func (*Test) Imports() map[string]string {
+	return map[string]string{"reflect": ""}
+}
+
+func (*Test) Dimensions() []Dimension {
+	// Constant indices in range of slices and strings must be in range of
+	// their values too, as valid expressions are evaluated by the test
+	xs := []Element{
+		{"Slice", Operand{"s", []Var{{"s", "[]int", "[]int{1, 2, 3, 4}"}}}},
+		{"Array", Operand{"a", []Var{{"a", "[3]int", "[3]int{1, 2, 3}"}}}},
+		{"ArrayPtr", Operand{"p", []Var{{"p", "*[3]int", "&[3]int{1, 2, 3}"}}}},
+		{"String", Operand{"str", []Var{{"str", "string", `"abcd"`}}}},
+		{"ConstString", Operand{`"abc"`, nil}},
+		{"Map", Operand{"m", []Var{{"m", "map[int]int", "map[int]int{1: 2}"}}}},
+		{"Int", Operand{"n", []Var{{"n", "int", "1"}}}},
+		{"Nil", Operand{"nil", nil}},
+	}
+	indices := []Element{
+		{"Int", Operand{"1", nil}},
+		{"Negative", Operand{"-1", nil}},
+		{"OutOfRange", Operand{"3", nil}},
+		{"Float", Operand{"1.5", nil}},
+		{"IntegralFloat", Operand{"1.0", nil}},
+		{"String", Operand{`"a"`, nil}},
+		{"Bool", Operand{"true", nil}},
+		{"Nil", Operand{"nil", nil}},
+		{"IntVar", Operand{"i", []Var{{"i", "int", "1"}}}},
+		{"FloatVar", Operand{"f", []Var{{"f", "float64", "1"}}}},
+	}
+	return []Dimension{
+		xs,
+		indices,
+	}
+}
+
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	return comment.Execute(w, map[string]interface{}{
+		"X":     elts[0],
+		"Index": elts[1],
+	})
+}
+
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	x, index := elts[0].Value.(Operand), elts[1].Value.(Operand)
+	return writeExprBody(w, x.Expr+"["+index.Expr+"]", x, index)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type Test struct{}
+
+var comment = template.Must(template.New("Comment").Parse(
+	`// Test {{ .X.Name }}.{{ .Sel.Name }}
+`))
+
+func (*Test) Package() string {
+	return "eval"
+}
+
+func (*Test) Prefix() string {
+	return "CheckSelectorExpr"
+}
+
+func (*Test) Imports() map[string]string {
+	return map[string]string{"reflect": ""}
+}
+
+func (*Test) Dimensions() []Dimension {
+	xs := []Element{
+		{"Struct", Operand{"st", []Var{{"st", "T", `T{1, "b"}`}}}},
+		{"StructPtr", Operand{"pt", []Var{{"pt", "*T", `&T{1, "b"}`}}}},
+		{"StructLit", Operand{`T{1, "b"}`, nil}},
+		{"Int", Operand{"n", []Var{{"n", "int", "1"}}}},
+		{"Nil", Operand{"nil", nil}},
+	}
+	sels := []Element{
+		{"Field", "A"},
+		{"StringField", "B"},
+		{"Missing", "C"},
+		{"Lower", "a"},
+	}
+	return []Dimension{
+		xs,
+		sels,
+	}
+}
+
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	return comment.Execute(w, map[string]interface{}{
+		"X":   elts[0],
+		"Sel": elts[1],
+	})
+}
+
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	x := elts[0].Value.(Operand)
+	return writeExprBody(w, x.Expr+"."+elts[1].Value.(string), x)
+}
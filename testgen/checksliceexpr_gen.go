@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io"
+	"text/template"
+)
+
+type Test struct{}
+
+var comment = template.Must(template.New("Comment").Parse(
+	`// Test {{ .X.Name }}{{ .Indices.Expr }}
+`))
+
+func (*Test) Package() string {
+	return "eval"
+}
+
+func (*Test) Prefix() string {
+	return "CheckSliceExpr"
+}
+
+func (*Test) Imports() map[string]string {
+	return map[string]string{"reflect": ""}
+}
+
+func (*Test) Dimensions() []Dimension {
+	// As for index expressions, constant indices in range of slices and
+	// strings must be in range of their values too
+	xs := []Element{
+		{"Slice", Operand{"s", []Var{{"s", "[]int", "[]int{1, 2, 3, 4}"}}}},
+		{"Array", Operand{"a", []Var{{"a", "[3]int", "[3]int{1, 2, 3}"}}}},
+		{"ArrayPtr", Operand{"p", []Var{{"p", "*[3]int", "&[3]int{1, 2, 3}"}}}},
+		{"ArrayLit", Operand{"[3]int{1, 2, 3}", nil}},
+		{"String", Operand{"str", []Var{{"str", "string", `"abcd"`}}}},
+		{"ConstString", Operand{`"abc"`, nil}},
+		{"Int", Operand{"n", []Var{{"n", "int", "1"}}}},
+		{"Nil", Operand{"nil", nil}},
+	}
+	indices := []Element{
+		{"All", Operand{"[:]", nil}},
+		{"Low", Operand{"[1:]", nil}},
+		{"High", Operand{"[:2]", nil}},
+		{"LowHigh", Operand{"[1:2]", nil}},
+		{"LowHighMax", Operand{"[1:2:3]", nil}},
+		{"HighMax", Operand{"[:2:3]", nil}},
+		{"Inverted", Operand{"[2:1]", nil}},
+		{"Negative", Operand{"[-1:]", nil}},
+		{"OutOfRange", Operand{"[:4]", nil}},
+		{"Float", Operand{"[1.5:]", nil}},
+		{"IntVar", Operand{"[i:]", []Var{{"i", "int", "1"}}}},
+	}
+	return []Dimension{
+		xs,
+		indices,
+	}
+}
+
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	return comment.Execute(w, map[string]interface{}{
+		"X":       elts[0],
+		"Indices": elts[1].Value,
+	})
+}
+
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	x, indices := elts[0].Value.(Operand), elts[1].Value.(Operand)
+	return writeExprBody(w, x.Expr+indices.Expr, x, indices)
+}
@@ -1,64 +1,187 @@
 package main
 
 import (
-	"bufio"
-	"go/build"
 	"fmt"
-
 	"io"
-	"io/ioutil"
-
-	"strings"
+	"regexp"
+	"text/template"
 
-	"os"
-	"os/exec"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 )
 
-func compileExpr(expr string) (compileErrors []string, err error) {
-	f, err := ioutil.TempFile("/tmp", "testgen")
+// checkExpr type checks expr with go/types, in process. It returns the
+// error go/types reports for expr, worded as gc words it, if any, and
+// otherwise the type of the result. Constant expressions keep their
+// untyped types, as they would in the package under test.
+func checkExpr(expr string) (compileErrors []string, t types.Type, err error) {
+	tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, expr)
 	if err != nil {
-		return nil, err
+		if terr, ok := err.(types.Error); ok {
+			return gcErrors(expr, []string{terr.Msg}), nil, nil
+		}
+		return nil, nil, err
 	}
-	defer os.Remove(f.Name())
+	return nil, tv.Type, nil
+}
 
-	_, err = fmt.Fprintf(f,
-`package main
-func f(...interface{}) {}
-func main() {
-	f(%s)
+// constType returns the constructor and reflect.Type of the package
+// under test for a constant of the untyped type t. The constructor is
+// "" for types whose constants need none.
+func constType(t types.Type) (newConstType, resultType string) {
+	basic, ok := t.(*types.Basic)
+	if !ok {
+		return "", ""
+	}
+	switch basic.Kind() {
+	case types.UntypedInt:
+		return "NewConstInt64", "ConstInt"
+	case types.UntypedRune:
+		return "NewConstRune", "ConstRune"
+	case types.UntypedFloat:
+		return "NewConstFloat64", "ConstFloat"
+	case types.UntypedComplex:
+		return "NewConstComplex128", "ConstComplex"
+	case types.UntypedBool:
+		return "", "ConstBool"
+	case types.UntypedString:
+		return "", "ConstString"
+	case types.UntypedNil:
+		return "", "ConstNil"
+	}
+	return "", ""
+}
+
+// Var is a variable declared by a generated test, both in the test
+// itself and in the Env the expression is evaluated in
+type Var struct {
+	Name, Type, Value string
 }
-`, expr);
 
+// Operand is an operand of a generated expression and the variables
+// it uses
+type Operand struct {
+	Expr string
+	Vars []Var
+}
+
+// typeDecls declares the named types generated tests may use. Tests
+// that do declare them locally, so that their reflect names are those
+// of the package under test.
+const typeDecls = `type T struct { A int; B string }`
+
+// checkExprWith is checkExpr for an expression using vars and the types
+// of typeDecls. The types are declared in a package named after the
+// package under test, so that diagnostics name them as reflect does.
+// Errors are worded as gc words them, as by checkExpr.
+func checkExprWith(vars []Var, expr string) (compileErrors []string, t types.Type, err error) {
+	fset := token.NewFileSet()
+	typesFile, err := parser.ParseFile(fset, "types.go", "package eval\n"+typeDecls, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	typesPkg, err := new(types.Config).Check("eval", fset, []*ast.File{typesFile}, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// -e prints all errors
-	cmd := exec.Command(build.ToolDir + "/8g", "-e", "-o", "/dev/null", f.Name())
-	stdout, err := cmd.StdoutPipe()
+	src := "package main\nimport . \"eval\"\nvar _ T\n"
+	for _, v := range vars {
+		src += fmt.Sprintf("var %s %s = %s\n", v.Name, v.Type, v.Value)
+	}
+	file, err := parser.ParseFile(fset, "main.go", src, 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if err := cmd.Start(); err != nil {
-		return nil, err
+	conf := types.Config{Importer: importer(func(string) (*types.Package, error) {
+		return typesPkg, nil
+	})}
+	pkg, err := conf.Check("main", fset, []*ast.File{file}, nil)
+	if err != nil {
+		return nil, nil, err
 	}
-	buf := bufio.NewReader(stdout)
-
-	line, rerr := buf.ReadString('\n')
-	for rerr == nil {
-		if strings.Index(line, ": ") != -1 {
-			// Remove filename prefix
-			s := strings.SplitN(line, ": ", 2)[1]
-			// Remove trailing \n
-			s = s[:len(s)-1]
-			compileErrors = append(compileErrors, s)
+
+	// Check within the file, so that the dot import is in scope
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	typeOf := func(x ast.Expr) types.TypeAndValue {
+		info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+		types.CheckExpr(fset, pkg, file.End()-1, x, info)
+		return info.Types[x]
+	}
+	if err := types.CheckExpr(fset, pkg, file.End()-1, node, nil); err != nil {
+		if terr, ok := err.(types.Error); ok {
+			return gcErrorsWith(node, expr, terr.Msg, typeOf), nil, nil
 		}
-		line, rerr = buf.ReadString('\n')
+		return nil, nil, err
 	}
-	if rerr != io.EOF {
-		return nil, rerr
-	} else {
-		return compileErrors, nil
+	return nil, typeOf(node).Type, nil
+}
+
+type importer func(path string) (*types.Package, error)
+
+func (f importer) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+var typeName = regexp.MustCompile(`\bT\b`)
+
+// usesVars returns the variables used by operands, without duplicates
+func usesVars(operands ...Operand) (vars []Var) {
+	seen := map[string]bool{}
+	for _, operand := range operands {
+		for _, v := range operand.Vars {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				vars = append(vars, v)
+			}
+		}
 	}
+	return vars
 }
 
+var exprBody = template.Must(template.New("Body").Parse(
+	`	env := makeEnv()
+{{ if .UsesT }}	{{ .TypeDecls }}
+	env.Types["T"] = reflect.TypeOf(T{})
+{{ end }}{{ range .Vars }}	var {{ .Name }} {{ .Type }} = {{ .Value }}
+	env.Vars["{{ .Name }}"] = reflect.ValueOf(&{{ .Name }})
+{{ end }}{{ if .Errors }}
+	expectCompileError(t, ` + "`{{ .Expr }}`" + `, env,{{ range .Errors }}
+		` + "`{{ . }}`" + `,{{ end }}
+	)
+{{ else if .Void }}
+	expectVoid(t, ` + "`{{ .Expr }}`" + `, env)
+{{ else }}
+	expectResult(t, ` + "`{{ .Expr }}`" + `, env, {{ .Expr }})
+{{ end }}`))
+
+// writeExprBody writes the body of a test of expr, which uses the
+// variables of operands. A valid expr is expected to yield the value
+// it does when compiled into the test, so it must not panic.
+func writeExprBody(w io.Writer, expr string, operands ...Operand) error {
+	vars := usesVars(operands...)
+	compileErrs, t, err := checkExprWith(vars, expr)
+	if err != nil {
+		return err
+	}
+
+	usesT := typeName.MatchString(expr)
+	for _, v := range vars {
+		usesT = usesT || typeName.MatchString(v.Type)
+	}
+
+	tuple, void := t.(*types.Tuple)
+	return exprBody.Execute(w, map[string]interface{}{
+		"Expr":      expr,
+		"Errors":    compileErrs,
+		"Vars":      vars,
+		"UsesT":     usesT,
+		"TypeDecls": typeDecls,
+		"Void":      void && tuple.Len() == 0,
+	})
+}
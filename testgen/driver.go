@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Element is a named value of one dimension of the generated tests
+type Element struct {
+	Name  string
+	Value interface{}
+}
+
+// Dimension is a list of elements, one of which is used by each test
+type Dimension []Element
+
+// Generator generates a test for every combination of one element of
+// each of its dimensions. The name of each test is its prefix followed
+// by the names of the elements.
+type Generator interface {
+	Package() string
+	Prefix() string
+
+	// Imports other than "testing", mapped to their local names or ""
+	Imports() map[string]string
+	Dimensions() []Dimension
+
+	// Comment and Body write the comment preceding a test and the
+	// statements of its body
+	Comment(w io.Writer, elts ...Element) error
+	Body(w io.Writer, elts ...Element) error
+}
+
+// Generate writes the test file of g to w
+func Generate(g Generator, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "package %s\n\nimport (\n", g.Package()); err != nil {
+		return err
+	}
+	imports := []string{"testing"}
+	for path := range g.Imports() {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		if name := g.Imports()[path]; name != "" {
+			fmt.Fprintf(w, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(w, "\t%q\n", path)
+		}
+	}
+	if _, err := fmt.Fprintf(w, ")\n"); err != nil {
+		return err
+	}
+	return generate(g, w, g.Dimensions(), nil)
+}
+
+func generate(g Generator, w io.Writer, dims []Dimension, elts []Element) error {
+	if len(dims) > 0 {
+		for _, elt := range dims[0] {
+			if err := generate(g, w, dims[1:], append(elts, elt)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	name := g.Prefix()
+	for _, elt := range elts {
+		name += elt.Name
+	}
+	fmt.Fprintf(w, "\n")
+	if err := g.Comment(w, elts...); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "func Test%s(t *testing.T) {\n", name)
+	if err := g.Body(w, elts...); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "}\n")
+	return err
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"go/ast"
+	"go/parser"
+)
+
+type Test struct{}
+
+// noConversion is a type no operand converts to, so that go/types quotes
+// the operand in its error
+const noConversion = "struct{ noConversion int }"
+
+var comment = template.Must(template.New("Comment").Parse(
+	`// Test {{ .Expr }}, printed by go/types in
+// {{ .Msg }}
+`))
+
+var body = template.Must(template.New("Body").Parse(
+	`	env := makeEnv()
+{{ if .UsesT }}	{{ .TypeDecls }}
+	env.Types["T"] = reflect.TypeOf(T{})
+{{ end }}{{ range .Vars }}	var {{ .Name }} {{ .Type }} = {{ .Value }}
+	env.Vars["{{ .Name }}"] = reflect.ValueOf(&{{ .Name }})
+{{ end }}
+	expectString(t, ` + "`{{ .Expr }}`" + `, env, ` + "`{{ .String }}`" + `)
+`))
+
+func (*Test) Package() string {
+	return "eval"
+}
+
+func (*Test) Prefix() string {
+	return "ExprString"
+}
+
+func (*Test) Imports() map[string]string {
+	return map[string]string{"reflect": ""}
+}
+
+func (*Test) Dimensions() []Dimension {
+	s := Var{"s", "[]int", "nil"}
+	m := Var{"m", "map[string]int", "nil"}
+	x := Var{"x", "interface{}", "nil"}
+	p := Var{"p", "*T", "nil"}
+	ch := Var{"ch", "chan int", "nil"}
+
+	exprs := []Element{
+		{"CompositeLit", Operand{`T{1, "b"}`, nil}},
+		{"EmptyCompositeLit", Operand{"T{}", nil}},
+		{"SliceLit", Operand{"[]int{1}", nil}},
+		{"ArrayLit", Operand{"[3]int{}", nil}},
+		{"MapLit", Operand{"map[string]int{}", nil}},
+		{"PtrSliceLit", Operand{"[]*T{}", nil}},
+		{"AddressOfLit", Operand{"&T{}", nil}},
+		{"FuncLit", Operand{"func(a int, b string) (int, error) { return 0, nil }", nil}},
+		{"EmptyFuncLit", Operand{"func() {}", nil}},
+		{"Slice", Operand{"s[1:2]", []Var{s}}},
+		{"Slice3", Operand{"s[1:2:3]", []Var{s}}},
+		{"SliceAll", Operand{"s[:]", []Var{s}}},
+		{"Index", Operand{"s[1]", []Var{s}}},
+		{"IndexBinary", Operand{"s[len(s)-1]", []Var{s}}},
+		{"MapIndex", Operand{`m["a"]`, []Var{m}}},
+		{"TypeAssert", Operand{"x.(string)", []Var{x}}},
+		{"Selector", Operand{"p.A", []Var{p}}},
+		{"Star", Operand{"*p", []Var{p}}},
+		{"Receive", Operand{"<-ch", []Var{ch}}},
+		{"Negate", Operand{"-len(s)", []Var{s}}},
+		{"Builtin", Operand{"len(s)", []Var{s}}},
+		{"Ellipsis", Operand{"append(s, s...)", []Var{s}}},
+		{"Paren", Operand{"(s)", []Var{s}}},
+		{"ParenBinary", Operand{"(1 + len(s))", []Var{s}}},
+		{"RecvChanConversion", Operand{"(<-chan int)(nil)", nil}},
+		{"SendChanConversion", Operand{"(chan<- int)(nil)", nil}},
+		{"ChanConversion", Operand{"(chan int)(nil)", nil}},
+		{"FuncConversion", Operand{"(func(int) string)(nil)", nil}},
+		{"StructPtrConversion", Operand{"(*struct{ A int; B, C string })(nil)", nil}},
+		{"InterfaceConversion", Operand{"(interface{ M() })(nil)", nil}},
+	}
+	return []Dimension{
+		exprs,
+	}
+}
+
+func (*Test) Comment(w io.Writer, elts ...Element) error {
+	operand := elts[0].Value.(Operand)
+	msg, _, err := quoteOperand(operand)
+	if err != nil {
+		return err
+	}
+	return comment.Execute(w, map[string]interface{}{
+		"Expr": operand.Expr,
+		"Msg":  msg,
+	})
+}
+
+func (*Test) Body(w io.Writer, elts ...Element) error {
+	operand := elts[0].Value.(Operand)
+	_, s, err := quoteOperand(operand)
+	if err != nil {
+		return err
+	}
+	usesT := typeName.MatchString(operand.Expr)
+	for _, v := range operand.Vars {
+		usesT = usesT || typeName.MatchString(v.Type)
+	}
+	return body.Execute(w, map[string]interface{}{
+		"Expr":      operand.Expr,
+		"String":    s,
+		"Vars":      operand.Vars,
+		"UsesT":     usesT,
+		"TypeDecls": typeDecls,
+	})
+}
+
+// quoteOperand returns the error go/types reports converting operand to
+// noConversion, and the operand as quoted in it, reworded as gc prints
+// it
+func quoteOperand(operand Operand) (msg, s string, err error) {
+	errs, _, err := checkExprWith(operand.Vars, noConversion+"("+operand.Expr+")")
+	if err != nil {
+		return "", "", err
+	} else if len(errs) == 0 {
+		return "", "", fmt.Errorf("%s converts to %s", operand.Expr, noConversion)
+	}
+	m := quotedOperand.FindStringSubmatch(errs[0])
+	if m == nil {
+		return "", "", fmt.Errorf("unexpected error converting %s: %s", operand.Expr, errs[0])
+	}
+	x, err := parser.ParseExpr(operand.Expr)
+	if err != nil {
+		return "", "", err
+	}
+	return errs[0], gcOperand(m[1], x), nil
+}
+
+// gcOperand rewords an operand x, as go/types quotes it, as gc prints
+// it: function literals in full with their bodies elided, rather than as
+// (T literal), and without superfluous parentheses
+func gcOperand(s string, x ast.Expr) string {
+	s = funcLiteral.ReplaceAllString(s, "$1 {…}")
+	for {
+		paren, ok := x.(*ast.ParenExpr)
+		if !ok {
+			return s
+		} else if _, ok := paren.X.(*ast.BinaryExpr); ok {
+			return s
+		}
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+		x = paren.X
+	}
+}
+
+var (
+	quotedOperand = regexp.MustCompile(`^cannot convert (.+) \([\w, ]+ of (?:\w+ )?type .+\) to type struct\{noConversion int\}$`)
+	funcLiteral   = regexp.MustCompile(`\((func\(.*?) literal\)`)
+)
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+// gcErrors rewords the errors go/types reports for the constant
+// expression expr as gc reports them, which is the wording of the
+// package under test. Errors no rule applies to are returned as is.
+func gcErrors(expr string, errs []string) []string {
+	if len(errs) != 1 {
+		return errs
+	}
+	msg := errs[0]
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return errs
+	}
+
+	switch node := node.(type) {
+	case *ast.CallExpr:
+		if ident, ok := node.Fun.(*ast.Ident); ok && ident.Name == "nil" {
+			return []string{"use of untyped nil"}
+		}
+		if len(node.Args) == 1 && strings.HasPrefix(msg, "cannot convert ") {
+			if gc := gcConversionErrors(types.ExprString(node.Fun), node.Args[0]); gc != nil {
+				return gc
+			}
+		}
+
+	case *ast.UnaryExpr:
+		if strings.Contains(msg, " not defined on ") {
+			switch x := untypedKind(node.X); x {
+			case types.UntypedFloat, types.UntypedComplex:
+				return []string{fmt.Sprintf("illegal constant expression %v ideal", node.Op)}
+			case types.UntypedNil:
+				return []string{fmt.Sprintf("invalid operation: %v nil", node.Op)}
+			default:
+				return []string{fmt.Sprintf("invalid operation: %v ideal %v", node.Op, defaultName(x))}
+			}
+		}
+
+	case *ast.BinaryExpr:
+		x, y := untypedKind(node.X), untypedKind(node.Y)
+		if strings.Contains(msg, " (mismatched types ") {
+			return gcMismatchedErrors(node, x, y)
+		}
+		if strings.Contains(msg, " not defined on ") {
+			kind := x
+			if y > x {
+				kind = y
+			}
+			switch {
+			case kind == types.UntypedFloat && node.Op == token.REM:
+				return []string{"illegal constant expression: floating-point % operation"}
+			case kind == types.UntypedFloat || kind == types.UntypedComplex:
+				return []string{fmt.Sprintf("illegal constant expression: ideal %v ideal", node.Op)}
+			default:
+				return []string{fmt.Sprintf("invalid operation: %s (operator %v not defined on %s)",
+					gcString(node), node.Op, defaultName(kind))}
+			}
+		}
+	}
+	return errs
+}
+
+// gcConversionErrors returns the errors of converting the untyped
+// constant x to the basic type named to, or nil if there is no rule
+func gcConversionErrors(to string, x ast.Expr) []string {
+	tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, types.ExprString(x))
+	if err != nil || tv.Value == nil {
+		return nil
+	}
+	kind := tv.Type.(*types.Basic).Kind()
+	target, ok := types.Universe.Lookup(to).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	info := target.Type().Underlying().(*types.Basic).Info()
+
+	switch {
+	case kind == types.UntypedBool || kind == types.UntypedString || info&types.IsString != 0:
+		return []string{
+			fmt.Sprintf("cannot convert %s to type %s", gcString(x), to),
+			fmt.Sprintf("cannot convert %s (type %s) to type %s", gcString(x), defaultName(kind), to),
+		}
+	case info&types.IsInteger != 0:
+		value := gcConstString(tv.Value)
+		errs := []string{fmt.Sprintf("constant %s truncated to integer", value)}
+		if constant.Sign(constant.Imag(tv.Value)) != 0 {
+			errs = append(errs, fmt.Sprintf("constant %s truncated to real", value))
+		}
+		return errs
+	case info&types.IsFloat != 0:
+		return []string{fmt.Sprintf("constant %s truncated to real", gcConstString(tv.Value))}
+	}
+	return nil
+}
+
+// gcMismatchedErrors returns the errors of a binary operation on
+// untyped operands of kinds x and y which cannot be converted to a
+// common type. gc converts the operand it does not take the type from,
+// or both operands to int if neither is numeric.
+func gcMismatchedErrors(node *ast.BinaryExpr, x, y types.BasicKind) []string {
+	var errs []string
+	convert := func(operand ast.Expr, kind types.BasicKind) {
+		errs = append(errs, fmt.Sprintf("cannot convert %s to type %s", gcString(operand), defaultName(kind)))
+	}
+	switch {
+	case x == types.UntypedBool || isNumeric(x):
+		convert(node.Y, x)
+	case isNumeric(y):
+		convert(node.X, y)
+	default:
+		convert(node.X, types.UntypedInt)
+		convert(node.Y, types.UntypedInt)
+	}
+	return append(errs, fmt.Sprintf("invalid operation: %s (mismatched types %s and %s)",
+		gcString(node), gcTypeName(x), gcTypeName(y)))
+}
+
+// untypedKind returns the kind of the untyped constant or nil x
+func untypedKind(x ast.Expr) types.BasicKind {
+	tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, types.ExprString(x))
+	if err != nil {
+		return types.Invalid
+	}
+	if basic, ok := tv.Type.(*types.Basic); ok {
+		return basic.Kind()
+	}
+	return types.Invalid
+}
+
+func isNumeric(kind types.BasicKind) bool {
+	return kind >= types.UntypedInt && kind <= types.UntypedComplex
+}
+
+// defaultName names the default type of the untyped kind
+func defaultName(kind types.BasicKind) string {
+	switch kind {
+	case types.UntypedBool:
+		return "bool"
+	case types.UntypedInt:
+		return "int"
+	case types.UntypedRune:
+		return "rune"
+	case types.UntypedFloat:
+		return "float64"
+	case types.UntypedComplex:
+		return "complex128"
+	case types.UntypedString:
+		return "string"
+	case types.UntypedNil:
+		return "nil"
+	}
+	return "invalid type"
+}
+
+// gcTypeName names kind in a mismatched types error, where nil has
+// the placeholder type <T>
+func gcTypeName(kind types.BasicKind) string {
+	if kind == types.UntypedNil {
+		return "<T>"
+	}
+	return defaultName(kind)
+}
+
+// gcString prints x as the package under test does, with numeric
+// literals replaced by their values
+func gcString(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.INT, token.FLOAT, token.IMAG:
+			return gcConstString(constant.MakeFromLiteral(x.Value, x.Kind, 0))
+		}
+	case *ast.BinaryExpr:
+		return gcString(x.X) + " " + x.Op.String() + " " + gcString(x.Y)
+	case *ast.ParenExpr:
+		return "(" + gcString(x.X) + ")"
+	}
+	return types.ExprString(x)
+}
+
+// gcConstString prints the numeric constant v as the package under test
+// does: integral parts exactly, others as %g, and complex numbers as
+// re+imi without a zero real part
+func gcConstString(v constant.Value) string {
+	if v.Kind() != constant.Complex {
+		return gcRealString(v)
+	}
+	s := ""
+	if re := constant.Real(v); constant.Sign(re) != 0 {
+		s = gcRealString(re) + "+"
+	}
+	return s + gcRealString(constant.Imag(v)) + "i"
+}
+
+func gcRealString(v constant.Value) string {
+	if i := constant.ToInt(v); i.Kind() == constant.Int {
+		return i.ExactString()
+	}
+	f, _ := constant.Float64Val(v)
+	return fmt.Sprintf("%g", f)
+}
+
+// gcErrorsWith rewords the error msg go/types reports for expr, an
+// expression using variables, as gc reports it. typeOf returns the type
+// and value of operands. src is the source of expr, which gc prints
+// where go/types abbreviates it.
+func gcErrorsWith(expr ast.Expr, src string, msg string, typeOf func(ast.Expr) types.TypeAndValue) []string {
+	source := func(x ast.Node) string {
+		return src[x.Pos()-1 : x.End()-1]
+	}
+	if m := firstLine.FindStringSubmatch(msg); m != nil {
+		msg = m[1]
+	}
+
+	switch {
+	case msg == "cannot index nil" || msg == "cannot slice nil" || nilSelector.MatchString(msg):
+		return []string{"use of untyped nil"}
+	case undefinedName.MatchString(msg):
+		return []string{undefinedName.ReplaceAllString(msg, "$1 undefined")}
+	case notAFunction.MatchString(msg):
+		return []string{notAFunction.ReplaceAllString(msg, "cannot call non-function $1 (type $2)")}
+	case duplicateIndex.MatchString(msg):
+		return []string{duplicateIndex.ReplaceAllString(msg, "duplicate index in array literal: $1")}
+	case litIndexBounds.MatchString(msg):
+		return []string{litIndexBounds.ReplaceAllString(msg, "array index $1 out of bounds [0:$2]")}
+	case unusable.MatchString(msg):
+		m := unusable.FindStringSubmatch(msg)
+		from := gcOperandType(m[2])
+		switch {
+		case m[4] == "map index":
+			return []string{fmt.Sprintf("cannot convert %s (type %s) to type %s", m[1], from, m[3])}
+		case strings.HasSuffix(m[4], " literal"):
+			return []string{fmt.Sprintf("cannot use %s (type %s) as type %s in assignment", m[1], from, m[3])}
+		default:
+			return []string{fmt.Sprintf("cannot use %s (type %s) as type %s in %s", m[1], from, m[3], m[4])}
+		}
+	case unusableNil.MatchString(msg):
+		m := unusableNil.FindStringSubmatch(msg)
+		if m[2] == "map index" {
+			return []string{fmt.Sprintf("cannot convert nil to type %s", m[1])}
+		}
+		return []string{fmt.Sprintf("cannot use nil as type %s in %s", m[1], m[2])}
+	}
+
+	switch node := expr.(type) {
+	case *ast.CompositeLit:
+		if negativeIndex.MatchString(msg) || truncatedIndex.MatchString(msg) {
+			return []string{"array index must be non-negative integer constant"}
+		}
+
+	case *ast.SelectorExpr:
+		// go/types abbreviates composite literals as T{…}
+		return []string{strings.Replace(msg, types.ExprString(node.X), source(node.X), 1)}
+
+	case *ast.IndexExpr:
+		return gcIndexErrors(msg, indexKind(typeOf(node.X).Type), typeOf(node.X), source(node))
+
+	case *ast.SliceExpr:
+		switch {
+		case strings.HasPrefix(msg, "cannot slice unaddressable value "):
+			return []string{fmt.Sprintf("invalid operation %s (slice of unaddressable value)", source(node))}
+		case msg == "invalid operation: 3-index slice of string":
+			return []string{fmt.Sprintf("invalid operation %s (3-index slice of string)", source(node))}
+		case invertedSlice.MatchString(msg):
+			return []string{invertedSlice.ReplaceAllString(msg, "invalid slice index: $2 > $1")}
+		case operandOfType.MatchString(msg):
+			return []string{operandOfType.ReplaceAllString(msg, "$1 (type $2)")}
+		}
+		return gcIndexErrors(msg, "slice", typeOf(node.X), source(node))
+	}
+	return []string{msg}
+}
+
+// gcIndexErrors rewords the error msg of indexing or slicing an operand
+// x with a non-integer, negative or out of bounds index. kind names what
+// gc says is indexed.
+func gcIndexErrors(msg, kind string, x types.TypeAndValue, src string) []string {
+	switch {
+	case strings.HasPrefix(msg, "cannot index "):
+		return []string{fmt.Sprintf("invalid operation: %s (index of type %v)", src, x.Type)}
+	case negativeIndex.MatchString(msg):
+		return []string{negativeIndex.ReplaceAllString(msg, "invalid "+kind+" index $1 (index must be non-negative)")}
+	case truncatedIndex.MatchString(msg):
+		return []string{truncatedIndex.ReplaceAllString(msg, "constant $1 truncated to integer")}
+	case nonIntegerIndex.MatchString(msg):
+		return []string{nonIntegerIndex.ReplaceAllString(msg, "non-integer "+kind+" index $1")}
+	case unconvertibleIndex.MatchString(msg):
+		return []string{unconvertibleIndex.ReplaceAllString(msg, "non-integer "+kind+" index $1")}
+	case indexBounds.MatchString(msg):
+		m := indexBounds.FindStringSubmatch(msg)
+		return []string{fmt.Sprintf("invalid %s index %s (out of bounds for %s)", kind, m[1], staticBound(x))}
+	}
+	return []string{msg}
+}
+
+// indexKind names the kind of t as gc does in index errors
+func indexKind(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	switch t := t.Underlying().(type) {
+	case *types.Array:
+		return "array"
+	case *types.Basic:
+		if t.Info()&types.IsString != 0 {
+			return "string"
+		}
+	}
+	return "slice"
+}
+
+// staticBound describes the length of an array or constant string
+func staticBound(tv types.TypeAndValue) string {
+	if tv.Value != nil && tv.Value.Kind() == constant.String {
+		return fmt.Sprintf("%d-byte string", len(constant.StringVal(tv.Value)))
+	}
+	t := tv.Type
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if array, ok := t.Underlying().(*types.Array); ok {
+		return fmt.Sprintf("%d-element array", array.Len())
+	}
+	return "unknown bound"
+}
+
+// gcOperandType returns the type of an operand described by go/types as
+// an untyped constant or a value of some type
+func gcOperandType(desc string) string {
+	if m := untypedConstant.FindStringSubmatch(desc); m != nil {
+		switch m[1] {
+		case "int":
+			return "int"
+		case "float":
+			return "float64"
+		case "complex":
+			return "complex128"
+		}
+		return m[1]
+	}
+	return operandType.ReplaceAllString(desc, "$1")
+}
+
+var (
+	firstLine          = regexp.MustCompile(`^([^\n]*)\n`)
+	nilSelector        = regexp.MustCompile(`^nil\.\w+ undefined `)
+	undefinedName      = regexp.MustCompile(`^undefined: (\w+)$`)
+	notAFunction       = regexp.MustCompile(`^invalid operation: cannot call (\S+) \(\w+ of type (.+)\): .* is not a function$`)
+	duplicateIndex     = regexp.MustCompile(`^duplicate index (\d+) in array or slice literal$`)
+	litIndexBounds     = regexp.MustCompile(`^index (\d+) is out of bounds \(>= (\d+)\)$`)
+	unusable           = regexp.MustCompile(`^cannot use (.+) \((untyped \w+ constant|\w+ of type .+)\) as (\S+) value in (.+?)(?: \(truncated\))?$`)
+	unusableNil        = regexp.MustCompile(`^cannot use nil as (\S+) value in (.+)$`)
+	untypedConstant    = regexp.MustCompile(`^untyped (\w+) constant$`)
+	operandType        = regexp.MustCompile(`^\w+ of type (.+)$`)
+	operandOfType      = regexp.MustCompile(`^(cannot slice \S+) \(\w+ of type (.+)\)$`)
+	invertedSlice      = regexp.MustCompile(`^invalid slice indices: (\S+) < (\S+)$`)
+	negativeIndex      = regexp.MustCompile(`^invalid argument: index (\S+) \(constant of type int\) must not be negative$`)
+	truncatedIndex     = regexp.MustCompile(`^(\S+) \(untyped float constant\) truncated to int$`)
+	nonIntegerIndex    = regexp.MustCompile(`^invalid argument: index (\S+) \(\w+ of type .+\) must be integer$`)
+	unconvertibleIndex = regexp.MustCompile(`^cannot convert (\S+?)(?: \(untyped \w+ constant\))? to type int$`)
+	indexBounds        = regexp.MustCompile(`^invalid argument: index (\d+) out of bounds \[0:\d+\]$`)
+)
@@ -1,13 +1,19 @@
+// Command testgen generates the *_gen_test.go files of the package. Each
+// generator is built with main.go, common.go, driver.go and gcerrors.go,
+// see the Makefile. Whether an expression is valid, the errors expected
+// if it is not and the type of its result are those of go/types, which
+// runs in process, so the tests can be regenerated with any Go
+// toolchain. Errors are reworded as gc reports them, which the package
+// under test follows.
 package main
 
 import (
-	"os"
 	"fmt"
-	"github.com/0xfaded/go-testgen"
+	"os"
 )
 
 func main() {
-	if err := testgen.Generate(&Test{}, os.Stdout); err != nil {
+	if err := Generate(&Test{}, os.Stdout); err != nil {
 		panic(fmt.Sprintf("Test generation failed %v\n", err))
 	}
 }
@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"reflect"
+)
+
+// evalTypeAssertExpr evaluates x.(T). If x does not hold a T, the error
+// is a panic with a *TypeAssertionError value.
+func evalTypeAssertExpr(ctx *Ctx, assert *TypeAssertExpr, env *Env) (reflect.Value, error) {
+	xx, _, err := EvalExpr(ctx, assert.X.(Expr), env)
+	if err != nil {
+		return reflect.Value{}, err
+	} else if xx == nil {
+		return reflect.Value{}, ErrUntypedNil{at(ctx, assert.X)}
+	}
+	x, err := expectSingleValue(ctx, *xx, assert.X)
+	if err != nil {
+		return reflect.Value{}, err
+	} else if x.Kind() != reflect.Interface {
+		return reflect.Value{}, ErrInvalidTypeAssert{at(ctx, assert.X), x.Type()}
+	}
+
+	t := assert.KnownType()[0]
+	failed := &TypeAssertionError{Interface: x.Type(), Asserted: t}
+	if x.IsNil() {
+		return reflect.Value{}, ErrPanic{failed}
+	}
+	dynamic := x.Elem()
+	failed.Concrete = dynamic.Type()
+	if t.Kind() == reflect.Interface {
+		if failed.Missing = missingMethod(dynamic.Type(), t); failed.Missing != "" {
+			return reflect.Value{}, ErrPanic{failed}
+		}
+		v := reflect.New(t).Elem()
+		v.Set(dynamic)
+		return v, nil
+	} else if dynamic.Type() != t {
+		return reflect.Value{}, ErrPanic{failed}
+	}
+	return dynamic, nil
+}
+
+// evalTypeAssertOk evaluates v, ok := x.(T), which does not panic if x
+// does not hold a T
+func evalTypeAssertOk(ctx *Ctx, assert *TypeAssertExpr, env *Env) (reflect.Value, bool, error) {
+	v, err := evalTypeAssertExpr(ctx, assert, env)
+	if p, ok := err.(ErrPanic); ok {
+		if _, ok := p.Value.(*TypeAssertionError); ok {
+			return reflect.Zero(assert.KnownType()[0]), false, nil
+		}
+	}
+	return v, err == nil, err
+}
@@ -0,0 +1,200 @@
+package eval
+
+import (
+	"go/parser"
+	"reflect"
+	"testing"
+)
+
+func TestTypeDeclStruct(t *testing.T) {
+	env := makeEnv()
+	declare(t, "type Point struct{ X, Y int }", env)
+
+	expected := struct{ X, Y int }{1, 2}
+	expectResult(t, "Point{1, 2}", env, expected)
+	expectResult(t, "Point{Y: 2, X: 1}", env, expected)
+}
+
+func TestTypeDeclStructTagsAndUnexported(t *testing.T) {
+	env := makeEnv()
+	declare(t, "type T struct{ Name string `json:\"name\"`; age int }", env)
+
+	typ := env.Types["T"]
+	if tag := typ.Field(0).Tag.Get("json"); tag != "name" {
+		t.Fatalf("Expected tag name, got %v", tag)
+	}
+	if pkgPath := typ.Field(1).PkgPath; pkgPath != "main" {
+		t.Fatalf("Expected unexported field in package main, got %v", pkgPath)
+	}
+}
+
+func TestTypeDeclEmbedded(t *testing.T) {
+	type Inner struct{ A int }
+
+	env := makeEnv()
+	env.Types["Inner"] = reflect.TypeOf(Inner{})
+	declare(t, "type Outer struct{ *Inner; B int }", env)
+
+	field := env.Types["Outer"].Field(0)
+	if !field.Anonymous || field.Name != "Inner" || field.Type != reflect.TypeOf(&Inner{}) {
+		t.Fatalf("Wrong embedded field %+v", field)
+	}
+}
+
+func TestTypeDeclBasic(t *testing.T) {
+	env := makeEnv()
+	declare(t, "type Celsius float64", env)
+
+	expectResult(t, "Celsius(1.5)", env, float64(1.5))
+	expectConst(t, "Celsius(1.5) * 2", env, float64(3), reflect.TypeOf(float64(0)))
+}
+
+func TestTypeDeclWarning(t *testing.T) {
+	env := makeEnv()
+	src := "type Celsius float64\ntype F = float64"
+	var warnings []Diagnostic
+	ctx := &Ctx{Input: src, Warn: func(d Diagnostic) { warnings = append(warnings, d) }}
+	if errs := EvalSource(ctx, src, env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+
+	// Aliases are represented exactly, and are not reported
+	expected := Diagnostic{
+		Severity: SeverityWarning,
+		Code:     DefinedTypeCollapsed,
+		Start:    Position{1, 6},
+		End:      Position{1, 13},
+		Message:  "type Celsius is represented by its underlying type float64, without methods or type identity",
+	}
+	if len(warnings) != 1 || !reflect.DeepEqual(warnings[0], expected) {
+		t.Fatalf("Expected warning %+v, got %+v", expected, warnings)
+	}
+}
+
+func TestTypeDeclOverNamedType(t *testing.T) {
+	type MyInts []int
+
+	env := makeEnv()
+	env.Types["MyInts"] = reflect.TypeOf(MyInts{})
+	declare(t, "type Ints MyInts", env)
+
+	// The underlying type, rather than the named host type
+	expectResult(t, "Ints{1, 2}", env, []int{1, 2})
+}
+
+func TestTypeDeclAlias(t *testing.T) {
+	type Alice struct{ Bob int }
+
+	env := makeEnv()
+	env.Types["Alice"] = reflect.TypeOf(Alice{})
+	declare(t, "type Carol = Alice\ntype R = rune", env)
+
+	expectResult(t, "Carol{1}", env, Alice{1})
+	if env.Types["R"] != RuneType {
+		t.Fatalf("Expected alias of rune, got %v", env.Types["R"])
+	}
+}
+
+func TestTypeDeclBlock(t *testing.T) {
+	env := makeEnv()
+	declare(t, "type ( A int; B []A; C [2]B )", env)
+
+	expectResult(t, "C{B{1}, B{2, 3}}", env, [2][]int{{1}, {2, 3}})
+}
+
+func TestTypeDeclCompositeTypes(t *testing.T) {
+	env := makeEnv()
+	declare(t, `type (
+	Grid [2][3]int
+	Set map[string]bool
+	In <-chan int
+	Out chan<- int
+	Fn func(int, ...string) (bool, error)
+	Any interface{}
+	Ptr *[]rune
+)`, env)
+
+	expected := map[string]reflect.Type{
+		"Grid": reflect.TypeOf([2][3]int{}),
+		"Set":  reflect.TypeOf(map[string]bool{}),
+		"In":   reflect.TypeOf((<-chan int)(nil)),
+		"Out":  reflect.TypeOf((chan<- int)(nil)),
+		"Fn":   reflect.FuncOf([]reflect.Type{reflect.TypeOf(0), reflect.TypeOf([]string{})}, []reflect.Type{reflect.TypeOf(false), builtinTypes["error"]}, true),
+		"Any":  reflect.TypeOf((*interface{})(nil)).Elem(),
+		"Ptr":  reflect.TypeOf(&[]rune{}),
+	}
+	for name, typ := range expected {
+		if env.Types[name] != typ {
+			t.Fatalf("Type %s is %v, expected %v", name, env.Types[name], typ)
+		}
+	}
+}
+
+func TestTypeConversionToSlice(t *testing.T) {
+	env := makeEnv()
+	expectResult(t, "[]byte(\"hi\")", env, []byte("hi"))
+}
+
+func TestTypeDeclErrors(t *testing.T) {
+	env := makeEnv()
+
+	expectDeclError(t, "type T Foo", env, "undefined type: Foo")
+	expectDeclError(t, "type A [-1]int", env, "invalid array bound -1")
+	expectDeclError(t, "type A [1.5]int", env, "invalid array bound 1.5")
+	expectDeclError(t, "type M map[[]int]bool", env, "invalid map key type []int")
+}
+
+func TestTypeDeclReflectLimitations(t *testing.T) {
+	env := makeEnv()
+	declare(t, "type Point struct{ X, Y int }", env)
+
+	expectDeclError(t, "type List struct{ Next *List }", env,
+		"invalid recursive type List: reflect cannot create recursive types")
+	expectDeclError(t, "type I interface{ M() }", env,
+		"unsupported type interface{ M() }: reflect cannot create interface types with methods")
+	expectDeclError(t, "type S[T any] []T", env,
+		"unsupported type S[T any] []T: generic types are not implemented")
+	expectDeclError(t, "func (p *Point) Scale(k int) {}", env,
+		"cannot declare method (*Point).Scale: reflect cannot create types with methods")
+
+	// A field with the name of the type is not recursive
+	declare(t, "type X struct{ X int }", env)
+}
+
+func TestTypedConstConversion(t *testing.T) {
+	env := makeEnv()
+	declare(t, "const Y int8 = -3\nconst S string = \"x\"\ntype Celsius float64", env)
+
+	expectConst(t, "int(Y)", env, int(-3), reflect.TypeOf(int(0)))
+	expectConst(t, "Celsius(Y)", env, float64(-3), reflect.TypeOf(float64(0)))
+	expectCheckError(t, "uint(Y)", env, "constant -3 overflows uint")
+	expectResult(t, "[]byte(S)", env, []byte("x"))
+}
+
+func TestTypeLitOperands(t *testing.T) {
+	env := makeEnv()
+
+	expectResult(t, "new([2]int)", env, new([2]int))
+	expectResult(t, "new(struct{X int})", env, new(struct{ X int }))
+	expectResult(t, "new(*int)", env, new(*int))
+	expectResult(t, "(*[]int)(nil)", env, (*[]int)(nil))
+
+	expectCheckError(t, "[]int", env, "type []int is not an expression")
+	expectCheckError(t, "func()(nil)", env, "type func()(nil) is not an expression")
+	expectCheckError(t, "len(map[int]int)", env, "type map[int]int is not an expression")
+}
+
+func TestEvalTypeLitExpr(t *testing.T) {
+	env := makeEnv()
+	ctx := &Ctx{Input: "[]int"}
+	e, _ := parser.ParseExpr(ctx.Input)
+	aexpr, errs := checkTypeExpr(ctx, e, env)
+	if errs != nil {
+		t.Fatalf("Failed to check type %v", errs)
+	}
+	if _, _, err := EvalExpr(ctx, aexpr.(Expr), env); err == nil {
+		t.Fatalf("Expected evaluating a type to fail")
+	} else if _, ok := err.(ErrInvalidExpr); !ok {
+		t.Fatalf("Expected ErrInvalidExpr, got %T %v", err, err)
+	}
+}
@@ -0,0 +1,295 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"go/ast"
+)
+
+// Types built by reflect are always unnamed and have no methods.
+// Type expressions such as struct{ X, Y int } are therefore exact, but
+// a type declared with type T U is represented by the underlying type
+// of U, see checkTypeDecl.
+
+var emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// arrayLength returns the length of an array type given its checked
+// length expression, and whether it is a valid array bound.
+func arrayLength(length Expr) (int, bool) {
+	if !length.IsConst() {
+		return 0, false
+	}
+	v := length.Const()
+	if n, ok := v.Interface().(*ConstNumber); ok {
+		if !n.Value.IsInteger() {
+			return 0, false
+		}
+		// The limit of 2^31 elements matches that of composite literals
+		i, _, overflow := n.Value.Int(32)
+		return int(i), !overflow && i >= 0
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		return int(i), i >= 0 && i < 1<<31
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		return int(u), u < 1<<31
+	default:
+		return 0, false
+	}
+}
+
+// unhackType is the inverse of the internal types used by the checker,
+// such as Rune. The result can be passed to reflect.SliceOf and friends.
+func unhackType(t reflect.Type) reflect.Type {
+	if r, ok := t.(Rune); ok {
+		return r.Type
+	}
+	return t
+}
+
+func evalSelectorType(ctx *Ctx, selector *SelectorExpr, env *Env) (reflect.Type, error) {
+	var name string
+	switch x := selector.X.(type) {
+	case *ast.Ident:
+		name = x.Name
+	case *Ident:
+		name = x.Name
+	default:
+		return nil, errors.New("not a type")
+	}
+	if pkg, ok := env.Pkgs[name]; ok {
+		if t, ok := (*Env)(pkg).Types[selector.Sel.Name]; ok {
+			return t, nil
+		}
+		return nil, errors.New("undefined type: " + name + "." + selector.Sel.Name)
+	}
+	return nil, errors.New("not a type")
+}
+
+func evalArrayType(ctx *Ctx, array *ArrayType, env *Env) (reflect.Type, error) {
+	elt, err := evalType(ctx, array.Elt.(Expr), env)
+	if err != nil {
+		return nil, err
+	}
+	elt = unhackType(elt)
+	if array.Len == nil {
+		return reflect.SliceOf(elt), nil
+	}
+	if n, ok := arrayLength(array.Len.(Expr)); !ok || allocSize(n, elt.Size()) == math.MaxInt64 {
+		return nil, ErrInvalidArrayBound{at(ctx, array.Len)}
+	} else if err := ctx.Limits.checkArrayType(n, elt); err != nil {
+		return nil, err
+	} else {
+		return reflect.ArrayOf(n, elt), nil
+	}
+}
+
+func evalStructType(ctx *Ctx, st *StructType, env *Env) (reflect.Type, error) {
+	// Unexported names need a package path, use that of the Env
+	pkgPath := env.Path
+	if pkgPath == "" {
+		pkgPath = "main"
+	}
+
+	var fields []reflect.StructField
+	for _, field := range st.Fields.List {
+		t, err := evalType(ctx, field.Type.(Expr), env)
+		if err != nil {
+			return nil, err
+		}
+		t = unhackType(t)
+
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if s, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+
+		if field.Names == nil {
+			// Embedded fields are named after their type
+			name := embeddedFieldName(field.Type)
+			f := reflect.StructField{Name: name, Type: t, Tag: tag, Anonymous: true}
+			if !ast.IsExported(name) {
+				f.PkgPath = pkgPath
+			}
+			fields = append(fields, f)
+		}
+		for _, ident := range field.Names {
+			f := reflect.StructField{Name: ident.Name, Type: t, Tag: tag}
+			if !ast.IsExported(ident.Name) {
+				f.PkgPath = pkgPath
+			}
+			fields = append(fields, f)
+		}
+	}
+	return structOf(ctx, st, fields)
+}
+
+// structOf calls reflect.StructOf, which panics on structs it cannot
+// construct, such as those embedding types with methods.
+func structOf(ctx *Ctx, st *StructType, fields []reflect.StructField) (t reflect.Type, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrUnsupportedType{at(ctx, st), fmt.Sprint(r)}
+		}
+	}()
+	return reflect.StructOf(fields), nil
+}
+
+func embeddedFieldName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *Ident:
+		return expr.Name
+	case *StarExpr:
+		return embeddedFieldName(expr.X)
+	case *SelectorExpr:
+		return expr.Sel.Name
+	case *ParenExpr:
+		return embeddedFieldName(expr.X)
+	default:
+		return ""
+	}
+}
+
+func evalFuncType(ctx *Ctx, fn *FuncType, env *Env) (reflect.Type, error) {
+	in, variadic, err := evalFieldListTypes(ctx, fn.Params, env)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := evalFieldListTypes(ctx, fn.Results, env)
+	if err != nil {
+		return nil, err
+	}
+	return reflect.FuncOf(in, out, variadic), nil
+}
+
+// evalFieldListTypes returns the types of a parameter or result list,
+// with one entry per name. If the last parameter is variadic, its type
+// is a slice and variadic is true.
+func evalFieldListTypes(ctx *Ctx, list *ast.FieldList, env *Env) (types []reflect.Type, variadic bool, err error) {
+	if list == nil {
+		return nil, false, nil
+	}
+	for i, field := range list.List {
+		var t reflect.Type
+		if ellipsis, ok := field.Type.(*Ellipsis); ok {
+			if i != len(list.List)-1 || len(field.Names) > 1 {
+				return nil, false, ErrUnsupportedType{at(ctx, ellipsis), "can only use ... with final parameter in list"}
+			}
+			if t, err = evalType(ctx, ellipsis.Elt.(Expr), env); err != nil {
+				return nil, false, err
+			}
+			t = reflect.SliceOf(unhackType(t))
+			variadic = true
+		} else if t, err = evalType(ctx, field.Type.(Expr), env); err != nil {
+			return nil, false, err
+		}
+		t = unhackType(t)
+
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for j := 0; j < n; j += 1 {
+			types = append(types, t)
+		}
+	}
+	return types, variadic, nil
+}
+
+func evalInterfaceType(ctx *Ctx, iface *InterfaceType, env *Env) (reflect.Type, error) {
+	if iface.Methods == nil || len(iface.Methods.List) == 0 {
+		return emptyInterfaceType, nil
+	}
+	return nil, ErrUnsupportedType{at(ctx, iface), "reflect cannot create interface types with methods"}
+}
+
+func evalMapType(ctx *Ctx, m *MapType, env *Env) (reflect.Type, error) {
+	key, err := evalType(ctx, m.Key.(Expr), env)
+	if err != nil {
+		return nil, err
+	}
+	value, err := evalType(ctx, m.Value.(Expr), env)
+	if err != nil {
+		return nil, err
+	}
+	key = unhackType(key)
+	if !key.Comparable() {
+		return nil, ErrInvalidMapKey{at(ctx, m.Key), key}
+	}
+	return reflect.MapOf(key, unhackType(value)), nil
+}
+
+func evalChanType(ctx *Ctx, ch *ChanType, env *Env) (reflect.Type, error) {
+	elem, err := evalType(ctx, ch.Value.(Expr), env)
+	if err != nil {
+		return nil, err
+	}
+	var dir reflect.ChanDir
+	switch ch.Dir {
+	case ast.SEND:
+		dir = reflect.SendDir
+	case ast.RECV:
+		dir = reflect.RecvDir
+	default:
+		dir = reflect.BothDir
+	}
+	return reflect.ChanOf(dir, unhackType(elem)), nil
+}
+
+// underlyingType returns an unnamed type identical to the underlying
+// type of t. If reflect cannot construct one, t is returned.
+func underlyingType(t reflect.Type) (u reflect.Type) {
+	t = unhackType(t)
+	if t.Name() == "" {
+		return t
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			u = t
+		}
+	}()
+	switch t.Kind() {
+	case reflect.Array:
+		return reflect.ArrayOf(t.Len(), t.Elem())
+	case reflect.Chan:
+		return reflect.ChanOf(t.ChanDir(), t.Elem())
+	case reflect.Func:
+		in := make([]reflect.Type, t.NumIn())
+		for i := range in {
+			in[i] = t.In(i)
+		}
+		out := make([]reflect.Type, t.NumOut())
+		for i := range out {
+			out[i] = t.Out(i)
+		}
+		return reflect.FuncOf(in, out, t.IsVariadic())
+	case reflect.Map:
+		return reflect.MapOf(t.Key(), t.Elem())
+	case reflect.Ptr:
+		return reflect.PtrTo(t.Elem())
+	case reflect.Slice:
+		return reflect.SliceOf(t.Elem())
+	case reflect.Struct:
+		fields := make([]reflect.StructField, t.NumField())
+		for i := range fields {
+			fields[i] = t.Field(i)
+		}
+		return reflect.StructOf(fields)
+	case reflect.Interface:
+		return t
+	default:
+		// Basic types are named after their kind
+		if b, ok := builtinTypes[t.Kind().String()]; ok {
+			return unhackType(b)
+		}
+		return t
+	}
+}
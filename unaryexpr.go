@@ -15,6 +15,16 @@ func evalUnaryExpr(ctx *Ctx, b *UnaryExpr, env *Env) (r reflect.Value, rtyped bo
 	rtyped = xtyped
 	x := (*xx)[0]
 
+	if b.Op == token.ARROW {
+		r, _, err = chanRecv(ctx, x, b.X.(Expr), b)
+		return r, true, err
+	} else if b.Op == token.AND {
+		if !x.CanAddr() {
+			return reflect.Value{}, false, ErrUnaddressable{at(ctx, b.X)}
+		}
+		return x.Addr(), true, nil
+	}
+
 	if userConversion != nil {
 		x, xtyped, err = userConversion(x, xtyped)
 	}
@@ -22,7 +32,7 @@ func evalUnaryExpr(ctx *Ctx, b *UnaryExpr, env *Env) (r reflect.Value, rtyped bo
 	switch x.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		r, err = evalUnaryIntExpr(ctx, x, b.Op)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		r, err = evalUnaryUintExpr(ctx, x, b.Op)
 	case reflect.Float32, reflect.Float64:
 		r, err = evalUnaryFloatExpr(ctx, x, b.Op)
@@ -30,6 +40,12 @@ func evalUnaryExpr(ctx *Ctx, b *UnaryExpr, env *Env) (r reflect.Value, rtyped bo
 		r, err = evalUnaryComplexExpr(ctx, x, b.Op)
 	case reflect.String:
 		r, err = evalUnaryStringExpr(ctx, x, b.Op)
+	case reflect.Bool:
+		if b.Op == token.NOT {
+			r = reflect.ValueOf(!x.Bool()).Convert(x.Type())
+		} else {
+			err = ErrInvalidOperand{x, b.Op}
+		}
 	default:
 		err = ErrInvalidOperands{x, b.Op, x}
 	}
@@ -49,6 +65,7 @@ func evalUnaryIntExpr(ctx *Ctx, x reflect.Value, op token.Token) (reflect.Value,
 	switch op {
 	case token.ADD: r = +xx
 	case token.SUB: r = -xx
+	case token.XOR: r = ^xx
 	default: err = ErrInvalidOperand{x, op}
 	}
 	if is_bool {
@@ -68,7 +85,8 @@ func evalUnaryUintExpr(ctx *Ctx, x reflect.Value, op token.Token) (reflect.Value
 	xx := x.Uint()
 	switch op {
 	case token.ADD: r = +xx
-	// case token.SUB: r = -xx
+	case token.SUB: r = -xx
+	case token.XOR: r = ^xx
 	default: err = ErrInvalidOperand{x, op}
 	}
 	if is_bool {
@@ -97,8 +115,10 @@ func evalUnaryComplexExpr(ctx *Ctx, x reflect.Value, op token.Token) (reflect.Va
 	var err error
 	var r complex128
 
-	// xx := x.Complex()
+	xx := x.Complex()
 	switch op {
+	case token.ADD: r = + xx
+	case token.SUB: r = - xx
 	default: err = ErrInvalidOperand{x, op}
 	}
 	return reflect.ValueOf(r).Convert(x.Type()), err
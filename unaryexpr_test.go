@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -21,3 +22,21 @@ func TestUintUnaryOps(t *testing.T) {
 		expectResult(t, "uint64(+12)",  env, uint64(+12))
 	}
 }
+
+func TestAddressOf(t *testing.T) {
+	type Alice struct {
+		Bob int
+	}
+
+	env := makeEnv()
+	env.Types["Alice"] = reflect.TypeOf(Alice{})
+	env.Vars["a"] = reflect.ValueOf(&Alice{10})
+	env.Vars["s"] = reflect.ValueOf(&[]int{1, 2})
+
+	expectResult(t, "*&a.Bob", env, 10)
+	expectResult(t, "*&s[1]", env, 2)
+	expectResult(t, "&Alice{ 20 }", env, &Alice{20})
+
+	expectCheckError(t, "&1", env, "cannot take the address of 1")
+	expectCheckError(t, "&len(\"ab\")", env, "cannot take the address of len(\"ab\")")
+}
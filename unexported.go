@@ -0,0 +1,132 @@
+package eval
+
+import (
+	"reflect"
+	"unsafe"
+
+	"go/ast"
+)
+
+// UnexportedAccess is how evaluated code may use the unexported fields
+// of structs. The values of unexported fields obtained through reflect
+// cannot be converted to interfaces or assigned, so by default they
+// cannot be used.
+type UnexportedAccess int
+
+const (
+	// Unexported fields may not be used, selecting one is an error
+	UnexportedNone UnexportedAccess = iota
+
+	// Unexported fields may be read, but not assigned, nor may what
+	// they refer to be assigned through them. Copies of their values
+	// are not affected, and may still share their memory: after
+	// x := s.items, x[0] may be assigned.
+	UnexportedRead
+
+	// Unexported fields may be read and assigned
+	UnexportedWrite
+)
+
+// selectField returns the field of the struct x called name, which is
+// invalid if there is none. Fields may be promoted through embedded
+// structs and pointers. An unexported field is made usable with package
+// unsafe, as allowed by ctx.Unexported, and is an error otherwise. A field of a value which is not
+// addressable is read from a copy of the value.
+func selectField(ctx *Ctx, x reflect.Value, name string) (reflect.Value, error) {
+	f, ok := x.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}, nil
+	}
+	v, err := fieldByIndex(x, f.Index)
+	if err != nil || v.CanInterface() {
+		return v, err
+	} else if ctx.Unexported == UnexportedNone {
+		return v, ErrUnexportedSelector{name: name}
+	} else if !v.CanAddr() {
+		if !x.CanInterface() {
+			return v, nil
+		}
+		c := reflect.New(x.Type()).Elem()
+		c.Set(x)
+		v, _ = fieldByIndex(c, f.Index)
+		return readOnlyCopy(exposeValue(v)), nil
+	} else if ctx.Unexported == UnexportedRead {
+		return readOnlyCopy(exposeValue(v)), nil
+	}
+	return exposeValue(v), nil
+}
+
+// exposeValue returns a usable value at the address of v, which must be
+// addressable
+func exposeValue(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// readOnlyCopy returns a copy of v which cannot be assigned. An element
+// of an array which is not addressable is neither addressable itself.
+func readOnlyCopy(v reflect.Value) reflect.Value {
+	a := reflect.New(reflect.ArrayOf(1, v.Type())).Elem()
+	a.Index(0).Set(v)
+	return reflect.ValueOf(a.Interface()).Index(0)
+}
+
+// checkUnexportedField checks that a selector does not select an
+// unexported field, if the type of the struct is known and ctx does not
+// allow access to unexported fields. Otherwise it is checked when the
+// selector is evaluated.
+func checkUnexportedField(ctx *Ctx, selector *SelectorExpr, env *Env) error {
+	t := staticType(selector.X.(Expr), env)
+	if ctx.Unexported != UnexportedNone || t == nil {
+		return nil
+	} else if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	} else if f, ok := t.FieldByName(selector.Sel.Name); ok && f.PkgPath != "" {
+		return ErrUnexportedSelector{at(ctx, selector), selector.Sel.Name}
+	}
+	return nil
+}
+
+// unexportedRoot returns the unexported field through which x is
+// assigned, addressed or sliced, if ctx only allows unexported fields to
+// be read. The copy of such a field cannot be assigned, but the elements,
+// map entries and pointees it refers to are those of the original, and
+// are read only as the field is.
+func unexportedRoot(ctx *Ctx, x Expr, env *Env) (string, bool) {
+	if ctx.Unexported != UnexportedRead {
+		return "", false
+	}
+	for {
+		switch e := x.(type) {
+		case *SelectorExpr:
+			if _, ok := packageOf(e, env); ok {
+				return "", false
+			} else if !ast.IsExported(e.Sel.Name) {
+				return "unexported field " + at(ctx, e).Source(), true
+			}
+			x = e.X.(Expr)
+		case *IndexExpr:
+			x = e.X.(Expr)
+		case *SliceExpr:
+			x = e.X.(Expr)
+		case *StarExpr:
+			x = e.X.(Expr)
+		case *ParenExpr:
+			x = e.X.(Expr)
+		default:
+			return "", false
+		}
+	}
+}
+
+// readOnlyPath returns what makes x read only, if it is assigned,
+// addressed or sliced through a read only variable or an unexported
+// field which may only be read
+func readOnlyPath(ctx *Ctx, x Expr, env *Env) (string, bool) {
+	if name, ok := ctx.Policy.readOnlyRoot(x, env); ok {
+		return name, true
+	}
+	return unexportedRoot(ctx, x, env)
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type unexportedT struct {
+	name  string
+	count int
+	items []int
+	index map[string]int
+	total *int
+	inner *unexportedT
+}
+
+func TestUnexportedNone(t *testing.T) {
+	total := 3
+	s := &unexportedT{name: "outer", count: 2, items: []int{1, 2}, index: map[string]int{"a": 1},
+		total: &total, inner: &unexportedT{count: 5}}
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&s)
+	env.Funcs["id"] = reflect.ValueOf(func(n int) int { return n })
+	for _, src := range []string{
+		"q := s.count",
+		"var q int = s.count",
+		"var q int\nq = s.count",
+		"_ = id(s.count)",
+		"_ = s.count + 1",
+	} {
+		errs := EvalSource(&Ctx{}, src, env)
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), "s.count undefined (cannot refer to unexported field count)") {
+			t.Errorf("Expected %s to be rejected, got %v", src, errs)
+		}
+	}
+
+	// Fields of a struct whose type is only known when evaluating
+	env.Funcs["get"] = reflect.ValueOf(func() unexportedT { return *s })
+	if errs := EvalSource(&Ctx{}, "q := get().count", env); len(errs) != 1 ||
+		!strings.Contains(errs[0].Error(), "get().count undefined (cannot refer to unexported field count)") {
+		t.Errorf("Expected get().count to be rejected, got %v", errs)
+	}
+}
+
+func TestUnexportedRead(t *testing.T) {
+	total := 3
+	s := &unexportedT{name: "outer", count: 2, items: []int{1, 2}, index: map[string]int{"a": 1},
+		total: &total, inner: &unexportedT{count: 5}}
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&s)
+	ctx := &Ctx{Unexported: UnexportedRead}
+
+	if errs := EvalSource(ctx, "n := s.count + s.inner.count\nname := s.name\nitem := s.items[1]", env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+	expectResult(t, "n", env, int(7))
+	expectResult(t, "name", env, "outer")
+	expectResult(t, "item", env, int(2))
+
+	if errs := EvalSource(ctx, "s.count = 3", env); len(errs) != 1 {
+		t.Fatalf("Expected an error assigning an unexported field, got %v", errs)
+	} else if s.count != 2 {
+		t.Fatalf("Unexported field was assigned")
+	}
+
+	// What unexported fields refer to is read only as they are
+	for _, src := range []string{
+		"s.items[0] = 99",
+		"s.items[0]++",
+		"s.index[\"a\"] = 99",
+		"s.index[\"a\"] += 99",
+		"*s.total = 99",
+		"s.inner.count = 99",
+		"p := &s.items[0]",
+		"s.items[:1][0] = 99",
+		"s.items = append(s.items[:1], 99)",
+		"_ = append(s.items[:1], 99)",
+	} {
+		if errs := EvalSource(ctx, src, env); errs == nil || !strings.Contains(errs[0].Error(), "unexported field") {
+			t.Errorf("Expected %s to be denied, got %v", src, errs)
+		}
+	}
+	if s.items[0] != 1 || s.items[1] != 2 || s.index["a"] != 1 || *s.total != 3 || s.inner.count != 5 {
+		t.Fatalf("Unexported fields were assigned through: %+v", s)
+	}
+
+	// Copies are not read only, though they share memory with the fields
+	if errs := EvalSource(ctx, "x := s.items\nx[0] = 99\nm := s.index\nm[\"a\"] = 99\np := s.total\n*p = 99", env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	} else if s.items[0] != 99 || s.index["a"] != 99 || *s.total != 99 {
+		t.Fatalf("Expected copies of unexported fields to share their memory: %+v", s)
+	}
+}
+
+func TestUnexportedWrite(t *testing.T) {
+	total := 3
+	s := &unexportedT{name: "outer", count: 2, items: []int{1, 2}, index: map[string]int{"a": 1},
+		total: &total, inner: &unexportedT{count: 5}}
+	env := makeEnv()
+	env.Vars["s"] = reflect.ValueOf(&s)
+	ctx := &Ctx{Unexported: UnexportedWrite}
+
+	if errs := EvalSource(ctx, "s.count = 3\ns.inner.count++\ns.items[0] = 4", env); errs != nil {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+	if s.count != 3 || s.inner.count != 6 || s.items[0] != 4 {
+		t.Fatalf("Unexported fields were not assigned: %+v", s)
+	}
+}
+
+func TestInspectUnexported(t *testing.T) {
+	if str := Inspect(reflect.ValueOf(unexportedT{count: 5})); !strings.Contains(str, "count: 5,") {
+		t.Fatalf("Expected Inspect to show unexported fields, got %s", str)
+	}
+}
@@ -3,6 +3,7 @@ package eval
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -23,14 +24,13 @@ func hackedNew(t reflect.Type) reflect.Value {
 }
 
 func assignableValue(x reflect.Value, to reflect.Type, xTyped bool) (reflect.Value, error) {
-	var err error
 	if xTyped {
 		if x.Type().AssignableTo(to) {
 			return x, nil
 		}
 	} else {
-		if x, err = promoteUntypedNumeral(x, to); err == nil {
-			return x, nil
+		if promoted, err := promoteUntypedNumeral(x, to); err == nil {
+			return promoted, nil
 		}
 	}
 	return x, errors.New(fmt.Sprintf("Cannot convert %v to type %v", x, to))
@@ -45,17 +45,6 @@ func setTypedValue(dst, src reflect.Value, srcTyped bool) error {
 	}
 }
 
-func makeSliceWithValues(elts []reflect.Value, sliceType reflect.Type) (reflect.Value, error) {
-	slice := reflect.MakeSlice(sliceType, len(elts), len(elts))
-	for i := 0; i < slice.Len(); i += 1 {
-		if err := setTypedValue(slice.Index(i), elts[i], true); err != nil {
-			return reflect.Value{}, nil
-		}
-	}
-	return slice, nil
-}
-
-
 // Only considers untyped kinds produced by our runtime. Assumes input type is unnamed
 func isUntypedNumeral(x reflect.Value) bool {
 	switch x.Kind() {
@@ -68,17 +57,31 @@ func isUntypedNumeral(x reflect.Value) bool {
 
 func promoteUntypedNumeral(untyped reflect.Value, to reflect.Type) (reflect.Value, error) {
 	// The only valid promotion that cannot be directly converted is int|float -> complex
-	if untyped.Type().ConvertibleTo(to) {
+	if to.Kind() == reflect.String && untyped.Kind() != reflect.String {
+		// Conversely, int -> string is a conversion but not a promotion
+	} else if untyped.Kind() == reflect.Float64 && isIntegerKind(to.Kind()) &&
+		untyped.Float() != math.Trunc(untyped.Float()) {
+		// Constants are truncated by conversions only
+	} else if untyped.Type().ConvertibleTo(to) {
 		return untyped.Convert(to), nil
 	} else if to.Kind() == reflect.Complex64 || to.Kind() == reflect.Complex128 {
 		floatType := reflect.TypeOf(float64(0))
 		if untyped.Type().ConvertibleTo(floatType) {
-			return reflect.ValueOf(complex(untyped.Convert(floatType).Float(), 0)), nil
+			return reflect.ValueOf(complex(untyped.Convert(floatType).Float(), 0)).Convert(to), nil
 		}
 	}
 	return reflect.Value{}, errors.New(fmt.Sprintf("cannot convert %v to %v", untyped, to))
 }
 
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
 // Only considers untyped kinds produced by our runtime. Assumes input type is unnamed
 func promoteUntypedNumerals(x, y reflect.Value) (reflect.Value, reflect.Value) {
 	switch x.Kind() {
@@ -162,31 +165,41 @@ var parseError = regexp.MustCompile(`^([0-9]+):([0-9]+): `)
 //  }
 //
 // If something is wrong parsing the error message or matching it with
-// the source, an empty slice is returned.
+// the source, an empty slice is returned. FormatDiagnostic does the
+// same for a Diagnostic.
 func FormatErrorPos(source, errmsg string) (cursored [] string) {
 	matches := parseError.FindStringSubmatch(errmsg)
 	if len(matches) == 3 {
 		var err error
-		var line, column int
-		if line, err = strconv.Atoi(matches[1]); err != nil {
+		var d Diagnostic
+		if d.Start.Line, err = strconv.Atoi(matches[1]); err != nil {
 			return cursored
 		}
-		if column, err = strconv.Atoi(matches[2]); err != nil {
+		if d.Start.Column, err = strconv.Atoi(matches[2]); err != nil {
 			return cursored
 		}
-		sourceLines := strings.Split(source, "\n")
-		if line > len(sourceLines) {
-			return cursored
-		}
-		errLine := sourceLines[line-1]
-		cursored = append(cursored, errLine)
-		if column-1 > len(errLine) || column < 1 {
-			return cursored
-		} else if column == 1 {
-			cursored = append(cursored, "^")
-		} else {
-			cursored = append(cursored, strings.Repeat("-", column-1) + "^")
-		}
+		d.Message = errmsg[len(matches[0]):]
+		cursored = FormatDiagnostic(source, d)
+	}
+	return cursored
+}
+
+// FormatDiagnostic formats source to show the start of d, as
+// FormatErrorPos does
+func FormatDiagnostic(source string, d Diagnostic) (cursored []string) {
+	line, column := d.Start.Line, d.Start.Column
+	sourceLines := strings.Split(source, "\n")
+	if line < 1 || line > len(sourceLines) {
+		return cursored
+	}
+	errLine := sourceLines[line-1]
+	cursored = append(cursored, errLine)
+	if column-1 > len(errLine) || column < 1 {
+		return cursored
+	} else if column == 1 {
+		cursored = append(cursored, "^")
+	} else {
+		cursored = append(cursored, strings.Repeat("-", column-1) + "^")
 	}
 	return cursored
 }